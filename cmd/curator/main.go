@@ -61,8 +61,11 @@ func curatorHandler(w http.ResponseWriter, r *http.Request) {
 
 	logger.Info("記事キュレーション処理を開始します")
 
+	// GCP_PROJECT_IDはFirestoreバックエンド・Secret Managerを使用する場合のみ必須
 	projectID := os.Getenv("GCP_PROJECT_ID")
-	if projectID == "" {
+	// STORAGE_BACKEND環境変数が未設定の場合はFirestoreを使用
+	storeOpts := storage.StoreOptionsFromEnv(projectID)
+	if projectID == "" && storeOpts.UsesFirestore() {
 		handleError(w, logger, http.StatusInternalServerError, "GCP_PROJECT_ID環境変数が設定されていません", nil)
 		return
 	}
@@ -78,10 +81,18 @@ func curatorHandler(w http.ResponseWriter, r *http.Request) {
 		configSource = "config.json"
 	}
 
-	secretMgr, err := secrets.NewManager(ctx, projectID)
-	if err != nil {
-		handleError(w, logger, http.StatusInternalServerError, "Secret Managerクライアントの初期化に失敗", err)
-		return
+	// GCP_PROJECT_IDが未設定の場合（memory・boltバックエンド）は、シークレットを環境変数から読み込む
+	var secretMgr secrets.Manager
+	var err error
+	if projectID != "" {
+		secretMgr, err = secrets.NewManager(ctx, projectID)
+		if err != nil {
+			handleError(w, logger, http.StatusInternalServerError, "Secret Managerクライアントの初期化に失敗", err)
+			return
+		}
+	} else {
+		logger.Info("GCP_PROJECT_IDが未設定のため、シークレットを環境変数から読み込みます", "storageBackend", storeOpts.Backend)
+		secretMgr = secrets.NewEnvManager()
 	}
 	defer secretMgr.Close()
 
@@ -91,18 +102,12 @@ func curatorHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	geminiAPIKey, err := secretMgr.GetSecret(ctx, "gemini-api-key")
+	store, err := storage.NewStore(ctx, storeOpts)
 	if err != nil {
-		handleError(w, logger, http.StatusInternalServerError, "Gemini APIキーの取得に失敗", err)
+		handleError(w, logger, http.StatusInternalServerError, "ストレージの初期化に失敗", err)
 		return
 	}
-
-	firestoreClient, err := storage.NewClient(ctx, projectID)
-	if err != nil {
-		handleError(w, logger, http.StatusInternalServerError, "Firestoreクライアントの初期化に失敗", err)
-		return
-	}
-	defer firestoreClient.Close()
+	defer store.Close()
 
 	configLoader := config.NewLoader()
 	cfg, err := configLoader.Load(ctx, configSource)
@@ -116,19 +121,58 @@ func curatorHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// プロンプトのテンプレートを読み込んで検証（prompt_settingsの相対パス・URLは設定ソースを基準に解決）
+	prompts, err := llm.LoadPrompts(ctx, configLoader, configSource, cfg.PromptSettings, llm.BatchOptionsFromSettings(cfg.LLMSettings))
+	if err != nil {
+		handleError(w, logger, http.StatusInternalServerError, "プロンプトのテンプレートの読み込みに失敗", err)
+		return
+	}
+
+	// 設定の保持期間をストレージに適用
+	store.SetRetentionSettings(cfg.RetentionSettings)
+	// 興味トピックとプロンプトのバージョンが変わった却下済み記事を再評価の対象にする
+	store.SetEvaluationContext(storage.EvaluationContext{
+		InterestsHash:     cfg.InterestsHash(),
+		PromptVersion:     prompts.Evaluation.Version,
+		MaxRechecksPerRun: cfg.ReevaluationSettings.MaxRechecksPerRun,
+	})
+
+	// LLMのAPIキー（ローカルのOpenAI互換サーバー・OllamaなどAPIキーが不要なバックエンドでは取得しない）
+	var llmAPIKey string
+	if secretName := cfg.LLMSettings.APIKeySecretName(); secretName != "" {
+		llmAPIKey, err = secretMgr.GetSecret(ctx, secretName)
+		if err != nil {
+			handleError(w, logger, http.StatusInternalServerError, "LLMのAPIキーの取得に失敗", err)
+			return
+		}
+	}
+
 	logger.Info("設定を読み込みました",
 		"rssSources", len(cfg.RSSSources),
 		"interests", len(cfg.Interests),
 		"maxArticles", cfg.NotificationSettings.MaxArticles,
+		"evaluationPromptVersion", prompts.Evaluation.Version,
+		"summaryPromptVersion", prompts.Summary.Version,
 	)
 
-	rssFetcher := rss.NewFetcher(time.Duration(cfg.TimeoutSettings.RSSFetchTimeoutSeconds) * time.Second)
+	rssFetcher := rss.NewFetcher(time.Duration(cfg.TimeoutSettings.RSSFetchTimeoutSeconds)*time.Second, cfg.SizeLimitSettings.RSSFeedMaxBytes())
 	rssParser := rss.NewParser()
-	articleFetcher := article.NewFetcher(time.Duration(cfg.TimeoutSettings.ArticleFetchTimeoutSeconds) * time.Second)
+	articleFetcher := article.NewFetcher(time.Duration(cfg.TimeoutSettings.ArticleFetchTimeoutSeconds)*time.Second, cfg.SizeLimitSettings.ArticleHTMLMaxBytes())
 	articleExtractor := article.NewExtractor(cfg.TimeoutSettings.MinTextLength, cfg.TimeoutSettings.MaxTextLength)
-	llmClient := llm.NewClient(geminiAPIKey)
-	llmEvaluator := llm.NewEvaluator(llmClient)
+	articleExtractor.SetFetcher(articleFetcher)
+	llmProvider, err := llm.NewProvider(cfg.LLMSettings, llmAPIKey, cfg.SizeLimitSettings.LLMResponseMaxBytes())
+	if err != nil {
+		handleError(w, logger, http.StatusInternalServerError, "LLMクライアントの初期化に失敗", err)
+		return
+	}
+	llmEvaluator := llm.NewEvaluator(llmProvider)
+	llmEvaluator.SetBatchOptions(llm.BatchOptionsFromSettings(cfg.LLMSettings))
+	llmEvaluator.SetPrompts(prompts)
+	llmEvaluator.SetTriageOptions(llm.TriageOptionsFromSettings(cfg.TriageSettings))
+	// 同じ本文・興味トピック・プロンプト・モデルで評価済みの記事はLLMを呼び出さずにキャッシュを使う
+	llmEvaluator.SetCache(store, cfg.InterestsHash())
 	discordClient := discord.NewClient(discordWebhookURL, logger)
+	discordClient.SetMaxResponseSize(cfg.SizeLimitSettings.DiscordResponseMaxBytes())
 
 	orchestrateCuration(
 		w,
@@ -140,7 +184,7 @@ func curatorHandler(w http.ResponseWriter, r *http.Request) {
 		articleExtractor,
 		llmEvaluator,
 		discordClient,
-		firestoreClient,
+		store,
 		logger,
 		0, // 本番環境では記事数制限なし
 	)
@@ -153,6 +197,37 @@ func getArticleTitle(articlesByURL map[string]rss.Article, articleURL string) st
 	return "Unknown"
 }
 
+// triageArticles は本文を取得する前に、タイトルとフィードの要約で評価の候補として残す記事を判定します
+// 候補から除いた記事は、興味トピックの変更時に再評価されるようtriage_rejectedの理由で却下済みとして記録します
+func triageArticles(ctx context.Context, logger logging.Logger, llmEvaluator *llm.Evaluator, store storage.Store, topics []config.InterestTopic, articles []rss.Article) []rss.Article {
+	items := make([]llm.TriageItem, len(articles))
+	for i, rssArticle := range articles {
+		items[i] = llm.TriageItem{Title: rssArticle.Title, Description: rssArticle.Summary}
+	}
+
+	kept := make([]rss.Article, 0, len(articles))
+	for i, keep := range llmEvaluator.TriageArticles(ctx, items, topics) {
+		if keep {
+			kept = append(kept, articles[i])
+			continue
+		}
+		logger.Debug("トリアージにより記事をスキップします", "url", articles[i].URL, "title", articles[i].Title)
+		if saveErr := store.SaveRejectedArticle(ctx, articles[i].URL, config.ReasonTriageRejected, nil); saveErr != nil {
+			logger.Error("却下記事の保存に失敗", "url", articles[i].URL, "error", saveErr)
+		}
+	}
+	return kept
+}
+
+// markRunFailed は実行履歴を失敗として記録します
+func markRunFailed(run *config.RunHistory, message string, err error) {
+	run.Status = config.RunStatusFailed
+	run.ErrorMessage = message
+	if err != nil {
+		run.ErrorMessage = fmt.Sprintf("%s: %v", message, err)
+	}
+}
+
 func orchestrateCuration(
 	w http.ResponseWriter,
 	ctx context.Context,
@@ -163,10 +238,35 @@ func orchestrateCuration(
 	articleExtractor *article.Extractor,
 	llmEvaluator *llm.Evaluator,
 	discordClient *discord.Client,
-	firestoreClient *storage.Client,
+	store storage.Store,
 	logger logging.Logger,
 	maxEvaluationArticles int, // 評価する記事の最大数（0=無制限、ローカルテストでは3）
 ) {
+	// 実行履歴を記録（途中で終了した場合も含めて終了時に保存）
+	run := &config.RunHistory{StartedAt: time.Now(), Status: config.RunStatusSuccess}
+	// LLMの使用量を集計する日（1日あたりの使用量の上限の判定に使う）
+	usageDate := run.StartedAt.Format("2006-01-02")
+	defer func() {
+		run.FinishedAt = time.Now()
+		// この実行でのLLMの使用量を実行履歴に記録し、当日の使用量に加算する
+		run.TokenUsage, run.LLMRequests = llmEvaluator.Usage()
+		if run.LLMRequests > 0 {
+			if usageErr := store.AddDailyUsage(ctx, usageDate, run.TokenUsage, run.LLMRequests); usageErr != nil {
+				logger.Error("LLMの使用量の保存に失敗", "error", usageErr)
+			}
+			daily := llmEvaluator.DailyUsage()
+			logger.Info("LLMの使用量",
+				"requests", run.LLMRequests,
+				"totalTokens", run.TokenUsage.TotalTokens,
+				"dailyRequests", daily.Requests,
+				"dailyTotalTokens", daily.TotalTokens,
+			)
+		}
+		if saveErr := store.SaveRunHistory(ctx, run); saveErr != nil {
+			logger.Error("実行履歴の保存に失敗", "error", saveErr)
+		}
+	}()
+
 	logger.Info("RSSフィードから記事を取得中")
 	allArticles := []rss.Article{}
 
@@ -197,25 +297,27 @@ func orchestrateCuration(
 		return
 	}
 
+	run.FetchedCount = len(allArticles)
 	logger.Info("すべてのRSSフィードから記事を取得しました", "totalCount", len(allArticles))
 
 	logger.Info("重複チェックを実行中")
 	filteredArticles := []rss.Article{}
-	var firestoreErrorCount int
+	var storageErrorCount int
 	var notifiedSkipCount, rejectedSkipCount int
-	const maxFirestoreErrors = 10
+	const maxStorageErrors = 10
 
 	for _, article := range allArticles {
-		notified, err := firestoreClient.IsArticleNotified(ctx, article.URL)
+		notified, err := store.IsArticleNotified(ctx, article.URL)
 		if err != nil {
-			firestoreErrorCount++
+			storageErrorCount++
 			logger.Error("通知済みチェックに失敗しました", "url", article.URL, "error", err)
-			if firestoreErrorCount >= maxFirestoreErrors {
-				logger.Error("Firestoreエラーが多すぎます。処理を中止します",
-					"firestoreErrorCount", firestoreErrorCount,
+			if storageErrorCount >= maxStorageErrors {
+				logger.Error("ストレージエラーが多すぎます。処理を中止します",
+					"storageErrorCount", storageErrorCount,
 					"processedArticles", len(allArticles),
 					"filteredArticles", len(filteredArticles))
-				handleError(w, logger, http.StatusInternalServerError, fmt.Sprintf("Firestoreエラーが多すぎます（%d件）。処理を中止します", firestoreErrorCount), nil)
+				markRunFailed(run, fmt.Sprintf("ストレージエラーが多すぎます（%d件）", storageErrorCount), nil)
+				handleError(w, logger, http.StatusInternalServerError, fmt.Sprintf("ストレージエラーが多すぎます（%d件）。処理を中止します", storageErrorCount), nil)
 				return
 			}
 			filteredArticles = append(filteredArticles, article)
@@ -226,12 +328,14 @@ func orchestrateCuration(
 			continue
 		}
 
-		rejected, err := firestoreClient.IsArticleRejected(ctx, article.URL)
+		// 評価条件が変わった却下済み記事は、ここで再評価の予算を消費して評価の対象にする
+		rejected, err := store.IsArticleRejected(ctx, article.URL, true)
 		if err != nil {
-			firestoreErrorCount++
+			storageErrorCount++
 			logger.Error("却下済みチェックに失敗しました", "url", article.URL, "error", err)
-			if firestoreErrorCount >= maxFirestoreErrors {
-				handleError(w, logger, http.StatusInternalServerError, fmt.Sprintf("Firestoreエラーが多すぎます（%d件）。処理を中止します", firestoreErrorCount), nil)
+			if storageErrorCount >= maxStorageErrors {
+				markRunFailed(run, fmt.Sprintf("ストレージエラーが多すぎます（%d件）", storageErrorCount), nil)
+				handleError(w, logger, http.StatusInternalServerError, fmt.Sprintf("ストレージエラーが多すぎます（%d件）。処理を中止します", storageErrorCount), nil)
 				return
 			}
 			filteredArticles = append(filteredArticles, article)
@@ -245,12 +349,16 @@ func orchestrateCuration(
 		filteredArticles = append(filteredArticles, article)
 	}
 
+	run.FilteredCount = len(filteredArticles)
+	run.RecheckedCount = store.RechecksUsed()
+
 	logger.Info("重複チェック完了",
 		"originalCount", len(allArticles),
 		"filteredCount", len(filteredArticles),
 		"notifiedSkipped", notifiedSkipCount,
 		"rejectedSkipped", rejectedSkipCount,
-		"firestoreErrors", firestoreErrorCount,
+		"rechecked", run.RecheckedCount,
+		"storageErrors", storageErrorCount,
 	)
 
 	if len(filteredArticles) == 0 {
@@ -260,6 +368,37 @@ func orchestrateCuration(
 		return
 	}
 
+	// 当日のLLMの使用量を読み込み、1日あたりの上限（llm_settings.daily_token_budget・daily_request_budget）に達した後は評価しない
+	dailyUsage, usageErr := store.GetDailyUsage(ctx, usageDate)
+	if usageErr != nil {
+		logger.Warn("LLMの使用量の取得に失敗しました。この実行の使用量のみで上限を判定します", "error", usageErr)
+		dailyUsage = &config.DailyUsage{Date: usageDate}
+	}
+	llmEvaluator.SetBudget(llm.BudgetFromSettings(cfg.LLMSettings), *dailyUsage)
+	if llmEvaluator.BudgetExhausted() {
+		logger.Warn("LLMの1日あたりの使用量の上限に達しています。評価キャッシュにない記事は評価しません",
+			"dailyRequests", dailyUsage.Requests,
+			"dailyTotalTokens", dailyUsage.TotalTokens,
+		)
+	}
+
+	// 本文を取得する前に、タイトルとフィードの要約で興味トピックと明らかに無関係な記事を除く（triage_settings.modeを設定した場合のみ）
+	if llmEvaluator.TriageEnabled() {
+		filteredArticles = triageArticles(ctx, logger, llmEvaluator, store, cfg.Interests, filteredArticles)
+		run.TriageRejected = run.FilteredCount - len(filteredArticles)
+		logger.Info("トリアージ完了",
+			"mode", cfg.TriageSettings.Mode,
+			"keptCount", len(filteredArticles),
+			"rejectedCount", run.TriageRejected,
+		)
+		if len(filteredArticles) == 0 {
+			logger.Info("トリアージの結果、評価する記事がありませんでした")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "トリアージの結果、評価する記事がありませんでした\n")
+			return
+		}
+	}
+
 	if maxEvaluationArticles > 0 && len(filteredArticles) > maxEvaluationArticles {
 		logger.Info("記事数を制限します",
 			"originalCount", len(filteredArticles),
@@ -271,73 +410,166 @@ func orchestrateCuration(
 
 	logger.Info("記事を評価中")
 	evaluatedArticles := []config.ArticleEvaluation{}
-
-	interestTopics := make([]string, len(cfg.Interests))
-	for i, interest := range cfg.Interests {
-		interestTopics[i] = interest.Topic
-	}
+	// Discordの埋め込み表示に使うため、抽出した記事のメタデータをURLごとに保持する
+	contentByURL := make(map[string]*config.Article, len(filteredArticles))
+	// 評価する記事（すべての記事の取得・抽出が終わってから、まとめてLLMで評価する）
+	var candidates []llm.BatchItem
+	// 記事のURL（リダイレクト後の最終URL）ごとのフィードの記事
+	articlesByURL := make(map[string]rss.Article, len(filteredArticles))
+	// 今回の実行で評価する記事と、同じ記事を指すフィードのリンク（別名）
+	runArticles := storage.NewRunArticles(store)
 
 	for _, rssArticle := range filteredArticles {
-		htmlContent, err := articleFetcher.Fetch(ctx, rssArticle.URL)
+		var extracted *article.ExtractedContent
+		// フィードのリンクがリダイレクタ・短縮URLの場合は、リダイレクト後の最終URLを記事のURLとし、
+		// フィードのリンク（originalURL）は別名として記録する
+		articleURL, originalURL := rssArticle.URL, ""
+		doc, err := articleFetcher.FetchDocument(ctx, rssArticle.URL)
+		if err == nil && doc.Redirected() {
+			articleURL, originalURL = doc.URL, rssArticle.URL
+		}
+		// 別のリンク（リダイレクタ・短縮URL・他のフィード）から同じ記事を今回すでに取得している場合や、
+		// リダイレクト後の最終URLが処理済みの場合は重複としてスキップする（フィードのリンクは記事の別名として記録する）
+		added, dupErr := runArticles.Add(ctx, articleURL, rssArticle.URL)
+		if dupErr != nil {
+			logger.Warn("記事のURLの重複チェックに失敗しました", "url", rssArticle.URL, "finalURL", articleURL, "error", dupErr)
+		}
+		if !added {
+			logger.Info("同じ記事を処理済みのためスキップします", "url", rssArticle.URL, "finalURL", articleURL)
+			continue
+		}
+		articlesByURL[articleURL] = rssArticle
+		if err != nil {
+			logger.Warn("記事の取得に失敗しました", "url", rssArticle.URL, "error", err)
+		} else if extracted, err = articleExtractor.ExtractDocument(ctx, doc); err != nil {
+			logger.Warn("記事本文の抽出に失敗しました", "url", articleURL, "error", err)
+		}
 		if err != nil {
-			logger.Warn("記事HTMLの取得に失敗しました。スキップします", "url", rssArticle.URL, "error", err)
-			if saveErr := firestoreClient.SaveRejectedArticle(ctx, rssArticle.URL, config.ReasonContentExtractionFailed, nil); saveErr != nil {
-				logger.Error("却下記事の保存に失敗", "url", rssArticle.URL, "error", saveErr)
+			reason := article.RejectionReason(err)
+			// 有料記事はフィードの要約が十分な長さであれば、要約のみで評価する
+			if reason == config.ReasonPaywalled {
+				extracted = articleExtractor.FeedSummaryContent(rssArticle.Summary)
 			}
-			continue
+			if extracted == nil {
+				logger.Info("記事をスキップします", "url", articleURL, "reason", reason)
+				if saveErr := storage.SaveRejectedArticleWithAliases(ctx, store, articleURL, runArticles.Aliases(articleURL), reason, nil); saveErr != nil {
+					logger.Error("却下記事の保存に失敗", "url", articleURL, "error", saveErr)
+				}
+				continue
+			}
+			logger.Info("有料記事のためフィードの要約で評価します", "url", articleURL)
 		}
 
-		extractedTitle, extractedText, err := articleExtractor.ExtractWithTitle(ctx, htmlContent, rssArticle.URL)
-		if err != nil {
-			logger.Warn("記事本文の抽出に失敗しました。スキップします", "url", rssArticle.URL, "error", err)
-			if saveErr := firestoreClient.SaveRejectedArticle(ctx, rssArticle.URL, config.ReasonContentExtractionFailed, nil); saveErr != nil {
-				logger.Error("却下記事の保存に失敗", "url", rssArticle.URL, "error", saveErr)
+		// ソース・興味トピックの言語フィルターに一致しない記事は評価しない
+		interestTopics := cfg.TopicsForLanguage(extracted.Language)
+		if !cfg.SourceAcceptsLanguage(rssArticle.SourceFeed, extracted.Language) || len(interestTopics) == 0 {
+			logger.Info("言語フィルターにより記事をスキップします", "url", articleURL, "language", extracted.Language, "source", rssArticle.SourceFeed)
+			if saveErr := storage.SaveRejectedArticleWithAliases(ctx, store, articleURL, runArticles.Aliases(articleURL), config.ReasonLanguageMismatch, nil); saveErr != nil {
+				logger.Error("却下記事の保存に失敗", "url", articleURL, "error", saveErr)
 			}
 			continue
 		}
 
 		title := rssArticle.Title
-		if extractedTitle != "" {
-			title = extractedTitle
+		if extracted.Title != "" {
+			title = extracted.Title
 		}
 
 		configArticle := &config.Article{
 			Title:         title,
-			URL:           rssArticle.URL,
+			URL:           articleURL,
+			OriginalURL:   originalURL,
 			PublishedDate: rssArticle.PublishedDate,
 			SourceFeed:    rssArticle.SourceFeed,
-			ContentText:   extractedText,
+			ContentText:   extracted.Text,
 			FetchedAt:     rssArticle.FetchedAt,
+			ContentStats:  extracted.Stats,
+
+			Author:             extracted.Author,
+			SiteName:           extracted.SiteName,
+			ImageURL:           extracted.ImageURL,
+			ReadingTimeMinutes: extracted.ReadingTimeMinutes,
+			Paywalled:          extracted.Paywalled,
+			Tags:               extracted.Tags,
+			LikeCount:          extracted.LikeCount,
+			Language:           extracted.Language,
+		}
+		if configArticle.PublishedDate.IsZero() {
+			configArticle.PublishedDate = extracted.PublishedAt
 		}
 
-		evaluation, err := llmEvaluator.EvaluateArticle(ctx, configArticle, interestTopics, cfg.NotificationSettings.MinRelevanceScore)
-		if err != nil {
-			logger.Error("記事の評価に失敗しました。スキップします", "url", rssArticle.URL, "error", err)
+		candidates = append(candidates, llm.BatchItem{Article: configArticle, Topics: interestTopics})
+	}
+
+	// 複数の記事を1回のLLM呼び出しで評価する（llm_settings.batch_sizeが1以下の場合は1記事ずつ評価）
+	quotaSkipped, budgetSkipped := 0, 0
+	for _, result := range llmEvaluator.EvaluateArticles(ctx, candidates, cfg.NotificationSettings.MinRelevanceScore) {
+		configArticle, evaluation := result.Article, result.Evaluation
+		articleURL := configArticle.URL
+		if result.Err != nil {
+			// クォータ超過で評価できなかった記事は却下済みにしないため、次回の実行で評価される
+			if llm.IsQuotaExceeded(result.Err) {
+				quotaSkipped++
+				continue
+			}
+			// 1日あたりの使用量の上限で評価できなかった記事も同様に、次回の実行で評価される
+			if llm.IsBudgetExceeded(result.Err) {
+				budgetSkipped++
+				continue
+			}
+			logger.Error("記事の評価に失敗しました。スキップします", "url", articleURL, "error", result.Err)
 			continue
 		}
 
+		run.EvaluatedCount++
+
+		// スコアの根拠を後から監査できるよう、評価記録を保存する（失敗しても処理は継続）
+		if saveErr := store.SaveEvaluation(ctx, config.NewEvaluationRecord(configArticle, evaluation)); saveErr != nil {
+			logger.Warn("評価記録の保存に失敗", "url", articleURL, "error", saveErr)
+		}
+
 		logger.Info("記事を評価しました",
-			"url", rssArticle.URL,
+			"url", articleURL,
 			"score", evaluation.RelevanceScore,
+			"rankingScore", evaluation.RankingScore,
+			"cached", evaluation.Cached,
 			"isRelevant", evaluation.IsRelevant,
 		)
 
 		if !evaluation.IsRelevant {
-			logger.Debug("関連性がない記事を却下", "url", rssArticle.URL, "score", evaluation.RelevanceScore)
+			logger.Debug("関連性がない記事を却下", "url", articleURL, "score", evaluation.RelevanceScore)
 			reason := config.ReasonLowRelevance
 			if len(evaluation.MatchingTopics) == 0 {
 				reason = config.ReasonNoTopicMatch
 			}
-			if saveErr := firestoreClient.SaveRejectedArticle(ctx, rssArticle.URL, reason, &evaluation.RelevanceScore); saveErr != nil {
-				logger.Error("却下記事の保存に失敗", "url", rssArticle.URL, "error", saveErr)
+			if saveErr := storage.SaveRejectedArticleWithAliases(ctx, store, articleURL, runArticles.Aliases(articleURL), reason, &evaluation.RelevanceScore); saveErr != nil {
+				logger.Error("却下記事の保存に失敗", "url", articleURL, "error", saveErr)
 			}
 			continue
 		}
 
 		evaluatedArticles = append(evaluatedArticles, *evaluation)
+		contentByURL[articleURL] = configArticle
 	}
 
-	logger.Info("記事の評価完了", "relevantCount", len(evaluatedArticles))
+	if quotaSkipped > 0 {
+		logger.Warn("LLMのクォータを超過したため、一部の記事を評価できませんでした。次回の実行で評価します",
+			"skippedCount", quotaSkipped,
+			"stopped", llmEvaluator.QuotaExhausted(),
+		)
+	}
+	if budgetSkipped > 0 {
+		run.BudgetExhausted = true
+		logger.Warn("LLMの1日あたりの使用量の上限に達したため、一部の記事を評価できませんでした。評価済みの記事を通知します",
+			"skippedCount", budgetSkipped,
+		)
+	}
+	run.CacheHits, run.CacheMisses = llmEvaluator.CacheStats()
+	logger.Info("記事の評価完了",
+		"relevantCount", len(evaluatedArticles),
+		"cacheHits", run.CacheHits,
+		"cacheMisses", run.CacheMisses,
+	)
 
 	if len(evaluatedArticles) == 0 {
 		logger.Info("関連性のある記事が見つかりませんでした")
@@ -347,6 +579,10 @@ func orchestrateCuration(
 	}
 
 	sort.Slice(evaluatedArticles, func(i, j int) bool {
+		// 興味トピックの優先度を反映したランキングスコアで並べ、同点の場合はLLMのスコアで並べる
+		if evaluatedArticles[i].RankingScore != evaluatedArticles[j].RankingScore {
+			return evaluatedArticles[i].RankingScore > evaluatedArticles[j].RankingScore
+		}
 		return evaluatedArticles[i].RelevanceScore > evaluatedArticles[j].RelevanceScore
 	})
 
@@ -357,11 +593,6 @@ func orchestrateCuration(
 
 	logger.Info("上位記事を選択しました", "count", len(evaluatedArticles))
 
-	articlesByURL := make(map[string]rss.Article, len(filteredArticles))
-	for _, article := range filteredArticles {
-		articlesByURL[article.URL] = article
-	}
-
 	discordArticles := make([]discord.Article, len(evaluatedArticles))
 	for i, eval := range evaluatedArticles {
 		article, ok := articlesByURL[eval.ArticleURL]
@@ -378,6 +609,15 @@ func orchestrateCuration(
 			Topics:      eval.MatchingTopics,
 			Source:      sourceFeed,
 		}
+		if content, ok := contentByURL[eval.ArticleURL]; ok {
+			discordArticles[i].SiteName = content.SiteName
+			discordArticles[i].Author = content.Author
+			discordArticles[i].ThumbnailURL = content.ImageURL
+			discordArticles[i].PublishedAt = content.PublishedDate
+			discordArticles[i].ReadingTimeMinutes = content.ReadingTimeMinutes
+			discordArticles[i].Paywalled = content.Paywalled
+			discordArticles[i].Language = content.Language
+		}
 	}
 
 	logger.Info("記事全体のサマリーを生成中", "articleCount", len(evaluatedArticles))
@@ -390,6 +630,9 @@ func orchestrateCuration(
 			RelevanceScore: eval.RelevanceScore,
 			MatchingTopics: eval.MatchingTopics,
 		}
+		if content, ok := contentByURL[eval.ArticleURL]; ok {
+			llmArticles[i].Language = content.Language
+		}
 	}
 
 	summaryResult, err := llmEvaluator.GenerateArticlesSummary(ctx, llmArticles)
@@ -408,22 +651,33 @@ func orchestrateCuration(
 			Recommendations: summaryResult.Recommendations,
 		}
 	}
+	// ダイジェストのフッターにLLMの使用量を表示する（サマリーを生成できなかった場合はフッターのみ表示する）
+	if cfg.LLMSettings.UsageFooter {
+		if discordSummary == nil {
+			discordSummary = &discord.ArticlesSummary{}
+		}
+		runUsage, runRequests := llmEvaluator.Usage()
+		discordSummary.Footer = discord.FormatUsageFooter(runUsage, runRequests, llmEvaluator.DailyUsage(), cfg.LLMSettings)
+	}
 
 	logger.Info("Discordに通知中", "articleCount", len(discordArticles))
 
 	date := time.Now().Format("2006-01-02")
 	messageID, err := discordClient.PostArticles(ctx, discordArticles, date, discordSummary)
 	if err != nil {
+		markRunFailed(run, "Discord通知に失敗", err)
 		handleError(w, logger, http.StatusInternalServerError, "Discord通知に失敗", err)
 		return
 	}
 
 	logger.Info("Discordへの通知に成功しました", "messageID", messageID)
+	run.NotifiedCount = len(discordArticles)
+	run.DiscordMessageID = messageID
 
-	logger.Info("通知済み記事をFirestoreに保存中")
+	logger.Info("通知済み記事をストレージに保存中")
 	for _, eval := range evaluatedArticles {
 		title := getArticleTitle(articlesByURL, eval.ArticleURL)
-		if err := firestoreClient.SaveNotifiedArticle(ctx, eval.ArticleURL, messageID, title, eval.RelevanceScore); err != nil {
+		if err := storage.SaveNotifiedArticleWithAliases(ctx, store, eval.ArticleURL, runArticles.Aliases(eval.ArticleURL), messageID, title, eval.RelevanceScore); err != nil {
 			logger.Error("通知済み記事の保存に失敗", "url", eval.ArticleURL, "error", err)
 		}
 	}
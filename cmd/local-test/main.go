@@ -14,6 +14,7 @@ import (
 	"github.com/kaka0913/discord-article-bot/internal/article"
 	"github.com/kaka0913/discord-article-bot/internal/config"
 	"github.com/kaka0913/discord-article-bot/internal/discord"
+	"github.com/kaka0913/discord-article-bot/internal/httpcache"
 	"github.com/kaka0913/discord-article-bot/internal/llm"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
 	"github.com/kaka0913/discord-article-bot/internal/rss"
@@ -34,8 +35,10 @@ func main() {
 	logger.Info("ローカルテスト: 記事キュレーション処理を開始します")
 
 	// 環境変数を取得
+	// GCP_PROJECT_IDはFirestoreバックエンドを使用する場合のみ必須
 	projectID := os.Getenv("GCP_PROJECT_ID")
-	if projectID == "" {
+	storeOpts := storage.StoreOptionsFromEnv(projectID)
+	if projectID == "" && storeOpts.UsesFirestore() {
 		log.Fatal("GCP_PROJECT_ID環境変数が設定されていません")
 	}
 
@@ -44,12 +47,7 @@ func main() {
 		log.Fatal("DISCORD_WEBHOOK_URL環境変数が設定されていません")
 	}
 
-	geminiAPIKey := os.Getenv("GEMINI_API_KEY")
-	if geminiAPIKey == "" {
-		log.Fatal("GEMINI_API_KEY環境変数が設定されていません")
-	}
-
-	// Firestore エミュレータの設定
+	// Firestore エミュレータの設定（STORAGE_BACKENDがfirestoreの場合のみ使用）
 	firestoreEmulator := os.Getenv("FIRESTORE_EMULATOR_HOST")
 	if firestoreEmulator != "" {
 		logger.Info("Firestoreエミュレータを使用します", "host", firestoreEmulator)
@@ -58,19 +56,37 @@ func main() {
 	// モックSecret Managerを使用（ローカルテスト用）
 	secretMgr := secrets.NewMockManager(map[string]string{
 		"discord-webhook-url": discordWebhookURL,
-		"gemini-api-key":      geminiAPIKey,
 	})
 	defer secretMgr.Close()
 
-	// Firestoreクライアントを初期化
-	firestoreClient, err := storage.NewClient(ctx, projectID)
+	// ストレージを初期化（STORAGE_BACKEND=memory|bolt でFirestore以外を選択可能）
+	store, err := storage.NewStore(ctx, storeOpts)
+	if err != nil {
+		log.Fatalf("ストレージの初期化に失敗: %v", err)
+	}
+	defer store.Close()
+	logger.Info("ストレージバックエンドを初期化しました", "backend", storeOpts.Backend)
+
+	// HTTPレスポンスのキャッシュ（HTTP_CACHE_DIRまたはHTTP_CACHE_FROZENを設定した場合のみ使用）
+	// フィード・記事・設定ファイルの取得結果をディスクに保存し、プロンプトの調整時に再取得しない
+	var httpCache *httpcache.Cache
+	cacheOpts, cacheEnabled, err := httpcache.OptionsFromEnv()
 	if err != nil {
-		log.Fatalf("Firestoreクライアントの初期化に失敗: %v", err)
+		log.Fatalf("HTTPキャッシュの設定が不正です: %v", err)
+	}
+	if cacheEnabled {
+		httpCache, err = httpcache.New(cacheOpts)
+		if err != nil {
+			log.Fatalf("HTTPキャッシュの初期化に失敗: %v", err)
+		}
+		logger.Info("HTTPレスポンスのキャッシュを使用します", "dir", cacheOpts.Dir, "maxAge", cacheOpts.MaxAge, "frozen", cacheOpts.Frozen)
 	}
-	defer firestoreClient.Close()
 
 	// 設定を読み込む（ローカルのconfig.jsonを使用）
 	configLoader := config.NewLoader()
+	if httpCache != nil {
+		configLoader = config.NewLoaderWithTransport(httpCache.Wrap(nil))
+	}
 	cfg, err := configLoader.Load(ctx, "config.json")
 	if err != nil {
 		log.Fatalf("設定の読み込みに失敗: %v", err)
@@ -81,20 +97,63 @@ func main() {
 		log.Fatalf("設定の検証に失敗: %v", err)
 	}
 
+	// プロンプトのテンプレートを読み込んで検証（prompt_settingsの相対パスはconfig.jsonからの相対パス）
+	prompts, err := llm.LoadPrompts(ctx, configLoader, "config.json", cfg.PromptSettings, llm.BatchOptionsFromSettings(cfg.LLMSettings))
+	if err != nil {
+		log.Fatalf("プロンプトのテンプレートの読み込みに失敗: %v", err)
+	}
+
+	// 設定の保持期間をストレージに適用
+	store.SetRetentionSettings(cfg.RetentionSettings)
+	// 興味トピックとプロンプトのバージョンが変わった却下済み記事を再評価の対象にする
+	store.SetEvaluationContext(storage.EvaluationContext{
+		InterestsHash:     cfg.InterestsHash(),
+		PromptVersion:     prompts.Evaluation.Version,
+		MaxRechecksPerRun: cfg.ReevaluationSettings.MaxRechecksPerRun,
+	})
+
+	// LLMのAPIキー（Geminiの場合はGEMINI_API_KEY、api_key_secretを設定したバックエンドはLLM_API_KEYから取得する）
+	// ローカルのOpenAI互換サーバー・OllamaなどAPIキーが不要なバックエンドでは使用しない
+	var llmAPIKey string
+	if cfg.LLMSettings.APIKeySecret != "" {
+		llmAPIKey = os.Getenv("LLM_API_KEY")
+	} else if cfg.LLMSettings.ProviderName() == config.LLMProviderGemini {
+		llmAPIKey = os.Getenv("GEMINI_API_KEY")
+	}
+	if cfg.LLMSettings.APIKeySecretName() != "" && llmAPIKey == "" {
+		log.Fatal("LLMのAPIキーの環境変数（GEMINI_API_KEYまたはLLM_API_KEY）が設定されていません")
+	}
+
 	logger.Info("設定を読み込みました",
 		"rssSources", len(cfg.RSSSources),
 		"interests", len(cfg.Interests),
 		"maxArticles", cfg.NotificationSettings.MaxArticles,
+		"evaluationPromptVersion", prompts.Evaluation.Version,
+		"summaryPromptVersion", prompts.Summary.Version,
 	)
 
 	// 依存関係を初期化
-	rssFetcher := rss.NewFetcher(time.Duration(cfg.TimeoutSettings.RSSFetchTimeoutSeconds) * time.Second)
+	rssFetcher := rss.NewFetcher(time.Duration(cfg.TimeoutSettings.RSSFetchTimeoutSeconds)*time.Second, cfg.SizeLimitSettings.RSSFeedMaxBytes())
 	rssParser := rss.NewParser()
-	articleFetcher := article.NewFetcher(time.Duration(cfg.TimeoutSettings.ArticleFetchTimeoutSeconds) * time.Second)
+	articleFetcher := article.NewFetcher(time.Duration(cfg.TimeoutSettings.ArticleFetchTimeoutSeconds)*time.Second, cfg.SizeLimitSettings.ArticleHTMLMaxBytes())
 	articleExtractor := article.NewExtractor(cfg.TimeoutSettings.MinTextLength, cfg.TimeoutSettings.MaxTextLength)
-	llmClient := llm.NewClient(geminiAPIKey)
-	llmEvaluator := llm.NewEvaluator(llmClient)
+	articleExtractor.SetFetcher(articleFetcher)
+	if httpCache != nil {
+		rssFetcher.SetCache(httpCache)
+		articleFetcher.SetCache(httpCache)
+	}
+	llmProvider, err := llm.NewProvider(cfg.LLMSettings, llmAPIKey, cfg.SizeLimitSettings.LLMResponseMaxBytes())
+	if err != nil {
+		log.Fatalf("LLMクライアントの初期化に失敗: %v", err)
+	}
+	llmEvaluator := llm.NewEvaluator(llmProvider)
+	llmEvaluator.SetBatchOptions(llm.BatchOptionsFromSettings(cfg.LLMSettings))
+	llmEvaluator.SetPrompts(prompts)
+	llmEvaluator.SetTriageOptions(llm.TriageOptionsFromSettings(cfg.TriageSettings))
+	// 同じ本文・興味トピック・プロンプト・モデルで評価済みの記事はLLMを呼び出さずにキャッシュを使う
+	llmEvaluator.SetCache(store, cfg.InterestsHash())
 	discordClient := discord.NewClient(discordWebhookURL, logger)
+	discordClient.SetMaxResponseSize(cfg.SizeLimitSettings.DiscordResponseMaxBytes())
 
 	// メインオーケストレーションを実行
 	if err := orchestrateCuration(
@@ -106,7 +165,7 @@ func main() {
 		articleExtractor,
 		llmEvaluator,
 		discordClient,
-		firestoreClient,
+		store,
 		logger,
 		3, // ローカルテストではAPI制限のため3件に制限
 	); err != nil {
@@ -127,10 +186,39 @@ func orchestrateCuration(
 	articleExtractor *article.Extractor,
 	llmEvaluator *llm.Evaluator,
 	discordClient *discord.Client,
-	firestoreClient *storage.Client,
+	store storage.Store,
 	logger logging.Logger,
 	maxEvaluationArticles int, // 評価する記事の最大数（0=無制限、ローカルテストでは3）
-) error {
+) (err error) {
+	// 実行履歴を記録（途中で終了した場合も含めて終了時に保存）
+	run := &config.RunHistory{StartedAt: time.Now(), Status: config.RunStatusSuccess}
+	// LLMの使用量を集計する日（1日あたりの使用量の上限の判定に使う）
+	usageDate := run.StartedAt.Format("2006-01-02")
+	defer func() {
+		if err != nil {
+			run.Status = config.RunStatusFailed
+			run.ErrorMessage = err.Error()
+		}
+		run.FinishedAt = time.Now()
+		// この実行でのLLMの使用量を実行履歴に記録し、当日の使用量に加算する
+		run.TokenUsage, run.LLMRequests = llmEvaluator.Usage()
+		if run.LLMRequests > 0 {
+			if usageErr := store.AddDailyUsage(ctx, usageDate, run.TokenUsage, run.LLMRequests); usageErr != nil {
+				logger.Error("LLMの使用量の保存に失敗", "error", usageErr)
+			}
+			daily := llmEvaluator.DailyUsage()
+			logger.Info("LLMの使用量",
+				"requests", run.LLMRequests,
+				"totalTokens", run.TokenUsage.TotalTokens,
+				"dailyRequests", daily.Requests,
+				"dailyTotalTokens", daily.TotalTokens,
+			)
+		}
+		if saveErr := store.SaveRunHistory(ctx, run); saveErr != nil {
+			logger.Error("実行履歴の保存に失敗", "error", saveErr)
+		}
+	}()
+
 	// 1. RSSフィードから記事を取得
 	logger.Info("RSSフィードから記事を取得中")
 	allArticles := []rss.Article{}
@@ -163,23 +251,24 @@ func orchestrateCuration(
 		return nil
 	}
 
+	run.FetchedCount = len(allArticles)
 	logger.Info("すべてのRSSフィードから記事を取得しました", "totalCount", len(allArticles))
 
 	// 2. 重複チェック（通知済み・却下済み記事を除外）
 	logger.Info("重複チェックを実行中")
 	filteredArticles := []rss.Article{}
-	var firestoreErrorCount int
+	var storageErrorCount int
 	var notifiedSkipCount, rejectedSkipCount int
-	const maxFirestoreErrors = 10
+	const maxStorageErrors = 10
 
 	for _, article := range allArticles {
 		// 通知済みチェック
-		notified, err := firestoreClient.IsArticleNotified(ctx, article.URL)
+		notified, err := store.IsArticleNotified(ctx, article.URL)
 		if err != nil {
-			firestoreErrorCount++
+			storageErrorCount++
 			logger.Error("通知済みチェックに失敗しました", "url", article.URL, "error", err)
-			if firestoreErrorCount >= maxFirestoreErrors {
-				return fmt.Errorf("Firestoreエラーが多すぎます（%d件）。処理を中止します", firestoreErrorCount)
+			if storageErrorCount >= maxStorageErrors {
+				return fmt.Errorf("ストレージエラーが多すぎます（%d件）。処理を中止します", storageErrorCount)
 			}
 			// エラー時は安全側に倒して処理を続行（重複のリスクはあるが、記事を見逃すよりまし）
 			filteredArticles = append(filteredArticles, article)
@@ -191,12 +280,13 @@ func orchestrateCuration(
 		}
 
 		// 却下済みチェック
-		rejected, err := firestoreClient.IsArticleRejected(ctx, article.URL)
+		// 評価条件が変わった却下済み記事は、ここで再評価の予算を消費して評価の対象にする
+		rejected, err := store.IsArticleRejected(ctx, article.URL, true)
 		if err != nil {
-			firestoreErrorCount++
+			storageErrorCount++
 			logger.Error("却下済みチェックに失敗しました", "url", article.URL, "error", err)
-			if firestoreErrorCount >= maxFirestoreErrors {
-				return fmt.Errorf("Firestoreエラーが多すぎます（%d件）。処理を中止します", firestoreErrorCount)
+			if storageErrorCount >= maxStorageErrors {
+				return fmt.Errorf("ストレージエラーが多すぎます（%d件）。処理を中止します", storageErrorCount)
 			}
 			// エラー時は安全側に倒して処理を続行
 			filteredArticles = append(filteredArticles, article)
@@ -210,12 +300,16 @@ func orchestrateCuration(
 		filteredArticles = append(filteredArticles, article)
 	}
 
+	run.FilteredCount = len(filteredArticles)
+	run.RecheckedCount = store.RechecksUsed()
+
 	logger.Info("重複チェック完了",
 		"originalCount", len(allArticles),
 		"filteredCount", len(filteredArticles),
 		"notifiedSkipped", notifiedSkipCount,
 		"rejectedSkipped", rejectedSkipCount,
-		"firestoreErrors", firestoreErrorCount,
+		"rechecked", run.RecheckedCount,
+		"storageErrors", storageErrorCount,
 	)
 
 	if len(filteredArticles) == 0 {
@@ -223,6 +317,35 @@ func orchestrateCuration(
 		return nil
 	}
 
+	// 当日のLLMの使用量を読み込み、1日あたりの上限（llm_settings.daily_token_budget・daily_request_budget）に達した後は評価しない
+	dailyUsage, usageErr := store.GetDailyUsage(ctx, usageDate)
+	if usageErr != nil {
+		logger.Warn("LLMの使用量の取得に失敗しました。この実行の使用量のみで上限を判定します", "error", usageErr)
+		dailyUsage = &config.DailyUsage{Date: usageDate}
+	}
+	llmEvaluator.SetBudget(llm.BudgetFromSettings(cfg.LLMSettings), *dailyUsage)
+	if llmEvaluator.BudgetExhausted() {
+		logger.Warn("LLMの1日あたりの使用量の上限に達しています。評価キャッシュにない記事は評価しません",
+			"dailyRequests", dailyUsage.Requests,
+			"dailyTotalTokens", dailyUsage.TotalTokens,
+		)
+	}
+
+	// 本文を取得する前に、タイトルとフィードの要約で興味トピックと明らかに無関係な記事を除く（triage_settings.modeを設定した場合のみ）
+	if llmEvaluator.TriageEnabled() {
+		filteredArticles = triageArticles(ctx, logger, llmEvaluator, store, cfg.Interests, filteredArticles)
+		run.TriageRejected = run.FilteredCount - len(filteredArticles)
+		logger.Info("トリアージ完了",
+			"mode", cfg.TriageSettings.Mode,
+			"keptCount", len(filteredArticles),
+			"rejectedCount", run.TriageRejected,
+		)
+		if len(filteredArticles) == 0 {
+			logger.Info("トリアージの結果、評価する記事がありませんでした")
+			return nil
+		}
+	}
+
 	// 2.5. 記事数を制限（ローカルテスト用）
 	if maxEvaluationArticles > 0 && len(filteredArticles) > maxEvaluationArticles {
 		logger.Info("記事数を制限します",
@@ -236,83 +359,172 @@ func orchestrateCuration(
 	// 3. 記事コンテンツを取得して評価
 	logger.Info("記事を評価中")
 	evaluatedArticles := []config.ArticleEvaluation{}
-
-	// 興味トピックをリストに変換
-	interestTopics := make([]string, len(cfg.Interests))
-	for i, interest := range cfg.Interests {
-		interestTopics[i] = interest.Topic
-	}
+	// Discordの埋め込み表示に使うため、抽出した記事のメタデータをURLごとに保持する
+	contentByURL := make(map[string]*config.Article, len(filteredArticles))
+	// 評価する記事（すべての記事の取得・抽出が終わってから、まとめてLLMで評価する）
+	var candidates []llm.BatchItem
+	// 記事のURL（リダイレクト後の最終URL）ごとのフィードの記事
+	articlesByURL := make(map[string]rss.Article, len(filteredArticles))
+	// 今回の実行で評価する記事と、同じ記事を指すフィードのリンク（別名）
+	runArticles := storage.NewRunArticles(store)
 
 	for _, rssArticle := range filteredArticles {
-		// 記事HTMLを取得
-		htmlContent, err := articleFetcher.Fetch(ctx, rssArticle.URL)
+		// 記事HTMLを取得して本文とタイトルを抽出
+		var extracted *article.ExtractedContent
+		// フィードのリンクがリダイレクタ・短縮URLの場合は、リダイレクト後の最終URLを記事のURLとし、
+		// フィードのリンク（originalURL）は別名として記録する
+		articleURL, originalURL := rssArticle.URL, ""
+		doc, err := articleFetcher.FetchDocument(ctx, rssArticle.URL)
+		if err == nil && doc.Redirected() {
+			articleURL, originalURL = doc.URL, rssArticle.URL
+		}
+		// 別のリンク（リダイレクタ・短縮URL・他のフィード）から同じ記事を今回すでに取得している場合や、
+		// リダイレクト後の最終URLが処理済みの場合は重複としてスキップする（フィードのリンクは記事の別名として記録する）
+		added, dupErr := runArticles.Add(ctx, articleURL, rssArticle.URL)
+		if dupErr != nil {
+			logger.Warn("記事のURLの重複チェックに失敗しました", "url", rssArticle.URL, "finalURL", articleURL, "error", dupErr)
+		}
+		if !added {
+			logger.Info("同じ記事を処理済みのためスキップします", "url", rssArticle.URL, "finalURL", articleURL)
+			continue
+		}
+		articlesByURL[articleURL] = rssArticle
 		if err != nil {
-			logger.Warn("記事HTMLの取得に失敗しました。スキップします", "url", rssArticle.URL, "error", err)
-			// コンテンツ抽出失敗として記録
-			if saveErr := firestoreClient.SaveRejectedArticle(ctx, rssArticle.URL, config.ReasonContentExtractionFailed, nil); saveErr != nil {
-				logger.Error("却下記事の保存に失敗", "url", rssArticle.URL, "error", saveErr)
+			logger.Warn("記事の取得に失敗しました", "url", rssArticle.URL, "error", err)
+		} else if extracted, err = articleExtractor.ExtractDocument(ctx, doc); err != nil {
+			logger.Warn("記事本文の抽出に失敗しました", "url", articleURL, "error", err)
+		}
+		if err != nil {
+			reason := article.RejectionReason(err)
+			// 有料記事はフィードの要約が十分な長さであれば、要約のみで評価する
+			if reason == config.ReasonPaywalled {
+				extracted = articleExtractor.FeedSummaryContent(rssArticle.Summary)
 			}
-			continue
+			if extracted == nil {
+				logger.Info("記事をスキップします", "url", articleURL, "reason", reason)
+				if saveErr := storage.SaveRejectedArticleWithAliases(ctx, store, articleURL, runArticles.Aliases(articleURL), reason, nil); saveErr != nil {
+					logger.Error("却下記事の保存に失敗", "url", articleURL, "error", saveErr)
+				}
+				continue
+			}
+			logger.Info("有料記事のためフィードの要約で評価します", "url", articleURL)
 		}
 
-		// 記事本文とタイトルを抽出
-		extractedTitle, extractedText, err := articleExtractor.ExtractWithTitle(ctx, htmlContent, rssArticle.URL)
-		if err != nil {
-			logger.Warn("記事本文の抽出に失敗しました。スキップします", "url", rssArticle.URL, "error", err)
-			// コンテンツ抽出失敗として記録
-			if saveErr := firestoreClient.SaveRejectedArticle(ctx, rssArticle.URL, config.ReasonContentExtractionFailed, nil); saveErr != nil {
-				logger.Error("却下記事の保存に失敗", "url", rssArticle.URL, "error", saveErr)
+		// タイトルが抽出された場合は使用、そうでなければRSSのタイトルを使用
+		// ソース・興味トピックの言語フィルターに一致しない記事は評価しない
+		interestTopics := cfg.TopicsForLanguage(extracted.Language)
+		if !cfg.SourceAcceptsLanguage(rssArticle.SourceFeed, extracted.Language) || len(interestTopics) == 0 {
+			logger.Info("言語フィルターにより記事をスキップします", "url", articleURL, "language", extracted.Language, "source", rssArticle.SourceFeed)
+			if saveErr := storage.SaveRejectedArticleWithAliases(ctx, store, articleURL, runArticles.Aliases(articleURL), config.ReasonLanguageMismatch, nil); saveErr != nil {
+				logger.Error("却下記事の保存に失敗", "url", articleURL, "error", saveErr)
 			}
 			continue
 		}
 
-		// タイトルが抽出された場合は使用、そうでなければRSSのタイトルを使用
 		title := rssArticle.Title
-		if extractedTitle != "" {
-			title = extractedTitle
+		if extracted.Title != "" {
+			title = extracted.Title
 		}
 
 		// config.Articleを作成
 		configArticle := &config.Article{
 			Title:         title,
-			URL:           rssArticle.URL,
+			URL:           articleURL,
+			OriginalURL:   originalURL,
 			PublishedDate: rssArticle.PublishedDate,
 			SourceFeed:    rssArticle.SourceFeed,
-			ContentText:   extractedText,
+			ContentText:   extracted.Text,
 			FetchedAt:     rssArticle.FetchedAt,
+			ContentStats:  extracted.Stats,
+
+			Author:             extracted.Author,
+			SiteName:           extracted.SiteName,
+			ImageURL:           extracted.ImageURL,
+			ReadingTimeMinutes: extracted.ReadingTimeMinutes,
+			Paywalled:          extracted.Paywalled,
+			Tags:               extracted.Tags,
+			LikeCount:          extracted.LikeCount,
+			Language:           extracted.Language,
+		}
+		if configArticle.PublishedDate.IsZero() {
+			configArticle.PublishedDate = extracted.PublishedAt
 		}
 
 		// LLMで評価
-		evaluation, err := llmEvaluator.EvaluateArticle(ctx, configArticle, interestTopics, cfg.NotificationSettings.MinRelevanceScore)
-		if err != nil {
-			logger.Error("記事の評価に失敗しました。スキップします", "url", rssArticle.URL, "error", err)
+		candidates = append(candidates, llm.BatchItem{Article: configArticle, Topics: interestTopics})
+	}
+
+	// 複数の記事を1回のLLM呼び出しで評価する（llm_settings.batch_sizeが1以下の場合は1記事ずつ評価）
+	quotaSkipped, budgetSkipped := 0, 0
+	for _, result := range llmEvaluator.EvaluateArticles(ctx, candidates, cfg.NotificationSettings.MinRelevanceScore) {
+		configArticle, evaluation := result.Article, result.Evaluation
+		articleURL := configArticle.URL
+		if result.Err != nil {
+			// クォータ超過で評価できなかった記事は却下済みにしないため、次回の実行で評価される
+			if llm.IsQuotaExceeded(result.Err) {
+				quotaSkipped++
+				continue
+			}
+			// 1日あたりの使用量の上限で評価できなかった記事も同様に、次回の実行で評価される
+			if llm.IsBudgetExceeded(result.Err) {
+				budgetSkipped++
+				continue
+			}
+			logger.Error("記事の評価に失敗しました。スキップします", "url", articleURL, "error", result.Err)
 			continue
 		}
 
+		run.EvaluatedCount++
+
+		// スコアの根拠を後から監査できるよう、評価記録を保存する（失敗しても処理は継続）
+		if saveErr := store.SaveEvaluation(ctx, config.NewEvaluationRecord(configArticle, evaluation)); saveErr != nil {
+			logger.Warn("評価記録の保存に失敗", "url", articleURL, "error", saveErr)
+		}
+
 		logger.Info("記事を評価しました",
-			"url", rssArticle.URL,
+			"url", articleURL,
 			"score", evaluation.RelevanceScore,
+			"rankingScore", evaluation.RankingScore,
+			"cached", evaluation.Cached,
 			"isRelevant", evaluation.IsRelevant,
 		)
 
 		// 関連性がない記事は却下
 		if !evaluation.IsRelevant {
-			logger.Debug("関連性がない記事を却下", "url", rssArticle.URL, "score", evaluation.RelevanceScore)
+			logger.Debug("関連性がない記事を却下", "url", articleURL, "score", evaluation.RelevanceScore)
 			// 却下理由を判定して保存
 			reason := config.ReasonLowRelevance
 			if len(evaluation.MatchingTopics) == 0 {
 				reason = config.ReasonNoTopicMatch
 			}
-			if saveErr := firestoreClient.SaveRejectedArticle(ctx, rssArticle.URL, reason, &evaluation.RelevanceScore); saveErr != nil {
-				logger.Error("却下記事の保存に失敗", "url", rssArticle.URL, "error", saveErr)
+			if saveErr := storage.SaveRejectedArticleWithAliases(ctx, store, articleURL, runArticles.Aliases(articleURL), reason, &evaluation.RelevanceScore); saveErr != nil {
+				logger.Error("却下記事の保存に失敗", "url", articleURL, "error", saveErr)
 			}
 			continue
 		}
 
 		evaluatedArticles = append(evaluatedArticles, *evaluation)
+		contentByURL[articleURL] = configArticle
 	}
 
-	logger.Info("記事の評価完了", "relevantCount", len(evaluatedArticles))
+	if quotaSkipped > 0 {
+		logger.Warn("LLMのクォータを超過したため、一部の記事を評価できませんでした。次回の実行で評価します",
+			"skippedCount", quotaSkipped,
+			"stopped", llmEvaluator.QuotaExhausted(),
+		)
+	}
+	if budgetSkipped > 0 {
+		run.BudgetExhausted = true
+		logger.Warn("LLMの1日あたりの使用量の上限に達したため、一部の記事を評価できませんでした。評価済みの記事を通知します",
+			"skippedCount", budgetSkipped,
+		)
+	}
+	run.CacheHits, run.CacheMisses = llmEvaluator.CacheStats()
+	logger.Info("記事の評価完了",
+		"relevantCount", len(evaluatedArticles),
+		"cacheHits", run.CacheHits,
+		"cacheMisses", run.CacheMisses,
+	)
 
 	if len(evaluatedArticles) == 0 {
 		logger.Info("関連性のある記事が見つかりませんでした")
@@ -321,6 +533,10 @@ func orchestrateCuration(
 
 	// 4. スコア順にソートして上位記事を選択
 	sort.Slice(evaluatedArticles, func(i, j int) bool {
+		// 興味トピックの優先度を反映したランキングスコアで並べ、同点の場合はLLMのスコアで並べる
+		if evaluatedArticles[i].RankingScore != evaluatedArticles[j].RankingScore {
+			return evaluatedArticles[i].RankingScore > evaluatedArticles[j].RankingScore
+		}
 		return evaluatedArticles[i].RelevanceScore > evaluatedArticles[j].RelevanceScore
 	})
 
@@ -333,12 +549,6 @@ func orchestrateCuration(
 	logger.Info("上位記事を選択しました", "count", len(evaluatedArticles))
 
 	// 5. Discord通知用のペイロードを作成
-	// パフォーマンス最適化: 記事URLからの高速検索用マップを作成（O(n²) → O(n)）
-	articlesByURL := make(map[string]rss.Article, len(filteredArticles))
-	for _, article := range filteredArticles {
-		articlesByURL[article.URL] = article
-	}
-
 	discordArticles := make([]discord.Article, len(evaluatedArticles))
 	for i, eval := range evaluatedArticles {
 		// マップから記事を取得（O(1)検索）
@@ -358,6 +568,15 @@ func orchestrateCuration(
 			Topics:      eval.MatchingTopics,
 			Source:      sourceFeed,
 		}
+		if content, ok := contentByURL[eval.ArticleURL]; ok {
+			discordArticles[i].SiteName = content.SiteName
+			discordArticles[i].Author = content.Author
+			discordArticles[i].ThumbnailURL = content.ImageURL
+			discordArticles[i].PublishedAt = content.PublishedDate
+			discordArticles[i].ReadingTimeMinutes = content.ReadingTimeMinutes
+			discordArticles[i].Paywalled = content.Paywalled
+			discordArticles[i].Language = content.Language
+		}
 	}
 
 	// 6. 記事全体のサマリーを生成
@@ -377,6 +596,9 @@ func orchestrateCuration(
 			RelevanceScore: eval.RelevanceScore,
 			MatchingTopics: eval.MatchingTopics,
 		}
+		if content, ok := contentByURL[eval.ArticleURL]; ok {
+			llmArticles[i].Language = content.Language
+		}
 	}
 
 	// サマリーを生成
@@ -397,6 +619,14 @@ func orchestrateCuration(
 			Recommendations: summaryResult.Recommendations,
 		}
 	}
+	// ダイジェストのフッターにLLMの使用量を表示する（サマリーを生成できなかった場合はフッターのみ表示する）
+	if cfg.LLMSettings.UsageFooter {
+		if discordSummary == nil {
+			discordSummary = &discord.ArticlesSummary{}
+		}
+		runUsage, runRequests := llmEvaluator.Usage()
+		discordSummary.Footer = discord.FormatUsageFooter(runUsage, runRequests, llmEvaluator.DailyUsage(), cfg.LLMSettings)
+	}
 
 	// 7. Discordに通知
 	logger.Info("Discordに通知中", "articleCount", len(discordArticles))
@@ -408,9 +638,11 @@ func orchestrateCuration(
 	}
 
 	logger.Info("Discordへの通知に成功しました", "messageID", messageID)
+	run.NotifiedCount = len(discordArticles)
+	run.DiscordMessageID = messageID
 
-	// 8. 通知済み記事をFirestoreに保存
-	logger.Info("通知済み記事をFirestoreに保存中")
+	// 8. 通知済み記事をストレージに保存
+	logger.Info("通知済み記事をストレージに保存中")
 	for _, eval := range evaluatedArticles {
 		// マップから記事タイトルを取得（O(1)検索）
 		article, ok := articlesByURL[eval.ArticleURL]
@@ -418,7 +650,7 @@ func orchestrateCuration(
 		if ok {
 			title = article.Title
 		}
-		if err := firestoreClient.SaveNotifiedArticle(ctx, eval.ArticleURL, messageID, title, eval.RelevanceScore); err != nil {
+		if err := storage.SaveNotifiedArticleWithAliases(ctx, store, eval.ArticleURL, runArticles.Aliases(eval.ArticleURL), messageID, title, eval.RelevanceScore); err != nil {
 			logger.Error("通知済み記事の保存に失敗", "url", eval.ArticleURL, "error", err)
 			// エラーをログに記録するが、処理は続行
 		}
@@ -427,3 +659,25 @@ func orchestrateCuration(
 	logger.Info("通知済み記事の保存完了")
 	return nil
 }
+
+// triageArticles は本文を取得する前に、タイトルとフィードの要約で評価の候補として残す記事を判定します
+// 候補から除いた記事は、興味トピックの変更時に再評価されるようtriage_rejectedの理由で却下済みとして記録します
+func triageArticles(ctx context.Context, logger logging.Logger, llmEvaluator *llm.Evaluator, store storage.Store, topics []config.InterestTopic, articles []rss.Article) []rss.Article {
+	items := make([]llm.TriageItem, len(articles))
+	for i, rssArticle := range articles {
+		items[i] = llm.TriageItem{Title: rssArticle.Title, Description: rssArticle.Summary}
+	}
+
+	kept := make([]rss.Article, 0, len(articles))
+	for i, keep := range llmEvaluator.TriageArticles(ctx, items, topics) {
+		if keep {
+			kept = append(kept, articles[i])
+			continue
+		}
+		logger.Debug("トリアージにより記事をスキップします", "url", articles[i].URL, "title", articles[i].Title)
+		if saveErr := store.SaveRejectedArticle(ctx, articles[i].URL, config.ReasonTriageRejected, nil); saveErr != nil {
+			logger.Error("却下記事の保存に失敗", "url", articles[i].URL, "error", saveErr)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,89 @@
+// Package main は保持期間を過ぎたFirestoreドキュメントを削除するパージコマンドです
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+	"github.com/kaka0913/discord-article-bot/internal/storage"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", storage.DefaultPurgeBatchSize, "1バッチあたりに取得・削除するドキュメント数")
+	dryRun := flag.Bool("dry-run", false, "削除せずに削除対象の件数のみ表示する")
+	flag.Parse()
+
+	// .envファイルを読み込む
+	if err := godotenv.Load(); err != nil {
+		log.Printf("警告: .envファイルの読み込みに失敗しました: %v", err)
+	}
+
+	logger := logging.NewLogger()
+	ctx := logging.ToContext(context.Background(), logger)
+
+	projectID := os.Getenv("GCP_PROJECT_ID")
+	if projectID == "" {
+		log.Fatal("GCP_PROJECT_ID環境変数が設定されていません")
+	}
+
+	// CONFIG_URLを優先し、未設定の場合はCONFIG_SOURCE、ローカルのconfig.jsonの順に参照
+	configSource := os.Getenv("CONFIG_URL")
+	if configSource == "" {
+		configSource = os.Getenv("CONFIG_SOURCE")
+	}
+	if configSource == "" {
+		configSource = "config.json"
+	}
+
+	configLoader := config.NewLoader()
+	cfg, err := configLoader.Load(ctx, configSource)
+	if err != nil {
+		log.Fatalf("設定の読み込みに失敗: %v", err)
+	}
+
+	if err := config.ValidateConfig(cfg); err != nil {
+		log.Fatalf("設定の検証に失敗: %v", err)
+	}
+
+	firestoreClient, err := storage.NewClient(ctx, projectID)
+	if err != nil {
+		log.Fatalf("Firestoreクライアントの初期化に失敗: %v", err)
+	}
+	defer firestoreClient.Close()
+
+	firestoreClient.SetRetentionSettings(cfg.RetentionSettings)
+
+	logger.Info("期限切れドキュメントのパージを開始します", "batchSize", *batchSize, "dryRun", *dryRun)
+
+	result, err := firestoreClient.PurgeExpired(ctx, storage.PurgeOptions{
+		BatchSize: *batchSize,
+		DryRun:    *dryRun,
+	})
+	if err != nil {
+		logger.Error("パージ処理に失敗しました", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("パージ処理が完了しました",
+		"notifiedDeleted", result.NotifiedDeleted,
+		"rejectedDeleted", result.RejectedDeleted,
+		"evaluationsDeleted", result.EvaluationsDeleted,
+		"cacheDeleted", result.CacheDeleted,
+		"total", result.Total(),
+		"dryRun", *dryRun,
+	)
+
+	label := "削除"
+	if *dryRun {
+		label = "削除対象"
+	}
+	fmt.Printf("%s件数: notified_articles=%d, rejected_articles=%d, evaluations=%d, evaluation_cache=%d, 合計=%d\n",
+		label, result.NotifiedDeleted, result.RejectedDeleted, result.EvaluationsDeleted, result.CacheDeleted, result.Total())
+}
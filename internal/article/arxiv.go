@@ -0,0 +1,78 @@
+package article
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// arxivHosts はarXivのホスト名
+var arxivHosts = map[string]bool{
+	"arxiv.org":        true,
+	"www.arxiv.org":    true,
+	"export.arxiv.org": true,
+}
+
+// isArxivAbstract はURLがarXivの論文の概要ページ（/abs/...）かどうかを返す
+func isArxivAbstract(u *url.URL) bool {
+	return arxivHosts[strings.ToLower(u.Hostname())] && strings.HasPrefix(u.Path, "/abs/")
+}
+
+// arxivPaper はarXivの概要ページのメタデータ（Highwire Pressのcitation_*タグ）から取得した論文情報を表す
+type arxivPaper struct {
+	title       string
+	authors     []string
+	abstract    string
+	publishedAt time.Time
+}
+
+// parseArxivAbstract はarXivの概要ページのmetaタグから論文のタイトル・著者・概要を取得する
+// タイトルまたは概要が取得できない場合はfalseを返す
+func parseArxivAbstract(htmlContent string) (*arxivPaper, bool) {
+	tags := metaTags(htmlContent)
+
+	paper := &arxivPaper{
+		title:    collapseSpaces(firstMeta(tags, "citation_title", "og:title")),
+		abstract: collapseSpaces(firstMeta(tags, "citation_abstract", "og:description", "description")),
+	}
+	for _, author := range tags["citation_author"] {
+		paper.authors = append(paper.authors, arxivAuthorName(author))
+	}
+	// citation_dateは「2023/10/05」形式
+	if date := firstMeta(tags, "citation_date", "citation_online_date"); date != "" {
+		if t, err := time.Parse("2006/01/02", date); err == nil {
+			paper.publishedAt = t
+		}
+	}
+
+	if paper.title == "" || paper.abstract == "" {
+		return nil, false
+	}
+	return paper, true
+}
+
+// markdown は論文情報を評価用の軽量なMarkdownに変換する
+func (p *arxivPaper) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", p.title)
+	if len(p.authors) > 0 {
+		fmt.Fprintf(&b, "著者: %s\n\n", strings.Join(p.authors, ", "))
+	}
+	fmt.Fprintf(&b, "## Abstract\n\n%s", p.abstract)
+	return b.String()
+}
+
+// arxivAuthorName はcitation_authorの「姓, 名」形式を「名 姓」に変換する
+func arxivAuthorName(author string) string {
+	last, first, ok := strings.Cut(author, ",")
+	if !ok {
+		return strings.TrimSpace(author)
+	}
+	return strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+}
+
+// collapseSpaces は改行を含む連続する空白を1つの空白にまとめる
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
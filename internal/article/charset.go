@@ -0,0 +1,147 @@
+package article
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	// metaScanSize は<meta charset>を探索するHTML先頭のバイト数
+	// WHATWGの規定は1024バイトだが、head内に長いスクリプト等を持つブログがあるため広めに取る
+	metaScanSize = 8 * 1024
+
+	// charsetUTF8 はUTF-8の文字コード名
+	charsetUTF8 = "utf-8"
+)
+
+// metaCharsetPattern は<meta charset="...">と<meta http-equiv="Content-Type" content="...; charset=...">の両方にマッチする
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-zA-Z0-9_:.\-]+)`)
+
+// bomEncodings はBOM（バイトオーダーマーク）と対応する文字コード
+var bomEncodings = []struct {
+	bom  []byte
+	enc  encoding.Encoding
+	name string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, unicode.UTF8BOM, charsetUTF8},
+	{[]byte{0xFE, 0xFF}, unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "utf-16be"},
+	{[]byte{0xFF, 0xFE}, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "utf-16le"},
+}
+
+// decodeToUTF8 はHTMLの文字コードを判定し、UTF-8の文字列に変換する
+// 判定の優先順位はBOM → Content-Typeヘッダー → <meta charset> → 内容からの推測
+// 戻り値の2番目は判定した文字コード名（ログ用）
+func decodeToUTF8(body []byte, contentType string) (string, string, error) {
+	enc, name := detectCharset(body, contentType)
+	if enc == nil {
+		return string(body), name, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return "", name, err
+	}
+	return string(decoded), name, nil
+}
+
+// detectCharset はHTMLの文字コードを判定する
+// UTF-8（または判定できない場合）は変換不要としてnilを返す
+func detectCharset(body []byte, contentType string) (encoding.Encoding, string) {
+	for _, b := range bomEncodings {
+		if bytes.HasPrefix(body, b.bom) {
+			return b.enc, b.name
+		}
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if enc, name := lookupCharset(params["charset"]); name != "" {
+			return enc, name
+		}
+	}
+
+	head := body
+	if len(head) > metaScanSize {
+		head = head[:metaScanSize]
+	}
+	if m := metaCharsetPattern.FindSubmatch(head); m != nil {
+		if enc, name := lookupCharset(string(m[1])); name != "" {
+			return enc, name
+		}
+	}
+
+	if utf8.Valid(body) {
+		return nil, charsetUTF8
+	}
+	return sniffJapanese(body)
+}
+
+// lookupCharset は文字コードのラベルから文字コードを取得する
+// UTF-8の場合は変換不要としてnilを返し、未知のラベルの場合は名前も空文字列を返す
+func lookupCharset(label string) (encoding.Encoding, string) {
+	if label == "" {
+		return nil, ""
+	}
+	enc, name := charset.Lookup(label)
+	if enc == nil {
+		return nil, ""
+	}
+	if name == charsetUTF8 {
+		return nil, name
+	}
+	return enc, name
+}
+
+// sniffJapanese は文字コードの宣言がない非UTF-8のHTMLについて、日本語の文字コードを推測する
+// Shift_JISとEUC-JPで試しに変換し、不正なバイト列が少なく、かなの割合が高い方を採用する
+// どちらでも変換できない場合は変換しない（nil）
+func sniffJapanese(body []byte) (encoding.Encoding, string) {
+	candidates := []struct {
+		enc  encoding.Encoding
+		name string
+	}{
+		{japanese.ShiftJIS, "shift_jis"},
+		{japanese.EUCJP, "euc-jp"},
+	}
+
+	var best encoding.Encoding
+	bestName := ""
+	bestScore := -1
+	for _, c := range candidates {
+		decoded, err := c.enc.NewDecoder().Bytes(body)
+		if err != nil {
+			continue
+		}
+		if score := japaneseScore(decoded); score > bestScore {
+			best, bestName, bestScore = c.enc, c.name, score
+		}
+	}
+
+	if best == nil || bestScore <= 0 {
+		return nil, "unknown"
+	}
+	return best, bestName
+}
+
+// japaneseScore は変換結果の日本語らしさを数値化する
+// ひらがな・カタカナを加点し、変換できなかった文字（U+FFFD）を大きく減点する
+func japaneseScore(decoded []byte) int {
+	score := 0
+	for len(decoded) > 0 {
+		r, size := utf8.DecodeRune(decoded)
+		decoded = decoded[size:]
+		switch {
+		case r == utf8.RuneError:
+			score -= 10
+		case r >= 0x3040 && r <= 0x30FF:
+			score++
+		}
+	}
+	return score
+}
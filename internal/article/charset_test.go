@@ -0,0 +1,128 @@
+package article
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	// fixtureTitle はテスト用フィクスチャに含まれる記事タイトル
+	fixtureTitle = "Goのエラー処理入門"
+)
+
+func TestFetcher_Fetch_Charset(t *testing.T) {
+	shiftJIS := readFixture(t, "shift_jis.html")
+	eucJP := readFixture(t, "euc-jp.html")
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+	}{
+		{
+			name:        "Shift_JIS（meta charset）",
+			body:        shiftJIS,
+			contentType: "text/html",
+		},
+		{
+			name:        "EUC-JP（meta http-equiv）",
+			body:        eucJP,
+			contentType: "text/html",
+		},
+		{
+			name:        "Content-Typeヘッダーを優先",
+			body:        removeMetaCharset(shiftJIS),
+			contentType: "text/html; charset=Shift_JIS",
+		},
+		{
+			name:        "宣言なしShift_JISの推測",
+			body:        removeMetaCharset(shiftJIS),
+			contentType: "text/html",
+		},
+		{
+			name:        "宣言なしEUC-JPの推測",
+			body:        removeMetaCharset(eucJP),
+			contentType: "text/html",
+		},
+		{
+			name:        "UTF-8（BOM付き）",
+			body:        append([]byte{0xEF, 0xBB, 0xBF}, []byte("<html><head><title>"+fixtureTitle+"</title></head></html>")...),
+			contentType: "text/html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			fetcher := newTestFetcher()
+			htmlContent, _, err := fetcher.Fetch(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+
+			if !strings.Contains(htmlContent, fixtureTitle) {
+				t.Errorf("UTF-8に変換されていません: %q", truncateUTF8(htmlContent, 200))
+			}
+			if strings.HasPrefix(htmlContent, "\uFEFF") {
+				t.Error("BOMが除去されていません")
+			}
+		})
+	}
+}
+
+func TestExtractor_ExtractContent_ShiftJIS(t *testing.T) {
+	htmlContent, _, err := decodeToUTF8(readFixture(t, "shift_jis.html"), "text/html")
+	if err != nil {
+		t.Fatalf("decodeToUTF8 failed: %v", err)
+	}
+
+	extractor := NewExtractor(50, 100000)
+	content, err := extractor.ExtractContent(context.Background(), htmlContent, "https://example.com/sjis")
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+	if !strings.Contains(content.Text, "センチネルエラー") {
+		t.Errorf("本文が文字化けしています: %q", content.Text)
+	}
+}
+
+func TestDetectCharset_UnknownLabelFallsBack(t *testing.T) {
+	body := []byte(`<html><head><meta charset="x-unknown"><title>` + fixtureTitle + `</title></head></html>`)
+
+	enc, name := detectCharset(body, "text/html; charset=x-unknown")
+	if enc != nil || name != charsetUTF8 {
+		t.Errorf("未知の文字コードはUTF-8として扱われるべき: name=%s", name)
+	}
+}
+
+// readFixture はtestdataディレクトリのフィクスチャを読み込む
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+	return data
+}
+
+// removeMetaCharset はフィクスチャから文字コードを宣言するmeta要素の行を取り除く
+func removeMetaCharset(body []byte) []byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if !metaCharsetPattern.Match(line) {
+			lines = append(lines, line)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/go-shiori/go-readability"
 
+	"github.com/kaka0913/discord-article-bot/internal/config"
 	"github.com/kaka0913/discord-article-bot/internal/errors"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
 )
@@ -17,6 +19,7 @@ import (
 type Extractor struct {
 	minTextLength int
 	maxTextLength int
+	api           jsonFetcher // サイト固有の抽出で公開APIを呼び出す（nilの場合はAPIを使う抽出を行わない）
 }
 
 // NewExtractor は新しいExtractorインスタンスを作成する
@@ -27,6 +30,30 @@ func NewExtractor(minTextLength, maxTextLength int) *Extractor {
 	}
 }
 
+// SetFetcher はサイト固有の抽出（dev.to・Qiita・noteの公開API）で使用するFetcherを設定する
+// 設定しない場合、APIを使うサイトもreadabilityで抽出する
+func (e *Extractor) SetFetcher(f *Fetcher) {
+	e.api = f
+}
+
+// ExtractedContent は記事から抽出した内容を表す
+// メタデータは取得できない場合ゼロ値になる
+type ExtractedContent struct {
+	Title              string              // 記事タイトル（取得できない場合は空文字列）
+	Text               string              // 軽量なMarkdown形式の本文（コードブロック・見出し・リストを保持）
+	Stats              config.ContentStats // 本文の構造に関する統計情報
+	Excerpt            string              // 記事の抜粋（og:description等）
+	Author             string              // 著者名（article:author、readabilityのbyline）
+	SiteName           string              // サイト名（og:site_name等）
+	ImageURL           string              // サムネイル画像の絶対URL（og:image等）
+	PublishedAt        time.Time           // 公開日時（article:published_time等）
+	ReadingTimeMinutes int                 // 本文から推定した読了時間（分）
+	Paywalled          bool                // 有料記事のため、本文の代わりにフィードの要約を使用している
+	Tags               []string            // サイトが付与したタグ（サイト固有の抽出でのみ取得）
+	LikeCount          int                 // いいね・リアクション数（サイト固有の抽出でのみ取得）
+	Language           string              // 本文から判定した言語（ISO 639-1、判定できない場合は空文字列）
+}
+
 // Extract はHTMLから記事の本文を抽出する
 // go-readabilityを使用して本文を抽出し、テキストのみを返す
 func (e *Extractor) Extract(ctx context.Context, htmlContent, articleURL string) (string, error) {
@@ -36,57 +63,278 @@ func (e *Extractor) Extract(ctx context.Context, htmlContent, articleURL string)
 
 // ExtractWithTitle はHTMLから記事の本文とタイトルを抽出する
 func (e *Extractor) ExtractWithTitle(ctx context.Context, htmlContent, articleURL string) (title, text string, err error) {
+	content, err := e.ExtractContent(ctx, htmlContent, articleURL)
+	if err != nil {
+		return "", "", err
+	}
+	return content.Title, content.Text, nil
+}
+
+// ExtractDocument は取得したコンテンツの種類に応じて記事のタイトル・本文を抽出する
+// PDFはPDFパーサーで、arXivの概要ページはメタデータから、Zenn・Qiita・dev.to・noteは各サイトの構造化データから、
+// それ以外のHTMLはreadabilityで抽出する
+func (e *Extractor) ExtractDocument(ctx context.Context, doc *Document) (*ExtractedContent, error) {
+	parsedURL, err := url.Parse(doc.URL)
+	if err != nil {
+		return nil, errors.NewValidationError("記事URLのパースに失敗", err)
+	}
+
+	switch {
+	case doc.IsPDF():
+		return e.extractPDF(ctx, doc.PDF, doc.URL)
+	case isArxivAbstract(parsedURL):
+		if content, ok := e.extractArxiv(ctx, doc.HTML, doc.URL); ok {
+			return content, nil
+		}
+		// メタデータが取得できない場合は通常のHTMLとして抽出する
+	}
+	if content, ok := e.extractSite(ctx, doc, parsedURL); ok {
+		return content, nil
+	}
+	return e.ExtractContent(ctx, doc.HTML, doc.URL)
+}
+
+// extractSite はホスト名に対応するサイト固有の抽出処理で記事を抽出する
+// 対応していないホスト・ページの場合や、取得に失敗した場合はfalseを返し、readabilityでの抽出に任せる
+func (e *Extractor) extractSite(ctx context.Context, doc *Document, articleURL *url.URL) (*ExtractedContent, bool) {
+	site, ok := lookupSiteExtractor(articleURL.Hostname())
+	if !ok {
+		return nil, false
+	}
 	logger := logging.FromContext(ctx)
-	logger.Info("記事本文とタイトルを抽出中", "url", articleURL)
 
-	// URLを検証してパース
-	parsedURL, err := url.Parse(articleURL)
+	article, err := site.extract(ctx, e.api, doc, articleURL)
 	if err != nil {
-		return "", "", errors.NewValidationError("記事URLのパースに失敗", err)
+		logger.Warn("サイト固有の抽出に失敗しました。HTMLとして抽出します", "url", doc.URL, "site", site.siteName, "error", err)
+		return nil, false
+	}
+	if article == nil {
+		return nil, false
 	}
 
-	// go-readabilityで本文を抽出
-	article, err := readability.FromReader(strings.NewReader(htmlContent), parsedURL)
+	text, stats, err := article.content()
 	if err != nil {
-		return "", "", errors.NewArticleError("記事本文の抽出に失敗", err)
+		logger.Warn("記事本文の変換に失敗しました。HTMLとして抽出します", "url", doc.URL, "site", site.siteName, "error", err)
+		return nil, false
+	}
+	text, err = e.checkTextLength(ctx, text, doc.URL)
+	if err != nil {
+		logger.Warn("サイト固有の抽出で取得した本文が短すぎます。HTMLとして抽出します", "url", doc.URL, "site", site.siteName, "error", err)
+		return nil, false
 	}
 
-	// タイトルを取得
-	title = strings.TrimSpace(article.Title)
+	logger.Info("サイト固有の抽出に成功",
+		"url", doc.URL,
+		"site", site.siteName,
+		"title", article.title,
+		"textLength", len(text),
+		"tags", article.tags,
+		"likes", article.likeCount,
+	)
+	return &ExtractedContent{
+		Title:              strings.TrimSpace(article.title),
+		Text:               text,
+		Stats:              stats,
+		Excerpt:            strings.TrimSpace(article.excerpt),
+		Author:             strings.TrimSpace(article.author),
+		SiteName:           site.siteName,
+		ImageURL:           resolveURL(articleURL, article.imageURL),
+		PublishedAt:        article.publishedAt,
+		ReadingTimeMinutes: estimateReadingMinutes(text),
+		Tags:               article.tags,
+		LikeCount:          article.likeCount,
+		Language:           detectLanguage(text, htmlLanguage(doc.HTML)),
+	}, true
+}
 
-	// テキストコンテンツを取得（HTMLタグを削除）
-	text = article.TextContent
+// extractPDF はPDFから記事のタイトル・本文を抽出する
+func (e *Extractor) extractPDF(ctx context.Context, body []byte, articleURL string) (*ExtractedContent, error) {
+	logger := logging.FromContext(ctx)
+	logger.Info("PDFから本文を抽出中", "url", articleURL, "size", len(body))
 
-	// テキストをサニタイズ（前後の空白を削除、連続する空白を1つに）
-	text = strings.TrimSpace(text)
-	text = strings.Join(strings.Fields(text), " ")
+	doc, err := parsePDF(body)
+	if err != nil {
+		return nil, errors.NewArticleError("PDFの解析に失敗", err)
+	}
 
-	// テキストの長さを検証
+	text, err := e.checkTextLength(ctx, doc.text, articleURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &ExtractedContent{
+		Title:              pdfTitle(doc),
+		Text:               text,
+		Author:             doc.author,
+		ReadingTimeMinutes: estimateReadingMinutes(text),
+		Language:           detectLanguage(text, ""),
+	}
+	logger.Info("PDFから本文の抽出に成功", "url", articleURL, "title", content.Title, "textLength", len(text))
+	return content, nil
+}
+
+// extractArxiv はarXivの概要ページのメタデータから論文のタイトル・著者・概要を抽出する
+// メタデータが取得できない場合はfalseを返す
+func (e *Extractor) extractArxiv(ctx context.Context, htmlContent, articleURL string) (*ExtractedContent, bool) {
+	logger := logging.FromContext(ctx)
+
+	paper, ok := parseArxivAbstract(htmlContent)
+	if !ok {
+		logger.Warn("arXivのメタデータが見つかりません。HTMLとして抽出します", "url", articleURL)
+		return nil, false
+	}
+
+	text, err := e.checkTextLength(ctx, paper.markdown(), articleURL)
+	if err != nil {
+		logger.Warn("arXivの概要が短すぎます。HTMLとして抽出します", "url", articleURL, "error", err)
+		return nil, false
+	}
+
+	logger.Info("arXivの論文情報の抽出に成功", "url", articleURL, "title", paper.title, "authors", len(paper.authors))
+	return &ExtractedContent{
+		Title:              paper.title,
+		Text:               text,
+		Excerpt:            paper.abstract,
+		Author:             strings.Join(paper.authors, ", "),
+		SiteName:           "arXiv",
+		PublishedAt:        paper.publishedAt,
+		ReadingTimeMinutes: estimateReadingMinutes(paper.abstract),
+		Language:           detectLanguage(paper.abstract, htmlLanguage(htmlContent)),
+	}, true
+}
+
+// checkTextLength は本文の長さを検証し、最大文字数を超える場合は切り詰める
+func (e *Extractor) checkTextLength(ctx context.Context, text, articleURL string) (string, error) {
 	textLength := len(text)
 	if textLength < e.minTextLength {
-		return "", "", errors.New(
+		return "", errors.New(
 			errors.ErrorTypeArticle,
 			fmt.Sprintf("記事本文が短すぎる: %d文字 (最小 %d文字)", textLength, e.minTextLength),
 		)
 	}
 
 	if textLength > e.maxTextLength {
-		logger.Warn("記事本文が長すぎるため切り詰める",
+		logging.FromContext(ctx).Warn("記事本文が長すぎるため切り詰める",
 			"url", articleURL,
 			"originalLength", textLength,
 			"maxLength", e.maxTextLength,
 		)
 		text = truncateUTF8(text, e.maxTextLength)
-		textLength = len(text)
+	}
+	return text, nil
+}
+
+// ExtractContent はHTMLから記事のタイトル・本文・構造の統計情報を抽出する
+// 本文はreadabilityが抽出したHTMLを軽量なMarkdownに変換したもの
+func (e *Extractor) ExtractContent(ctx context.Context, htmlContent, articleURL string) (*ExtractedContent, error) {
+	logger := logging.FromContext(ctx)
+	logger.Info("記事本文とタイトルを抽出中", "url", articleURL)
+
+	// URLを検証してパース
+	parsedURL, err := url.Parse(articleURL)
+	if err != nil {
+		return nil, errors.NewValidationError("記事URLのパースに失敗", err)
+	}
+
+	// go-readabilityで本文を抽出
+	article, err := readability.FromReader(strings.NewReader(htmlContent), parsedURL)
+	if err != nil {
+		return nil, errors.NewArticleError("記事本文の抽出に失敗", err)
+	}
+
+	// タイトルを取得
+	title := strings.TrimSpace(article.Title)
+
+	// 本文をMarkdownに変換（コードブロック・見出し・リストの構造を保持）
+	var text string
+	var stats config.ContentStats
+	if article.Node != nil {
+		text, stats = htmlToMarkdown(article.Node)
+	}
+	if text == "" {
+		// 変換できない場合はテキストコンテンツにフォールバック（連続する空白を1つに）
+		text = strings.Join(strings.Fields(article.TextContent), " ")
+	}
+
+	// 有料記事・会員限定記事は冒頭部分だけで評価しないよう、長さの検証より先に判定する
+	if signals := detectPaywall(htmlContent, text); len(signals) > 0 {
+		logger.Info("有料記事と判定しました", "url", articleURL, "signals", signals)
+		return nil, errors.NewArticleError(
+			fmt.Sprintf("有料記事のため本文を取得できません（%s）", strings.Join(signals, ", ")),
+			errors.ErrPaywalled,
+		)
+	}
+
+	// テキストの長さを検証
+	text, err = e.checkTextLength(ctx, text, articleURL)
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info("記事本文とタイトルの抽出に成功",
 		"url", articleURL,
 		"title", title,
-		"textLength", textLength,
+		"textLength", len(text),
+		"codeBlocks", stats.CodeBlocks,
+		"headings", stats.Headings,
+		"links", stats.Links,
 	)
 
-	return title, text, nil
+	content := &ExtractedContent{
+		Title:              title,
+		Text:               text,
+		Stats:              stats,
+		Excerpt:            strings.TrimSpace(article.Excerpt),
+		SiteName:           strings.TrimSpace(article.SiteName),
+		ReadingTimeMinutes: estimateReadingMinutes(text),
+		Language:           detectLanguage(text, htmlLanguage(htmlContent)),
+	}
+	applyMetadata(content, htmlContent, parsedURL, &article)
+
+	return content, nil
+}
+
+// FeedSummaryContent は有料記事について、フィードの要約を本文の代わりに使用する内容を返す
+// 要約が最小文字数に満たない場合は評価に使えないためnilを返す
+func (e *Extractor) FeedSummaryContent(summary string) *ExtractedContent {
+	summary = strings.TrimSpace(summary)
+	if len(summary) < e.minTextLength {
+		return nil
+	}
+	return &ExtractedContent{
+		Text:      truncateUTF8(summary, e.maxTextLength),
+		Excerpt:   summary,
+		Paywalled: true,
+		Language:  detectLanguage(summary, ""),
+	}
+}
+
+// applyMetadata はmetaタグとreadabilityの結果から著者・画像・公開日時を設定する
+// Open Graph等のmetaタグを優先し、存在しない場合はreadabilityの推定値を使用する
+func applyMetadata(content *ExtractedContent, htmlContent string, articleURL *url.URL, article *readability.Article) {
+	tags := metaTags(htmlContent)
+
+	content.ImageURL = resolveURL(articleURL, firstMeta(tags, "og:image", "og:image:url", "twitter:image"))
+	if content.ImageURL == "" {
+		content.ImageURL = resolveURL(articleURL, article.Image)
+	}
+
+	// article:authorはプロフィールURLの場合があるため、その場合はbylineを使用する
+	if author := firstMeta(tags, "article:author", "author"); author != "" && !isURL(author) {
+		content.Author = author
+	} else {
+		content.Author = strings.TrimSpace(article.Byline)
+	}
+
+	if content.SiteName == "" {
+		content.SiteName = firstMeta(tags, "og:site_name")
+	}
+
+	if publishedAt, ok := parseMetaTime(firstMeta(tags, "article:published_time", "og:published_time")); ok {
+		content.PublishedAt = publishedAt
+	} else if article.PublishedTime != nil {
+		content.PublishedAt = *article.PublishedTime
+	}
 }
 
 // truncateUTF8 はUTF-8文字列を安全に切り詰める
@@ -2,95 +2,283 @@ package article
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
-	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 
+	"github.com/kaka0913/discord-article-bot/internal/config"
 	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/httpcache"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
-)
-
-const (
-	// maxHTMLSize はHTML取得時の最大サイズ（10MB）
-	// 10MBを超えるHTMLは異常に大きいため拒否する
-	maxHTMLSize = 10 * 1024 * 1024
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
 )
 
 // Fetcher は記事のHTMLコンテンツの取得を担当する
+// robots.txtを遵守し、同じホストへのリクエストの間隔を制限する
 type Fetcher struct {
-	client *http.Client
+	client  *http.Client
+	robots  *robotsCache
+	hosts   *hostLimiter
+	maxSize int64 // 記事HTMLの上限サイズ（バイト）
 }
 
 // NewFetcher は新しいFetcherインスタンスを作成する
-func NewFetcher(timeout time.Duration) *Fetcher {
+// 内部ネットワークへのリクエストを防ぐため、SSRF対策を施したHTTPクライアントを使用する
+// maxSizeを超えるHTMLは読み取りの途中で中止する
+func NewFetcher(timeout time.Duration, maxSize int64) *Fetcher {
+	return newFetcher(safehttp.NewClient(safehttp.Options{Timeout: timeout}), maxSize)
+}
+
+// newFetcher は指定したHTTPクライアントを使用するFetcherを作成する
+func newFetcher(client *http.Client, maxSize int64) *Fetcher {
 	return &Fetcher{
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  client,
+		robots:  newRobotsCache(client),
+		hosts:   newHostLimiter(DefaultHostInterval),
+		maxSize: maxSize,
+	}
+}
+
+// SetCache はHTTPレスポンスのキャッシュを設定する（ローカル開発用）
+// 記事・robots.txt・サイトのAPIのレスポンスをキャッシュする。SSRF対策はキャッシュにないURLを取得する際に適用される
+func (f *Fetcher) SetCache(cache *httpcache.Cache) {
+	f.client.Transport = cache.Wrap(f.client.Transport)
+}
+
+// RejectionReason は記事の取得・抽出エラーに対応する却下理由を返します
+func RejectionReason(err error) string {
+	if stderrors.Is(err, errors.ErrRobotsDisallowed) {
+		return config.ReasonRobotsDisallowed
+	}
+	if stderrors.Is(err, errors.ErrBlockedDestination) {
+		return config.ReasonBlockedDestination
+	}
+	if stderrors.Is(err, errors.ErrPaywalled) {
+		return config.ReasonPaywalled
+	}
+	return config.ReasonContentExtractionFailed
+}
+
+// Document は取得した記事のコンテンツを表す
+// HTMLの場合はUTF-8に変換したHTMLを、PDFの場合はファイルの内容を保持する
+// URLはリダイレクトを追跡した後の最終URLで、フィードに記載された元のURLはOriginalURLに保持する
+type Document struct {
+	URL           string   // 記事の最終URL（リダイレクトがない場合は元のURLと同じ）
+	OriginalURL   string   // 取得を要求した元のURL（フィードに記載されたリンク）
+	RedirectChain []string // リダイレクトで経由したURL（元のURLを含み、最終URLは含まない。リダイレクトがない場合はnil）
+	ContentType   string   // レスポンスのContent-Type
+	HTML          string   // UTF-8に変換したHTML（PDFの場合は空文字列）
+	PDF           []byte   // PDFファイルの内容（HTMLの場合はnil）
+}
+
+// IsPDF はコンテンツがPDFかどうかを返す
+func (d *Document) IsPDF() bool {
+	return d.PDF != nil
+}
+
+// Redirected はリダイレクトにより元のURLと異なるURLに到達したかどうかを返す
+func (d *Document) Redirected() bool {
+	return d.URL != d.OriginalURL
+}
+
+// Fetch は指定されたURLから記事のHTMLコンテンツを取得し、HTMLとリダイレクト後の最終URLを返す
+// エラーが発生した場合（PDFなどHTML以外の場合を含む）はエラーを返す
+func (f *Fetcher) Fetch(ctx context.Context, url string) (html, finalURL string, err error) {
+	doc, err := f.FetchDocument(ctx, url)
+	if err != nil {
+		return "", "", err
+	}
+	if doc.IsPDF() {
+		return "", "", errors.New(errors.ErrorTypeArticle, fmt.Sprintf("記事がHTMLではなくPDF: %s", url))
 	}
+	return doc.HTML, doc.URL, nil
 }
 
-// Fetch は指定されたURLから記事のHTMLコンテンツを取得する
+// checkRobots は記事URLへのアクセスがrobots.txtで許可されているかを確認し、ホストごとのレート制限まで待機する
+// 禁止されている場合やCrawl-delayが長すぎる場合は、ErrRobotsDisallowedを含むエラーを返す
+func (f *Fetcher) checkRobots(ctx context.Context, u *neturl.URL) error {
+	logger := logging.FromContext(ctx)
+	rules, err := f.robots.rules(ctx, u)
+	if err != nil {
+		return errors.NewArticleError("robots.txtの確認に失敗", err)
+	}
+	if !rules.allowed(robotsPath(u)) {
+		logger.Info("robots.txtにより取得をスキップします", "url", u.String())
+		return errors.NewArticleError(fmt.Sprintf("記事HTMLの取得を中止: %s", u), errors.ErrRobotsDisallowed)
+	}
+	// Crawl-delayが長すぎるホストは、実行全体が待機で止まらないよう取得しない
+	if rules.crawlDelay > MaxCrawlDelay {
+		logger.Warn("robots.txtのCrawl-delayが長すぎるため取得をスキップします", "url", u.String(), "crawlDelay", rules.crawlDelay, "maxCrawlDelay", MaxCrawlDelay)
+		return errors.NewArticleError(fmt.Sprintf("Crawl-delay（%v）が上限（%v）を超えるため記事HTMLの取得を中止: %s", rules.crawlDelay, MaxCrawlDelay, u), errors.ErrRobotsDisallowed)
+	}
+
+	// 同じホストへのリクエストの間隔を制限（Crawl-delayが指定されている場合はそれに従う）
+	if err := f.hosts.wait(ctx, u.Host, rules.crawlDelay); err != nil {
+		return errors.NewArticleError("ホストごとのレート制限の待機に失敗", err)
+	}
+	return nil
+}
+
+// documentClient は記事の取得に使うHTTPクライアントを返す
+// リダイレクト先のURLごとにもrobots.txtとホストごとのレート制限を適用する（リダイレクタから別のホストに移る場合など）
+func (f *Fetcher) documentClient() *http.Client {
+	client := *f.client
+	checkRedirect := f.client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if checkRedirect != nil {
+			if err := checkRedirect(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			return fmt.Errorf("stopped after %d redirects", len(via))
+		}
+		return f.checkRobots(req.Context(), req.URL)
+	}
+	return &client
+}
+
+// redirectChain はレスポンスに至るまでに経由したURLを、元のURLから順に返す
+// 最終URL（resp.Request.URL）は含まない。リダイレクトがない場合はnilを返す
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for r := resp.Request; r.Response != nil && r.Response.Request != nil; r = r.Response.Request {
+		chain = append([]string{r.Response.Request.URL.String()}, chain...)
+	}
+	return chain
+}
+
+// FetchDocument は指定されたURLから記事のコンテンツ（HTMLまたはPDF）を取得する
 // エラーが発生した場合はエラーを返す
-func (f *Fetcher) Fetch(ctx context.Context, url string) (string, error) {
+func (f *Fetcher) FetchDocument(ctx context.Context, url string) (*Document, error) {
 	logger := logging.FromContext(ctx)
 	logger.Info("記事HTMLを取得中", "url", url)
 
 	// HTTPリクエストを作成
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", errors.NewValidationError("HTTPリクエストの作成に失敗", err)
+		return nil, errors.NewValidationError("HTTPリクエストの作成に失敗", err)
+	}
+
+	// robots.txtで禁止されている場合は取得しない（同じホストへのリクエストの間隔も制限する）
+	if err := f.checkRobots(ctx, req.URL); err != nil {
+		return nil, err
 	}
 
 	// User-Agentヘッダーを設定（一部のサイトではUser-Agentが必要）
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; discord-article-bot/1.0)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,application/pdf;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "ja,en-US;q=0.9,en;q=0.8")
 
 	// HTTPリクエストを実行
-	resp, err := f.client.Do(req)
+	resp, err := f.documentClient().Do(req)
 	if err != nil {
-		return "", errors.NewArticleError("記事HTMLの取得に失敗", err)
+		if safehttp.IsBlocked(err) {
+			logger.Warn("セキュリティ上の理由で記事HTMLの取得を拒否しました", "url", url, "rejection", "security", "error", err)
+		}
+		return nil, errors.NewArticleError("記事HTMLの取得に失敗", err)
 	}
 	defer resp.Body.Close()
 
 	// ステータスコードを確認
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(
+		return nil, errors.New(
 			errors.ErrorTypeArticle,
 			fmt.Sprintf("記事HTMLの取得に失敗: HTTPステータス %d", resp.StatusCode),
 		)
 	}
 
-	// Content-Typeがtext/htmlかどうかを確認（警告のみ）
+	// リダイレクト（フィードのリダイレクタ・短縮URLなど）を追跡した後の最終URLを記事のURLとする
+	finalURL := resp.Request.URL.String()
+	chain := redirectChain(resp)
+	if len(chain) > 0 {
+		logger.Info("リダイレクトを追跡しました", "url", url, "finalURL", finalURL, "redirects", len(chain))
+	}
+
+	// リダイレクトでログインページに到達した場合は、ログインが必要な記事として扱う
+	if isLoginRedirect(req.URL, resp.Request.URL) {
+		logger.Info("ログインページへリダイレクトされました", "url", url, "finalURL", finalURL)
+		return nil, errors.NewArticleError(fmt.Sprintf("ログインが必要な記事（%s）: %s", signalLoginRedirect, url), errors.ErrPaywalled)
+	}
+
 	contentType := resp.Header.Get("Content-Type")
+
+	// レスポンスボディを上限サイズまで読み取り（異常に大きいHTMLは途中で中止する）
+	body, err := safehttp.ReadBody(resp, f.maxSize)
+	if err != nil {
+		if safehttp.IsTooLarge(err) {
+			return nil, errors.NewArticleError(fmt.Sprintf("記事HTMLが大きすぎる: %s", url), err)
+		}
+		return nil, errors.NewArticleError("レスポンスボディの読み取りに失敗", err)
+	}
+
+	// HTMLコンテンツのサイズを検証
+	if len(body) == 0 {
+		return nil, errors.New(errors.ErrorTypeArticle, "記事HTMLが空")
+	}
+
+	// PDF（論文など）はテキスト抽出時に解析するため、そのまま返す
+	if isPDF(contentType, body) {
+		logger.Info("記事PDFの取得に成功", "url", url, "size", len(body))
+		return &Document{URL: finalURL, OriginalURL: url, RedirectChain: chain, ContentType: contentType, PDF: body}, nil
+	}
+
+	// Content-Typeがtext/htmlかどうかを確認（警告のみ）
 	if contentType != "" && !containsHTML(contentType) {
 		logger.Warn("Content-Typeがtext/htmlではない", "url", url, "contentType", contentType)
 	}
 
-	// レスポンスボディを読み取り
-	body, err := io.ReadAll(resp.Body)
+	// 文字コードを判定してUTF-8に変換（Shift_JIS・EUC-JPのページに対応）
+	htmlContent, detectedCharset, err := decodeToUTF8(body, contentType)
 	if err != nil {
-		return "", errors.NewArticleError("レスポンスボディの読み取りに失敗", err)
+		return nil, errors.NewArticleError("記事HTMLの文字コード変換に失敗", err)
 	}
 
-	// HTMLコンテンツのサイズを検証
-	if len(body) == 0 {
-		return "", errors.New(errors.ErrorTypeArticle, "記事HTMLが空")
+	logger.Info("記事HTMLの取得に成功", "url", url, "size", len(body), "charset", detectedCharset)
+	return &Document{URL: finalURL, OriginalURL: url, RedirectChain: chain, ContentType: contentType, HTML: htmlContent}, nil
+}
+
+// FetchJSON はサイトが公開しているAPIからJSONを取得し、vにデコードする
+// サイト固有の抽出で使用する。ホストごとのレート制限とSSRF対策・サイズ制限は記事の取得と共通
+// 公開APIはプログラムからの利用を前提としているため、robots.txtは確認しない
+func (f *Fetcher) FetchJSON(ctx context.Context, apiURL string, v any) error {
+	logger := logging.FromContext(ctx)
+	logger.Debug("APIから記事情報を取得中", "url", apiURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return errors.NewValidationError("HTTPリクエストの作成に失敗", err)
+	}
+	if err := f.hosts.wait(ctx, req.URL.Host, 0); err != nil {
+		return errors.NewArticleError("ホストごとのレート制限の待機に失敗", err)
 	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "application/json")
 
-	// 最大サイズを制限
-	if len(body) > maxHTMLSize {
-		return "", errors.New(
-			errors.ErrorTypeArticle,
-			fmt.Sprintf("記事HTMLが大きすぎる: %d bytes (最大 %d bytes)", len(body), maxHTMLSize),
-		)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if safehttp.IsBlocked(err) {
+			logger.Warn("セキュリティ上の理由でAPIの呼び出しを拒否しました", "url", apiURL, "rejection", "security", "error", err)
+		}
+		return errors.NewArticleError("APIの呼び出しに失敗", err)
 	}
+	defer resp.Body.Close()
 
-	logger.Info("記事HTMLの取得に成功", "url", url, "size", len(body))
-	return string(body), nil
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(errors.ErrorTypeArticle, fmt.Sprintf("APIの呼び出しに失敗: HTTPステータス %d", resp.StatusCode))
+	}
+
+	body, err := safehttp.ReadBody(resp, f.maxSize)
+	if err != nil {
+		return errors.NewArticleError("APIレスポンスの読み取りに失敗", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.NewArticleError("APIレスポンスのパースに失敗", err)
+	}
+	return nil
 }
 
 // containsHTML はContent-Typeヘッダーにtext/htmlが含まれているかを確認する
@@ -0,0 +1,111 @@
+package article
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
+)
+
+func TestFetcher_Fetch_BlockedDestination(t *testing.T) {
+	var requested atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(true)
+		w.Write([]byte("<html><body><p>内部サービス</p></body></html>"))
+	}))
+	defer server.Close()
+
+	// 本番用のFetcherはループバックアドレス（httptestのサーバー）に接続しない
+	_, _, err := NewFetcher(5*time.Second, testMaxHTMLSize).Fetch(context.Background(), server.URL+"/admin")
+	if err == nil {
+		t.Fatal("ループバックアドレスの取得がエラーになりませんでした")
+	}
+	if reason := RejectionReason(err); reason != config.ReasonBlockedDestination {
+		t.Errorf("却下理由 = %s, 期待 %s", reason, config.ReasonBlockedDestination)
+	}
+	if requested.Load() {
+		t.Error("拒否した宛先にリクエストが送信されました")
+	}
+}
+
+func TestFetcher_Fetch_TooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// Content-Lengthを送らずに上限を超えるHTMLを返し続ける
+		w.(http.Flusher).Flush()
+		chunk := []byte(strings.Repeat("<p>大きな記事</p>", 1024))
+		for i := 0; i < 1024; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	_, _, err := newTestFetcher().Fetch(context.Background(), server.URL)
+	if !stderrors.Is(err, errors.ErrResponseTooLarge) {
+		t.Errorf("上限を超えるHTMLでErrResponseTooLargeが返りませんでした: %v", err)
+	}
+}
+
+func TestFetcher_FetchDocument_Redirects(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/~r/feed/abc":
+			http.Redirect(w, r, server.URL+"/s/x1", http.StatusMovedPermanently)
+		case "/s/x1":
+			http.Redirect(w, r, "/posts/real-article", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><body><p>記事</p></body></html>"))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	fetcher := newTestFetcher()
+	fetcher.hosts = newHostLimiter(0) // リダイレクトごとのホストの待機を省略する
+	doc, err := fetcher.FetchDocument(ctx, server.URL+"/~r/feed/abc")
+	if err != nil {
+		t.Fatalf("FetchDocument failed: %v", err)
+	}
+
+	if doc.URL != server.URL+"/posts/real-article" {
+		t.Errorf("URL = %q, 最終URLではありません", doc.URL)
+	}
+	if doc.OriginalURL != server.URL+"/~r/feed/abc" || !doc.Redirected() {
+		t.Errorf("OriginalURL = %q, Redirected = %v", doc.OriginalURL, doc.Redirected())
+	}
+	wantChain := []string{server.URL + "/~r/feed/abc", server.URL + "/s/x1"}
+	if strings.Join(doc.RedirectChain, " ") != strings.Join(wantChain, " ") {
+		t.Errorf("RedirectChain = %v, want %v", doc.RedirectChain, wantChain)
+	}
+
+	_, finalURL, err := newTestFetcher().Fetch(ctx, server.URL+"/posts/real-article")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if finalURL != server.URL+"/posts/real-article" {
+		t.Errorf("リダイレクトがない場合の最終URL = %q", finalURL)
+	}
+}
+
+// testMaxHTMLSize はテスト用Fetcherの記事HTMLの上限サイズ
+const testMaxHTMLSize = 1024 * 1024
+
+// newTestFetcher はhttptestのサーバー（ループバックアドレス）に接続できるテスト用のFetcherを作成する
+func newTestFetcher() *Fetcher {
+	return newFetcher(safehttp.NewClient(safehttp.Options{
+		Timeout:              5 * time.Second,
+		AllowPrivateNetworks: true,
+	}), testMaxHTMLSize)
+}
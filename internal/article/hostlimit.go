@@ -0,0 +1,54 @@
+package article
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultHostInterval は同じホストへのリクエストの最小間隔
+	// robots.txtのCrawl-delayがこれより長い場合はCrawl-delayを使用する
+	DefaultHostInterval = 1 * time.Second
+
+	// MaxCrawlDelay はrobots.txtのCrawl-delayとして従う最大の間隔
+	// 実行は記事を順に取得するため、これより長いCrawl-delayのホストの記事は取得しない
+	MaxCrawlDelay = 60 * time.Second
+)
+
+// hostLimiter はホストごとのレート制限を管理する
+// 記事を並行して取得する場合でも、同じホストに短時間でリクエストが集中しないようにする
+type hostLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// newHostLimiter は新しいホストごとのレート制限を作成する
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval: interval,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// wait はホストへのリクエストが許可されるまで待機する
+// crawlDelayが最小間隔より長い場合はcrawlDelayの間隔（MaxCrawlDelayまで）で制限する
+func (h *hostLimiter) wait(ctx context.Context, host string, crawlDelay time.Duration) error {
+	interval := max(h.interval, min(crawlDelay, MaxCrawlDelay))
+
+	h.mu.Lock()
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(interval), 1)
+		h.limiters[host] = limiter
+	} else if limiter.Limit() != rate.Every(interval) {
+		// robots.txtの再取得でCrawl-delayが変わった場合に追従する
+		limiter.SetLimit(rate.Every(interval))
+	}
+	h.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
@@ -0,0 +1,106 @@
+package article
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// languageSampleRunes は言語の判定に使用する本文の先頭からの文字数
+	languageSampleRunes = 5000
+
+	// minLanguageLetters は言語を判定するのに必要な最小の文字（記号・数字を除く）数
+	minLanguageLetters = 20
+)
+
+// htmlLangPattern は<html lang="...">の言語タグにマッチする
+var htmlLangPattern = regexp.MustCompile(`(?i)<html[^>]*\slang\s*=\s*["']?([a-zA-Z]{2,3})(?:[-_][a-zA-Z0-9]+)*`)
+
+// latinScriptLanguages はラテン文字で書かれる言語（本文の文字からは区別できないため、宣言された言語を使う）
+var latinScriptLanguages = map[string]bool{
+	"en": true, "de": true, "fr": true, "es": true, "pt": true, "it": true, "nl": true,
+	"sv": true, "no": true, "da": true, "fi": true, "pl": true, "cs": true, "tr": true,
+	"id": true, "vi": true,
+}
+
+// htmlLanguage はHTMLのlang属性から言語コード（ISO 639-1の小文字）を返す（指定がない場合は空文字列）
+func htmlLanguage(htmlContent string) string {
+	m := htmlLangPattern.FindStringSubmatch(htmlContent)
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[1])
+}
+
+// detectLanguage は本文に使われている文字の種類から記事の言語（ISO 639-1）を判定する
+// 日本語（かな）・韓国語・中国語・ロシア語などは文字の種類で判定し、ラテン文字の場合はdeclared（HTMLのlang属性）が
+// ラテン文字の言語であればそれを、そうでなければ英語とする。判定できない場合はdeclaredをそのまま返す
+// コードブロックは本文の言語と関係なく英字が多いため除外する
+func detectLanguage(text, declared string) string {
+	var kana, han, hangul, cyrillic, latin, letters int
+	sampled := 0
+	for _, r := range stripCodeBlocks(text) {
+		if sampled >= languageSampleRunes {
+			break
+		}
+		sampled++
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	if letters < minLanguageLetters {
+		return declared
+	}
+	switch {
+	// 日本語の技術記事は英単語やコードが多いため、かなが5%以上あれば日本語とする
+	case kana*20 >= letters:
+		return "ja"
+	case hangul*5 >= letters:
+		return "ko"
+	case han*5 >= letters:
+		return "zh"
+	case cyrillic*5 >= letters:
+		return "ru"
+	case latin*2 >= letters:
+		if latinScriptLanguages[declared] {
+			return declared
+		}
+		return "en"
+	}
+	return declared
+}
+
+// stripCodeBlocks はMarkdownのコードブロック（```で囲まれた部分）を除いた本文を返す
+func stripCodeBlocks(text string) string {
+	if !strings.Contains(text, "```") {
+		return text
+	}
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package article
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		declared string
+		want     string
+	}{
+		{
+			name: "日本語（英単語・コードを含む）",
+			text: "GoのgoroutineとchannelでWorker Poolを実装する方法を解説します。\n\n" +
+				"```go\nfunc worker(jobs <-chan Job, results chan<- Result) {\n\tfor job := range jobs {\n\t\tresults <- process(job)\n\t}\n}\n```\n\n" +
+				"context.Contextでキャンセルを伝播させるのがポイントです。",
+			want: "ja",
+		},
+		{
+			name: "英語",
+			text: "This article explains how to implement a worker pool with goroutines and channels in Go.",
+			want: "en",
+		},
+		{
+			name:     "ラテン文字の言語は宣言された言語を使う",
+			text:     "Dieser Artikel erklärt, wie man einen Worker-Pool mit Goroutinen und Channels in Go implementiert.",
+			declared: "de",
+			want:     "de",
+		},
+		{
+			name:     "宣言がラテン文字の言語でなければ英語",
+			text:     "This article explains how to implement a worker pool with goroutines and channels in Go.",
+			declared: "ja",
+			want:     "en",
+		},
+		{
+			name: "韓国語",
+			text: "이 글에서는 Go의 고루틴과 채널을 사용하여 워커 풀을 구현하는 방법을 설명합니다.",
+			want: "ko",
+		},
+		{
+			name: "中国語",
+			text: "本文介绍如何在Go语言中使用协程和通道实现工作池，并说明取消处理的要点。",
+			want: "zh",
+		},
+		{
+			name:     "短すぎる場合は宣言された言語を使う",
+			text:     "Go 1.22",
+			declared: "en",
+			want:     "en",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectLanguage(tt.text, tt.declared); got != tt.want {
+				t.Errorf("detectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLLanguage(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{`<!DOCTYPE html><html lang="ja"><head></head></html>`, "ja"},
+		{`<html class="no-js" lang="en-US">`, "en"},
+		{`<HTML LANG=de>`, "de"},
+		{`<html><body lang="fr"></body></html>`, ""},
+	}
+	for _, tt := range tests {
+		if got := htmlLanguage(tt.html); got != tt.want {
+			t.Errorf("htmlLanguage(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestExtractDocument_Language(t *testing.T) {
+	html := `<html lang="en"><head><title>Worker Pool</title></head><body><article><h1>Worker Pool</h1><p>` +
+		strings.Repeat("This article explains how to implement a worker pool in Go. ", 10) + `</p></article></body></html>`
+	content, err := NewExtractor(50, 100000).ExtractDocument(context.Background(), &Document{URL: "https://example.com/posts/1", HTML: html})
+	if err != nil {
+		t.Fatalf("ExtractDocument failed: %v", err)
+	}
+	if content.Language != "en" {
+		t.Errorf("Language = %q, want %q", content.Language, "en")
+	}
+}
@@ -0,0 +1,373 @@
+package article
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// excessiveNewlines は3つ以上連続する改行（空行の連続）にマッチする
+var excessiveNewlines = regexp.MustCompile(`\n{3,}`)
+
+// markdownConverter はreadabilityが抽出したHTMLを軽量なMarkdownに変換する
+// コードブロック・見出し・リストの構造を保持し、LLMがコード例の有無を判断できるようにする
+// リンクはトークン数を抑えるためテキストのみを出力し、件数のみ数える
+type markdownConverter struct {
+	stats config.ContentStats
+}
+
+// htmlToMarkdown はHTMLノードをMarkdownに変換し、構造の統計情報とともに返す
+func htmlToMarkdown(node *html.Node) (string, config.ContentStats) {
+	c := &markdownConverter{}
+	text := c.blocks(node)
+	text = excessiveNewlines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text), c.stats
+}
+
+// blocks は子ノードをブロック単位で変換し、空行区切りで連結する
+func (c *markdownConverter) blocks(node *html.Node) string {
+	return c.joinBlocks(node, "\n\n")
+}
+
+// joinBlocks は子ノードをブロック単位で変換し、sepで連結する
+func (c *markdownConverter) joinBlocks(node *html.Node, sep string) string {
+	var parts []string
+	var inline strings.Builder
+
+	flush := func() {
+		if text := normalizeInline(inline.String()); text != "" {
+			parts = append(parts, text)
+		}
+		inline.Reset()
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && (isBlockElement(child.DataAtom) || hasBlockDescendant(child)) {
+			flush()
+			if block := c.block(child); block != "" {
+				parts = append(parts, block)
+			}
+			continue
+		}
+		inline.WriteString(c.inline(child))
+	}
+	flush()
+
+	return strings.Join(parts, sep)
+}
+
+// block はブロック要素を変換する
+func (c *markdownConverter) block(node *html.Node) string {
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := normalizeInline(c.inlineChildren(node))
+		if text == "" {
+			return ""
+		}
+		c.stats.Headings++
+		level := int(node.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\n", " ")
+	case atom.Pre:
+		return c.codeBlock(node)
+	case atom.Ul, atom.Ol:
+		return c.list(node)
+	case atom.Blockquote:
+		return prefixLines(c.blocks(node), "> ", "> ")
+	case atom.Table:
+		return c.table(node)
+	case atom.Hr:
+		return "---"
+	case atom.Script, atom.Style, atom.Noscript, atom.Template:
+		return ""
+	default:
+		return c.blocks(node)
+	}
+}
+
+// codeBlock はpre要素をフェンス付きコードブロックに変換する
+func (c *markdownConverter) codeBlock(node *html.Node) string {
+	code := strings.Trim(textContent(node), "\n")
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+	c.stats.CodeBlocks++
+
+	// コード内のバッククォートの連続より長いフェンスを使用する
+	fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+	return fence + codeLanguage(node) + "\n" + code + "\n" + fence
+}
+
+// list はul/ol要素をリストに変換する（ネストしたリストはインデントする）
+func (c *markdownConverter) list(node *html.Node) string {
+	var items []string
+	index := 1
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if node.DataAtom == atom.Ol {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+
+		// リスト項目内の段落やネストしたリストは空行を挟まずに連結する
+		content := c.joinBlocks(child, "\n")
+		if content == "" {
+			continue
+		}
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table はtable要素を行ごとにセルを「 | 」で区切ったテキストに変換する
+func (c *markdownConverter) table(node *html.Node) string {
+	var rows []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.DataAtom != atom.Tr {
+				walk(child)
+				continue
+			}
+
+			var cells []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+					cells = append(cells, strings.ReplaceAll(normalizeInline(c.inlineChildren(cell)), "\n", " "))
+				}
+			}
+			if len(cells) > 0 {
+				rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+			}
+		}
+	}
+	walk(node)
+	return strings.Join(rows, "\n")
+}
+
+// inline はインライン要素・テキストノードを変換する
+func (c *markdownConverter) inline(node *html.Node) string {
+	switch node.Type {
+	case html.TextNode:
+		// ソース上の改行は空白として扱い、改行はbr要素のみで表現する
+		return strings.ReplaceAll(node.Data, "\n", " ")
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch node.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Code, atom.Kbd, atom.Samp:
+		code := strings.Join(strings.Fields(textContent(node)), " ")
+		if code == "" {
+			return ""
+		}
+		return "`" + code + "`"
+	case atom.A:
+		if href := attr(node, "href"); href != "" && !strings.HasPrefix(href, "#") {
+			c.stats.Links++
+		}
+		return c.inlineChildren(node)
+	case atom.Img, atom.Script, atom.Style, atom.Noscript, atom.Template:
+		return ""
+	default:
+		// ブロック要素がインライン要素内にある場合もテキストとして扱う
+		if isBlockElement(node.DataAtom) {
+			return "\n" + c.block(node) + "\n"
+		}
+		return c.inlineChildren(node)
+	}
+}
+
+// inlineChildren は子ノードをインラインとして変換して連結する
+func (c *markdownConverter) inlineChildren(node *html.Node) string {
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+// isBlockElement はMarkdownでブロックとして扱う要素かどうかを返す
+func isBlockElement(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer,
+		atom.Aside, atom.Nav, atom.Figure, atom.Figcaption, atom.Blockquote, atom.Pre,
+		atom.Ul, atom.Ol, atom.Li, atom.Dl, atom.Dt, atom.Dd, atom.Table, atom.Hr,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Details, atom.Summary, atom.Form,
+		atom.Script, atom.Style, atom.Noscript, atom.Template:
+		return true
+	}
+	return false
+}
+
+// hasBlockDescendant はインライン要素などの配下にブロック要素が含まれるかを返す
+// （ブロック要素を含む要素はブロックのコンテナとして扱う）
+func hasBlockDescendant(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if isBlockElement(child.DataAtom) || hasBlockDescendant(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeInline は改行（br）を保持しつつ、行内の連続する空白を1つにまとめる
+func normalizeInline(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// prefixLines は1行目にfirst、2行目以降にrestを付与する（空行には付与しない）
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" && i > 0 {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeLanguage はpre要素または内側のcode要素のclass属性からコードの言語を取得する
+// （"language-go" や "lang-go" の形式に対応）
+func codeLanguage(pre *html.Node) string {
+	candidates := []*html.Node{pre}
+	for child := pre.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Code {
+			candidates = append(candidates, child)
+		}
+	}
+
+	for _, node := range candidates {
+		for _, class := range strings.Fields(attr(node, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// textContent はノード配下のテキストをそのまま連結する（空白は保持する）
+func textContent(node *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			b.WriteString("\n")
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return b.String()
+}
+
+// attr は要素の属性値を返す（存在しない場合は空文字列）
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// longestRun は文字列内で指定した文字が連続する最大の長さを返す
+func longestRun(s string, r rune) int {
+	longest, current := 0, 0
+	for _, ch := range s {
+		if ch == r {
+			current++
+			longest = max(longest, current)
+			continue
+		}
+		current = 0
+	}
+	return longest
+}
+
+// markdownHeadingPattern はMarkdownのATX見出し行にマッチする
+var markdownHeadingPattern = regexp.MustCompile(`(?m)^#{1,6}\s`)
+
+// markdownLinkPattern はMarkdownのリンク（画像を除く）にマッチする
+var markdownLinkPattern = regexp.MustCompile(`(?:^|[^!])\[[^\]]*\]\(([^)\s]+)`)
+
+// htmlFragmentToMarkdown はAPIなどから取得した記事本文のHTML断片をMarkdownに変換する
+func htmlFragmentToMarkdown(fragment string) (string, config.ContentStats, error) {
+	node, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return "", config.ContentStats{}, err
+	}
+	text, stats := htmlToMarkdown(node)
+	return text, stats, nil
+}
+
+// markdownStats はAPIから取得したMarkdown本文の構造の統計情報を数える
+// コードブロック内の「#」やリンク記法は数えない
+func markdownStats(markdown string) config.ContentStats {
+	var stats config.ContentStats
+	var prose strings.Builder
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			if !inCode {
+				stats.CodeBlocks++
+			}
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			prose.WriteString(line)
+			prose.WriteByte('\n')
+		}
+	}
+
+	text := prose.String()
+	stats.Headings = len(markdownHeadingPattern.FindAllString(text, -1))
+	for _, m := range markdownLinkPattern.FindAllStringSubmatch(text, -1) {
+		if !strings.HasPrefix(m[1], "#") {
+			stats.Links++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,98 @@
+package article
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	input := `<div>
+<h2>Goでのエラー処理</h2>
+<p>Goでは   エラーを<code>error</code>型で
+返します。詳しくは<a href="https://go.dev/blog/errors">公式ブログ</a>を参照してください。</p>
+<pre><code class="language-go">if err != nil {
+    return fmt.Errorf("failed: %w", err)
+}
+</code></pre>
+<h3>ポイント</h3>
+<ul>
+  <li>エラーは<strong>ラップ</strong>する</li>
+  <li>センチネルエラー
+    <ol><li>errors.Is</li><li>errors.As</li></ol>
+  </li>
+</ul>
+<blockquote><p>Errors are values.</p></blockquote>
+<p><a href="#top">トップへ</a></p>
+</div>`
+
+	doc, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("html.Parse failed: %v", err)
+	}
+
+	text, stats := htmlToMarkdown(doc)
+
+	want := "## Goでのエラー処理\n\n" +
+		"Goでは エラーを`error`型で 返します。詳しくは公式ブログを参照してください。\n\n" +
+		"```go\nif err != nil {\n    return fmt.Errorf(\"failed: %w\", err)\n}\n```\n\n" +
+		"### ポイント\n\n" +
+		"- エラーはラップする\n" +
+		"- センチネルエラー\n" +
+		"  1. errors.Is\n" +
+		"  2. errors.As\n\n" +
+		"> Errors are values.\n\n" +
+		"トップへ"
+	if text != want {
+		t.Errorf("変換結果が不正:\n--- 期待 ---\n%s\n--- 実際 ---\n%s", want, text)
+	}
+
+	if stats.CodeBlocks != 1 || stats.Headings != 2 || stats.Links != 1 {
+		t.Errorf("統計情報が不正: %+v", stats)
+	}
+}
+
+func TestHTMLToMarkdown_FenceLongerThanBackticks(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader("<pre>```\nnested\n```</pre>"))
+	if err != nil {
+		t.Fatalf("html.Parse failed: %v", err)
+	}
+
+	text, _ := htmlToMarkdown(doc)
+	if !strings.HasPrefix(text, "````\n") || !strings.HasSuffix(text, "\n````") {
+		t.Errorf("コード内のバッククォートより長いフェンスが使用されていません:\n%s", text)
+	}
+}
+
+func TestExtractor_ExtractContent(t *testing.T) {
+	paragraph := strings.Repeat("Goのエラー処理について実装例とともに詳しく解説します。", 10)
+	input := `<html><head><title>Goのエラー処理入門</title></head><body>
+<article>
+<h1>Goのエラー処理入門</h1>
+<p>` + paragraph + `</p>
+<h2>実装例</h2>
+<pre><code>func main() {
+	fmt.Println("hello")
+}</code></pre>
+<p>` + paragraph + `</p>
+</article>
+</body></html>`
+
+	extractor := NewExtractor(100, 100000)
+	content, err := extractor.ExtractContent(context.Background(), input, "https://example.com/go-errors")
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+
+	if content.Title != "Goのエラー処理入門" {
+		t.Errorf("タイトルが不正: %q", content.Title)
+	}
+	if !strings.Contains(content.Text, "```\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n```") {
+		t.Errorf("コードブロックが保持されていません:\n%s", content.Text)
+	}
+	if content.Stats.CodeBlocks != 1 {
+		t.Errorf("コードブロック数が不正: %+v", content.Stats)
+	}
+}
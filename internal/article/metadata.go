@@ -0,0 +1,134 @@
+package article
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// japaneseCharsPerMinute は日本語（CJK文字）を1分間に読める文字数の目安
+	japaneseCharsPerMinute = 500
+	// englishWordsPerMinute は英語を1分間に読める単語数の目安
+	englishWordsPerMinute = 200
+)
+
+// metaTags はHTMLのhead内のmetaタグ（property/name → content）を収集する
+// 同じキーが複数ある場合（citation_authorなど）は出現順にすべての値を保持する
+func metaTags(htmlContent string) map[string][]string {
+	tags := make(map[string][]string)
+	z := html.NewTokenizer(strings.NewReader(htmlContent))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return tags
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				// metaタグはhead内にあるため、body以降は走査しない
+				return tags
+			case atom.Meta:
+			default:
+				continue
+			}
+			if !hasAttr {
+				continue
+			}
+
+			var key, content string
+			for {
+				k, v, more := z.TagAttr()
+				switch string(k) {
+				case "property", "name":
+					if key == "" {
+						key = strings.ToLower(strings.TrimSpace(string(v)))
+					}
+				case "content":
+					content = strings.TrimSpace(string(v))
+				}
+				if !more {
+					break
+				}
+			}
+			if key != "" && content != "" {
+				tags[key] = append(tags[key], content)
+			}
+		}
+	}
+}
+
+// firstMeta は指定したキーのうち最初に見つかったmetaタグの値を返す
+func firstMeta(tags map[string][]string, keys ...string) string {
+	for _, key := range keys {
+		if values := tags[key]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// parseMetaTime はmetaタグの日時（ISO 8601）をパースする
+func parseMetaTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// resolveURL は記事URLを基準に相対URLを絶対URLに変換する（変換できない場合は空文字列）
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(u)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// isURL は値がURL形式かどうかを返す
+// article:authorにはプロフィールURLが入ることがあるため、著者名として使用しない判定に使う
+func isURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+// estimateReadingMinutes は本文から読了時間（分）を推定する
+// 日本語（CJK文字）と英語（単語）をそれぞれの速度で換算して合計し、最低1分とする
+func estimateReadingMinutes(text string) int {
+	cjkChars := 0
+	words := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			cjkChars++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+
+	minutes := float64(cjkChars)/japaneseCharsPerMinute + float64(words)/englishWordsPerMinute
+	return max(1, int(math.Ceil(minutes)))
+}
@@ -0,0 +1,69 @@
+package article
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractor_ExtractContent_Metadata(t *testing.T) {
+	paragraph := strings.Repeat("Goのエラー処理について実装例とともに詳しく解説します。", 10)
+	input := `<html><head>
+<title>Goのエラー処理入門</title>
+<meta property="og:site_name" content="Example Blog">
+<meta property="og:image" content="/images/og.png">
+<meta property="article:author" content="山田太郎">
+<meta property="article:published_time" content="2025-10-27T09:00:00+09:00">
+</head><body>
+<article>
+<h1>Goのエラー処理入門</h1>
+<p>` + paragraph + `</p>
+<p>` + paragraph + `</p>
+</article>
+</body></html>`
+
+	extractor := NewExtractor(100, 100000)
+	content, err := extractor.ExtractContent(context.Background(), input, "https://example.com/posts/go-errors")
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+
+	if content.ImageURL != "https://example.com/images/og.png" {
+		t.Errorf("画像URLが絶対URLに変換されていません: %q", content.ImageURL)
+	}
+	if content.Author != "山田太郎" {
+		t.Errorf("著者が不正: %q", content.Author)
+	}
+	if content.SiteName != "Example Blog" {
+		t.Errorf("サイト名が不正: %q", content.SiteName)
+	}
+	want := time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC)
+	if !content.PublishedAt.Equal(want) {
+		t.Errorf("公開日時が不正: %v", content.PublishedAt)
+	}
+	if content.ReadingTimeMinutes != 2 {
+		t.Errorf("読了時間が不正: %d", content.ReadingTimeMinutes)
+	}
+}
+
+func TestEstimateReadingMinutes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"空文字列は最低1分", "", 1},
+		{"日本語1500文字は3分", strings.Repeat("あ", 1500), 3},
+		{"英語1000語は5分", strings.Repeat("word ", 1000), 5},
+		{"日英混在は合算して切り上げ", strings.Repeat("あ", 500) + strings.Repeat(" word", 100), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateReadingMinutes(tt.text); got != tt.want {
+				t.Errorf("estimateReadingMinutes() = %d, 期待 %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package article
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// teaserMaxChars は有料記事の冒頭部分（ティーザー）とみなす本文の最大文字数
+	// 有料記事のマークアップや「続きを読む」の文言は無料記事にも含まれることがあるため、
+	// 本文がこれより短い場合のみ有料記事と判定する
+	teaserMaxChars = 2000
+
+	// truncationMarkerWindow は本文の末尾から「続きを読む」等の文言を探す文字数
+	truncationMarkerWindow = 300
+)
+
+// 有料記事・ログイン必須の記事と判定した根拠の名前（ログ・エラーメッセージ用）
+const (
+	signalNotFree       = "isAccessibleForFree=false"
+	signalPaywallMarkup = "paywall_markup"
+	signalTruncation    = "truncation_marker"
+	signalLoginRedirect = "login_redirect"
+)
+
+// notFreePattern はJSON-LDの"isAccessibleForFree": false（文字列の"False"を含む）にマッチする
+var notFreePattern = regexp.MustCompile(`(?i)"isAccessibleForFree"\s*:\s*"?false"?`)
+
+// paywallMarkupPattern は有料記事の境界に使われる既知のclass/id属性にマッチする
+// Medium（meteredContent）、note（paid-area）、日経（paywall）などの主要サイトで使われるもの
+var paywallMarkupPattern = regexp.MustCompile(`(?i)(?:class|id)\s*=\s*["'][^"']*\b(?:paywall|paywalled|regwall|meteredContent|member-only|members-only|subscriber-only|premium-content|paid-content|paid-area|o-paywall)\b`)
+
+// truncationMarkers は有料記事の冒頭部分の末尾に表示される文言
+var truncationMarkers = []string{
+	"この続きをみるには",
+	"この続きを見るには",
+	"続きを読むには",
+	"ここから先は",
+	"有料会員限定",
+	"会員限定記事",
+	"会員登録すると続きを",
+	"ログインして続きを",
+	"member-only story",
+	"continue reading with a",
+	"subscribe to continue reading",
+	"sign in to continue reading",
+	"subscribe to read the full",
+	"this article is for subscribers",
+}
+
+// loginPathPattern はログイン・会員登録ページのパスにマッチする
+var loginPathPattern = regexp.MustCompile(`(?i)(?:^|/)(?:login|log-in|signin|sign-in|sign_in|signup|sign-up|register|auth|sso)(?:/|\.|$)`)
+
+// detectPaywall は記事のHTMLと抽出した本文から、有料記事・ログイン必須の記事かを判定し、根拠を返す
+// JSON-LDのisAccessibleForFree=falseは確実な根拠として常に有料記事と判定し、
+// マークアップや文言は本文が冒頭部分（ティーザー）程度の長さしかない場合のみ根拠とする
+func detectPaywall(htmlContent, text string) []string {
+	var signals []string
+	if notFreePattern.MatchString(htmlContent) {
+		signals = append(signals, signalNotFree)
+	}
+
+	if utf8.RuneCountInString(text) > teaserMaxChars {
+		return signals
+	}
+
+	if paywallMarkupPattern.MatchString(htmlContent) {
+		signals = append(signals, signalPaywallMarkup)
+	}
+	if hasTruncationMarker(text) {
+		signals = append(signals, signalTruncation)
+	}
+	return signals
+}
+
+// hasTruncationMarker は本文の末尾に有料部分への誘導文言があるかを返す
+func hasTruncationMarker(text string) bool {
+	runes := []rune(text)
+	if len(runes) > truncationMarkerWindow {
+		runes = runes[len(runes)-truncationMarkerWindow:]
+	}
+	tail := strings.ToLower(string(runes))
+	for _, marker := range truncationMarkers {
+		if strings.Contains(tail, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoginRedirect はリダイレクトの結果、別のページのログイン・会員登録ページに到達したかを返す
+func isLoginRedirect(original, final *url.URL) bool {
+	if final == nil || original.String() == final.String() {
+		return false
+	}
+	// 元のURL自体がログインページの場合は誤判定を避ける
+	if loginPathPattern.MatchString(original.Path) {
+		return false
+	}
+	return loginPathPattern.MatchString(final.Path)
+}
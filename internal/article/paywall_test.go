@@ -0,0 +1,142 @@
+package article
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+func TestDetectPaywall(t *testing.T) {
+	teaser := "Goのエラー処理について解説します。センチネルエラーとラップの使い分けを紹介します。"
+	longText := strings.Repeat("Goのエラー処理を詳しく解説します。", 200)
+
+	tests := []struct {
+		name        string
+		html        string
+		text        string
+		wantSignals []string
+	}{
+		{
+			name:        "JSON-LDのisAccessibleForFree=false",
+			html:        `<script type="application/ld+json">{"@type":"NewsArticle","isAccessibleForFree": "False"}</script>`,
+			text:        longText,
+			wantSignals: []string{signalNotFree},
+		},
+		{
+			name:        "有料記事のマークアップ（ティーザー）",
+			html:        `<div class="article-body paywall-container"><p>冒頭</p></div>`,
+			text:        teaser,
+			wantSignals: []string{signalPaywallMarkup},
+		},
+		{
+			name:        "末尾の誘導文言（note）",
+			html:        `<p>冒頭</p>`,
+			text:        teaser + "\n\nこの続きをみるには 記事を購入",
+			wantSignals: []string{signalTruncation},
+		},
+		{
+			name:        "末尾の誘導文言（Medium）",
+			html:        `<p>intro</p>`,
+			text:        "Member-only story. An introduction to Go generics.",
+			wantSignals: []string{signalTruncation},
+		},
+		{
+			name: "十分な長さの本文はマークアップがあっても無料記事",
+			html: `<div class="paywall-banner">会員登録</div>`,
+			text: longText,
+		},
+		{
+			name: "通常の記事",
+			html: `<script type="application/ld+json">{"isAccessibleForFree": true}</script>`,
+			text: teaser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := detectPaywall(tt.html, tt.text)
+			if strings.Join(signals, ",") != strings.Join(tt.wantSignals, ",") {
+				t.Errorf("detectPaywall() = %v, 期待 %v", signals, tt.wantSignals)
+			}
+		})
+	}
+}
+
+func TestIsLoginRedirect(t *testing.T) {
+	tests := []struct {
+		original string
+		final    string
+		want     bool
+	}{
+		{"https://example.com/articles/1", "https://example.com/login?next=/articles/1", true},
+		{"https://medium.com/@user/post-123", "https://medium.com/m/signin?redirect=post-123", true},
+		{"https://example.com/articles/1", "https://example.com/articles/1/", false},
+		{"https://example.com/articles/1", "https://example.com/articles/1", false},
+		{"https://example.com/auth/callback", "https://example.com/login", false}, // 元のURLもログイン関連
+		{"https://example.com/blog/authors", "https://example.com/blog/authors/1", false},
+	}
+
+	for _, tt := range tests {
+		original, _ := url.Parse(tt.original)
+		final, _ := url.Parse(tt.final)
+		if got := isLoginRedirect(original, final); got != tt.want {
+			t.Errorf("isLoginRedirect(%s, %s) = %v, 期待 %v", tt.original, tt.final, got, tt.want)
+		}
+	}
+}
+
+func TestFetcher_Fetch_LoginRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/members/article" {
+			http.Redirect(w, r, "/signin?return_to=/members/article", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body><form>ログイン</form></body></html>"))
+	}))
+	defer server.Close()
+
+	fetcher := newTestFetcher()
+	fetcher.hosts = newHostLimiter(0) // リダイレクトごとのホストの待機を省略する
+	_, _, err := fetcher.Fetch(context.Background(), server.URL+"/members/article")
+	if err == nil {
+		t.Fatal("ログインページへのリダイレクトがエラーになりませんでした")
+	}
+	if reason := RejectionReason(err); reason != config.ReasonPaywalled {
+		t.Errorf("却下理由 = %s, 期待 %s", reason, config.ReasonPaywalled)
+	}
+}
+
+func TestExtractor_ExtractContent_Paywalled(t *testing.T) {
+	htmlContent := `<html><head><title>会員限定の記事</title>
+<script type="application/ld+json">{"@type":"Article","isAccessibleForFree":false}</script>
+</head><body><article><p>` + strings.Repeat("冒頭部分のみ表示されます。", 20) + `</p></article></body></html>`
+
+	extractor := NewExtractor(50, 100000)
+	_, err := extractor.ExtractContent(context.Background(), htmlContent, "https://example.com/paid")
+	if reason := RejectionReason(err); reason != config.ReasonPaywalled {
+		t.Errorf("却下理由 = %s, 期待 %s (err=%v)", reason, config.ReasonPaywalled, err)
+	}
+}
+
+func TestExtractor_FeedSummaryContent(t *testing.T) {
+	extractor := NewExtractor(50, 100000)
+
+	if content := extractor.FeedSummaryContent("短い要約"); content != nil {
+		t.Error("最小文字数に満たない要約は使用しないべき")
+	}
+
+	summary := strings.Repeat("Goのジェネリクスを使った型安全なコレクションの実装を紹介します。", 3)
+	content := extractor.FeedSummaryContent(summary)
+	if content == nil {
+		t.Fatal("十分な長さの要約が使用されませんでした")
+	}
+	if !content.Paywalled || content.Text != summary {
+		t.Errorf("要約の内容が正しくありません: %+v", content)
+	}
+}
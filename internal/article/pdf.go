@@ -0,0 +1,119 @@
+package article
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// maxPDFPages はテキストを抽出するPDFの最大ページ数
+	// 論文の評価には冒頭（概要・導入）があれば十分なため、付録などの後半は読まない
+	maxPDFPages = 30
+)
+
+// pdfMagic はPDFファイルの先頭のバイト列
+var pdfMagic = []byte("%PDF-")
+
+// isPDF はContent-Typeまたは内容の先頭からPDFかどうかを判定する
+// Content-Typeがapplication/octet-streamなどの汎用的な値の場合もあるため、先頭のバイト列も確認する
+func isPDF(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "application/pdf" {
+		return true
+	}
+	return bytes.HasPrefix(body, pdfMagic)
+}
+
+// pdfDocument はPDFから抽出した内容を表す
+type pdfDocument struct {
+	title  string
+	author string
+	text   string
+}
+
+// parsePDF はPDFから文書情報（タイトル・著者）と本文のテキストを抽出する
+// PDFパーサーは不正なファイルでpanicすることがあるため、エラーとして扱う
+func parsePDF(body []byte) (doc *pdfDocument, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			doc = nil
+			err = fmt.Errorf("PDFの解析中に異常が発生: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	info := reader.Trailer().Key("Info")
+	doc = &pdfDocument{
+		title:  strings.TrimSpace(info.Key("Title").Text()),
+		author: strings.TrimSpace(info.Key("Author").Text()),
+	}
+
+	var lines []string
+	pages := min(reader.NumPage(), maxPDFPages)
+	for i := 1; i <= pages; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		rows, err := page.GetTextByRow()
+		if err != nil {
+			return nil, fmt.Errorf("%dページ目のテキスト抽出に失敗: %w", i, err)
+		}
+		for _, row := range rows {
+			if line := joinPDFRow(row.Content); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		// ページの区切りは段落の区切りとして扱う
+		lines = append(lines, "")
+	}
+
+	doc.text = strings.TrimSpace(strings.Join(lines, "\n"))
+	return doc, nil
+}
+
+// joinPDFRow は1行分のテキスト断片を連結する
+// 英単語の断片の間には空白を入れ、日本語（CJK文字）の間には入れない
+func joinPDFRow(texts pdf.TextHorizontal) string {
+	var b strings.Builder
+	for _, t := range texts {
+		s := strings.TrimSpace(t.S)
+		if s == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(b.String())
+			next, _ := utf8.DecodeRuneInString(s)
+			if !isCJK(prev) && !isCJK(next) {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(s)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// isCJK は漢字・ひらがな・カタカナ・全角記号かどうかを返す
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || (r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// pdfTitle はPDFの文書情報のタイトルを返す
+// 文書情報がない（またはファイル名のような値の）場合は、本文の最初の行をタイトルとして使用する
+func pdfTitle(doc *pdfDocument) string {
+	title := doc.title
+	if title != "" && !strings.HasSuffix(strings.ToLower(title), ".pdf") && !strings.EqualFold(title, "untitled") {
+		return title
+	}
+	firstLine, _, _ := strings.Cut(doc.text, "\n")
+	return truncateUTF8(strings.TrimSpace(firstLine), 200)
+}
@@ -0,0 +1,100 @@
+package article
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsePDF(t *testing.T) {
+	doc, err := parsePDF(readFixture(t, "paper.pdf"))
+	if err != nil {
+		t.Fatalf("parsePDF failed: %v", err)
+	}
+
+	if doc.title != "Serving LLMs from Go" {
+		t.Errorf("title = %q", doc.title)
+	}
+	if doc.author != "Gopher, Jane" {
+		t.Errorf("author = %q", doc.author)
+	}
+	for _, want := range []string{"Attention Is All You Need, Again", "batching,", "tail latency"} {
+		if !strings.Contains(doc.text, want) {
+			t.Errorf("本文に %q が含まれていません: %q", want, doc.text)
+		}
+	}
+}
+
+func TestParsePDF_Invalid(t *testing.T) {
+	if _, err := parsePDF([]byte("%PDF-1.4\nbroken")); err == nil {
+		t.Error("不正なPDFがエラーになりませんでした")
+	}
+}
+
+func TestFetcher_FetchDocument_PDF(t *testing.T) {
+	body := readFixture(t, "paper.pdf")
+
+	for _, contentType := range []string{"application/pdf", "application/octet-stream"} {
+		t.Run(contentType, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", contentType)
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			ctx := context.Background()
+			doc, err := newTestFetcher().FetchDocument(ctx, server.URL+"/paper.pdf")
+			if err != nil {
+				t.Fatalf("FetchDocument failed: %v", err)
+			}
+			if !doc.IsPDF() {
+				t.Fatal("PDFとして判定されませんでした")
+			}
+
+			content, err := NewExtractor(50, 100000).ExtractDocument(ctx, doc)
+			if err != nil {
+				t.Fatalf("ExtractDocument failed: %v", err)
+			}
+			if content.Title != "Serving LLMs from Go" || content.Author != "Gopher, Jane" {
+				t.Errorf("タイトル・著者が正しくありません: %q, %q", content.Title, content.Author)
+			}
+			if !strings.Contains(content.Text, "Transformer architecture") {
+				t.Errorf("本文が抽出されていません: %q", content.Text)
+			}
+		})
+	}
+}
+
+func TestExtractor_ExtractDocument_Arxiv(t *testing.T) {
+	htmlContent := `<html><head>
+<title>[2401.00001] Serving LLMs from Go</title>
+<meta name="citation_title" content="Serving LLMs from Go" />
+<meta name="citation_author" content="Gopher, Jane" />
+<meta name="citation_author" content="Pike, Rob" />
+<meta name="citation_date" content="2024/01/02" />
+<meta name="citation_pdf_url" content="https://arxiv.org/pdf/2401.00001" />
+<meta name="citation_abstract" content="We revisit the Transformer architecture and show how Go services can serve
+ large language models with low latency, covering batching and streaming." />
+</head><body><div id="abs">ナビゲーションなど本文以外の要素</div></body></html>`
+
+	doc := &Document{URL: "https://arxiv.org/abs/2401.00001", HTML: htmlContent}
+	content, err := NewExtractor(50, 100000).ExtractDocument(context.Background(), doc)
+	if err != nil {
+		t.Fatalf("ExtractDocument failed: %v", err)
+	}
+
+	if content.Title != "Serving LLMs from Go" {
+		t.Errorf("Title = %q", content.Title)
+	}
+	if content.Author != "Jane Gopher, Rob Pike" {
+		t.Errorf("Author = %q", content.Author)
+	}
+	if content.SiteName != "arXiv" || content.PublishedAt.Format("2006-01-02") != "2024-01-02" {
+		t.Errorf("SiteName = %q, PublishedAt = %v", content.SiteName, content.PublishedAt)
+	}
+	if !strings.Contains(content.Text, "## Abstract") || !strings.Contains(content.Text, "serve large language models") {
+		t.Errorf("概要が本文に含まれていません: %q", content.Text)
+	}
+}
@@ -0,0 +1,305 @@
+package article
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+const (
+	// UserAgent は記事取得時に送信するUser-Agent
+	UserAgent = "Mozilla/5.0 (compatible; discord-article-bot/1.0)"
+
+	// robotsUserAgent はrobots.txtのUser-agent行と照合するプロダクトトークン
+	robotsUserAgent = "discord-article-bot"
+
+	// robotsCacheTTL はホストごとのrobots.txtをキャッシュする期間
+	robotsCacheTTL = 24 * time.Hour
+
+	// robotsErrorTTL はrobots.txtを取得できなかった（5xx・ネットワークエラー）ホストをキャッシュする期間
+	// 到達できない間は記事を取得せず、短い期間の後に再取得する
+	robotsErrorTTL = 10 * time.Minute
+
+	// maxRobotsSize はrobots.txtとして読み取る最大サイズ（500KB、RFC 9309の推奨値）
+	maxRobotsSize = 500 * 1024
+)
+
+// robotsRule はrobots.txtのAllow/Disallow行を表す
+type robotsRule struct {
+	pattern *regexp.Regexp
+	length  int // 優先度の判定に使用するパターンの長さ
+	allow   bool
+}
+
+// robotsRules は自分のUser-Agentに適用されるrobots.txtのルールを表す
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// allowAll はすべてのパスを許可するルール（robots.txtが存在しない場合など）
+var allowAll = &robotsRules{}
+
+// allowed は指定したパス（クエリを含む）へのアクセスが許可されているかを返す
+// 最も長く一致したルールを採用し、同じ長さの場合はAllowを優先する
+func (r *robotsRules) allowed(path string) bool {
+	if path == "/robots.txt" {
+		return true
+	}
+
+	matched := -1
+	allow := true
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > matched || (rule.length == matched && rule.allow) {
+			matched = rule.length
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsGroup はrobots.txtのUser-agentごとのグループを表す
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots はrobots.txtを解析し、指定したUser-Agentに適用されるルールを返す
+// 一致するグループがない場合は「*」のグループを使用する
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// ルールの後に現れたUser-agentは新しいグループの開始
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			// 空のDisallowは「すべて許可」を意味するためルールとして扱わない
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				pattern: compileRobotsPattern(value),
+				length:  len(value),
+				allow:   key == "allow",
+			})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	userAgent = strings.ToLower(userAgent)
+	var specific, wildcard []*robotsGroup
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = append(wildcard, g)
+				break
+			}
+			if strings.HasPrefix(userAgent, agent) {
+				specific = append(specific, g)
+				break
+			}
+		}
+	}
+
+	selected := specific
+	if len(selected) == 0 {
+		selected = wildcard
+	}
+
+	// 同じUser-Agentに一致する複数のグループはまとめて適用する
+	rules := &robotsRules{}
+	for _, g := range selected {
+		rules.rules = append(rules.rules, g.rules...)
+		rules.crawlDelay = max(rules.crawlDelay, g.crawlDelay)
+	}
+	return rules
+}
+
+// compileRobotsPattern はrobots.txtのパスパターン（*と$に対応）を正規表現に変換する
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// robotsEntry はホストごとのrobots.txtのキャッシュエントリ
+type robotsEntry struct {
+	ready   chan struct{} // 取得完了時にcloseされる
+	done    bool          // 取得が完了しているか（robotsCache.muで保護）
+	rules   *robotsRules
+	err     error // robots.txtに到達できなかった場合のエラー
+	expires time.Time
+}
+
+// robotsCache はホストごとにrobots.txtを取得・キャッシュする
+// 同じホストへの同時アクセスでもrobots.txtの取得は1回のみ行う
+type robotsCache struct {
+	client  *http.Client
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+// newRobotsCache は新しいrobots.txtキャッシュを作成する
+func newRobotsCache(client *http.Client) *robotsCache {
+	return &robotsCache{
+		client:  client,
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// rules は記事URLのホストに適用されるrobots.txtのルールを返す
+// robots.txtに到達できない（5xx・ネットワークエラー）場合は、RFC 9309に従い記事を取得しないようエラーを返す
+func (c *robotsCache) rules(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	key := u.Scheme + "://" + u.Host
+
+	for {
+		c.mu.Lock()
+		entry := c.entries[key]
+		if entry != nil && entry.done && time.Now().After(entry.expires) {
+			entry = nil
+		}
+		if entry == nil {
+			entry = &robotsEntry{ready: make(chan struct{})}
+			c.entries[key] = entry
+			c.mu.Unlock()
+
+			rules, ttl, err := c.fetch(ctx, key)
+
+			c.mu.Lock()
+			entry.rules = rules
+			entry.err = err
+			entry.expires = time.Now().Add(ttl)
+			entry.done = true
+			// キャンセル・タイムアウトによる失敗はキャッシュしない
+			if ttl <= 0 && c.entries[key] == entry {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+			close(entry.ready)
+			return rules, err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		c.mu.Lock()
+		cached := c.entries[key] == entry
+		c.mu.Unlock()
+		if cached {
+			return entry.rules, entry.err
+		}
+		// 取得した呼び出し元のキャンセルで失敗した場合は取得し直す
+	}
+}
+
+// fetch はrobots.txtを取得して解析し、ルールとキャッシュする期間を返す
+// robots.txtが存在しない（4xx）場合は、すべて許可として扱う
+// サーバーエラー（5xx）やネットワークエラーの場合は、robotsErrorTTLの間キャッシュするエラーを返す
+// キャンセル・タイムアウトの場合は、キャッシュしないよう期間0でエラーを返す
+func (c *robotsCache) fetch(ctx context.Context, origin string) (*robotsRules, time.Duration, error) {
+	logger := logging.FromContext(ctx)
+	robotsURL := origin + "/robots.txt"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, robotsErrorTTL, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, err
+		}
+		logger.Warn("robots.txtの取得に失敗。しばらくこのホストの記事を取得しません", "url", robotsURL, "error", err)
+		return nil, robotsErrorTTL, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		logger.Warn("robots.txtの取得でサーバーエラー。しばらくこのホストの記事を取得しません", "url", robotsURL, "status", resp.StatusCode)
+		return nil, robotsErrorTTL, fmt.Errorf("robots.txtの取得に失敗: HTTPステータス %d", resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return allowAll, robotsCacheTTL, nil
+	}
+
+	rules := parseRobots(io.LimitReader(resp.Body, maxRobotsSize), robotsUserAgent)
+	logger.Debug("robots.txtを取得しました",
+		"url", robotsURL,
+		"rules", len(rules.rules),
+		"crawlDelay", rules.crawlDelay,
+	)
+	return rules, robotsCacheTTL, nil
+}
+
+// robotsPath はrobots.txtの照合に使用するパス（クエリを含む）を返す
+func robotsPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
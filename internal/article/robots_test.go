@@ -0,0 +1,270 @@
+package article
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+func TestParseRobots(t *testing.T) {
+	robotsTxt := `# サンプル
+User-agent: *
+Disallow: /admin/
+Crawl-delay: 5
+
+User-agent: Googlebot
+User-agent: discord-article-bot
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 2
+`
+
+	rules := parseRobots(strings.NewReader(robotsTxt), robotsUserAgent)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/admin/page", true}, // 自分専用のグループがあるため「*」のルールは適用しない
+		{"/private", false},
+		{"/private/secret", false},
+		{"/private/public/article", true}, // より長いAllowを優先
+		{"/docs/guide.pdf", false},
+		{"/docs/guide.pdf?download=1", true}, // $は末尾一致
+		{"/search?q=go", false},
+		{"/robots.txt", true},
+	}
+	for _, tt := range tests {
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("allowed(%q) = %v, 期待 %v", tt.path, got, tt.want)
+		}
+	}
+
+	if rules.crawlDelay != 2*time.Second {
+		t.Errorf("crawlDelay = %v, 期待 2s", rules.crawlDelay)
+	}
+}
+
+func TestParseRobots_Wildcard(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: *\nDisallow: /\nAllow: /$\n"), robotsUserAgent)
+
+	if !rules.allowed("/") {
+		t.Error("トップページが許可されていません")
+	}
+	if rules.allowed("/articles/1") {
+		t.Error("「*」グループのDisallowが適用されていません")
+	}
+
+	empty := parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), robotsUserAgent)
+	if !empty.allowed("/articles/1") {
+		t.Error("空のDisallowはすべて許可として扱うべき")
+	}
+}
+
+func TestFetcher_Fetch_Robots(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			w.Write([]byte("User-agent: discord-article-bot\nDisallow: /private/\nCrawl-delay: 0.2\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body><p>記事本文</p></body></html>"))
+	}))
+	defer server.Close()
+
+	fetcher := newTestFetcher()
+	fetcher.hosts = newHostLimiter(0) // Crawl-delayのみで間隔を制御する
+	ctx := context.Background()
+
+	_, _, err := fetcher.Fetch(ctx, server.URL+"/private/article")
+	if err == nil {
+		t.Fatal("robots.txtで禁止されたURLの取得がエラーになりませんでした")
+	}
+	if reason := RejectionReason(err); reason != config.ReasonRobotsDisallowed {
+		t.Errorf("却下理由 = %s, 期待 %s", reason, config.ReasonRobotsDisallowed)
+	}
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, _, err := fetcher.Fetch(ctx, server.URL+"/articles/1"); err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Crawl-delayが守られていません: %v", elapsed)
+	}
+
+	if n := robotsRequests.Load(); n != 1 {
+		t.Errorf("robots.txtがキャッシュされていません: %d回取得", n)
+	}
+}
+
+func TestFetcher_Fetch_RobotsAfterRedirect(t *testing.T) {
+	var articleRequests atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+			return
+		}
+		articleRequests.Add(1)
+		w.Write([]byte("<html><body><p>記事本文</p></body></html>"))
+	}))
+	defer target.Close()
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, target.URL+strings.TrimPrefix(r.URL.Path, "/r"), http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	fetcher := newTestFetcher()
+	fetcher.hosts = newHostLimiter(0)
+
+	// リダイレクト先のホストのrobots.txtで禁止されているURLは取得しない
+	_, _, err := fetcher.Fetch(context.Background(), redirector.URL+"/r/private/article")
+	if err == nil {
+		t.Fatal("リダイレクト先でrobots.txtに禁止されたURLの取得がエラーになりませんでした")
+	}
+	if reason := RejectionReason(err); reason != config.ReasonRobotsDisallowed {
+		t.Errorf("却下理由 = %s, 期待 %s", reason, config.ReasonRobotsDisallowed)
+	}
+	if n := articleRequests.Load(); n != 0 {
+		t.Errorf("禁止された記事を%d回取得しました", n)
+	}
+
+	// 許可されているURLはリダイレクトを追跡して取得する
+	if _, _, err := fetcher.Fetch(context.Background(), redirector.URL+"/r/articles/1"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if n := articleRequests.Load(); n != 1 {
+		t.Errorf("記事の取得回数 = %d, 期待 1", n)
+	}
+}
+
+func TestFetcher_Fetch_LongCrawlDelay(t *testing.T) {
+	var articleRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nCrawl-delay: 86400\n"))
+			return
+		}
+		articleRequests.Add(1)
+		w.Write([]byte("<html><body><p>記事本文</p></body></html>"))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, _, err := newTestFetcher().Fetch(context.Background(), server.URL+"/articles/1")
+	if err == nil {
+		t.Fatal("Crawl-delayが上限を超えるホストの記事を取得しました")
+	}
+	if reason := RejectionReason(err); reason != config.ReasonRobotsDisallowed {
+		t.Errorf("却下理由 = %s, 期待 %s", reason, config.ReasonRobotsDisallowed)
+	}
+	if n := articleRequests.Load(); n != 0 {
+		t.Errorf("記事を%d回取得しました", n)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Crawl-delayの待機で止まりました: %v", elapsed)
+	}
+}
+
+func TestFetcher_Fetch_RobotsUnavailable(t *testing.T) {
+	var robotsRequests, articleRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		articleRequests.Add(1)
+		w.Write([]byte("<html><body><p>記事本文</p></body></html>"))
+	}))
+	defer server.Close()
+
+	fetcher := newTestFetcher()
+	for i := 0; i < 2; i++ {
+		_, _, err := fetcher.Fetch(context.Background(), server.URL+"/articles/1")
+		if err == nil {
+			t.Fatal("robots.txtが5xxのホストの記事を取得しました")
+		}
+		if reason := RejectionReason(err); reason == config.ReasonRobotsDisallowed {
+			t.Errorf("一時的な失敗の却下理由が %s になっています", reason)
+		}
+	}
+	if n := articleRequests.Load(); n != 0 {
+		t.Errorf("robots.txtに到達できないホストの記事を%d回取得しました", n)
+	}
+	// 失敗も短い期間キャッシュし、記事ごとにrobots.txtを取得しない
+	if n := robotsRequests.Load(); n != 1 {
+		t.Errorf("robots.txtの取得回数 = %d, 期待 1", n)
+	}
+}
+
+func TestRobotsCache_Rules(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		robotsRequests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cache := newRobotsCache(server.Client())
+	u, _ := url.Parse(server.URL + "/articles/1")
+
+	// キャンセルによる失敗はキャッシュしない
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.rules(canceled, u); err == nil {
+		t.Fatal("キャンセルされたコンテキストでエラーになりませんでした")
+	}
+
+	// robots.txtが存在しない（4xx）場合はすべて許可し、キャッシュする
+	for i := 0; i < 2; i++ {
+		rules, err := cache.rules(context.Background(), u)
+		if err != nil {
+			t.Fatalf("rules failed: %v", err)
+		}
+		if !rules.allowed("/articles/1") {
+			t.Error("robots.txtが存在しないホストの記事が禁止されました")
+		}
+	}
+	if n := robotsRequests.Load(); n != 1 {
+		t.Errorf("robots.txtの取得回数 = %d, 期待 1", n)
+	}
+
+	// 4xxの結果はrobotsCacheTTLでキャッシュされる
+	entry := cache.entries[server.URL]
+	if ttl := time.Until(entry.expires); ttl <= robotsErrorTTL {
+		t.Errorf("4xxの結果のキャッシュ期間が短すぎます: %v", ttl)
+	}
+}
+
+func TestRejectionReason_Default(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, _, err := newTestFetcher().Fetch(context.Background(), server.URL+"/missing")
+	if err == nil {
+		t.Fatal("404のURLの取得がエラーになりませんでした")
+	}
+	if reason := RejectionReason(err); reason != config.ReasonContentExtractionFailed {
+		t.Errorf("却下理由 = %s, 期待 %s", reason, config.ReasonContentExtractionFailed)
+	}
+}
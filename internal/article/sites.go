@@ -0,0 +1,320 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// jsonFetcher はサイトの公開APIからJSONを取得する（*Fetcherが実装する）
+type jsonFetcher interface {
+	FetchJSON(ctx context.Context, apiURL string, v any) error
+}
+
+// siteArticle はサイト固有の構造化データから取得した記事の内容を表す
+// 本文はMarkdown（markdown）またはHTML（bodyHTML）のいずれか
+type siteArticle struct {
+	title       string
+	markdown    string
+	bodyHTML    string
+	excerpt     string
+	author      string
+	imageURL    string
+	publishedAt time.Time
+	tags        []string
+	likeCount   int
+}
+
+// siteExtractor はサイト固有の構造化データ（公開API・埋め込みJSON）から記事を取得する
+// URLが記事ページでない場合など、対応していない場合はnil, nilを返す
+type siteExtractor struct {
+	siteName string
+	extract  func(ctx context.Context, api jsonFetcher, doc *Document, u *url.URL) (*siteArticle, error)
+}
+
+// siteExtractors はホスト名ごとのサイト固有の抽出処理
+// 登録されていないホストはreadabilityで抽出する
+var siteExtractors = map[string]siteExtractor{
+	"dev.to":    {siteName: "DEV Community", extract: extractDevTo},
+	"qiita.com": {siteName: "Qiita", extract: extractQiita},
+	"zenn.dev":  {siteName: "Zenn", extract: extractZenn},
+	"note.com":  {siteName: "note", extract: extractNote},
+}
+
+// lookupSiteExtractor はホスト名に対応するサイト固有の抽出処理を返す
+func lookupSiteExtractor(host string) (siteExtractor, bool) {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	extractor, ok := siteExtractors[host]
+	return extractor, ok
+}
+
+// pathSegments はURLのパスを「/」で区切った空でない要素を返す
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// devToReservedPaths は記事ではないdev.toのトップレベルのパス
+var devToReservedPaths = map[string]bool{
+	"t": true, "tags": true, "search": true, "settings": true, "api": true, "top": true, "latest": true,
+}
+
+// devToArticle はdev.toの記事API（/api/articles/{username}/{slug}）のレスポンス
+type devToArticle struct {
+	Title                string     `json:"title"`
+	Description          string     `json:"description"`
+	BodyMarkdown         string     `json:"body_markdown"`
+	Tags                 devToTags  `json:"tags"`
+	PublicReactionsCount int        `json:"public_reactions_count"`
+	CoverImage           string     `json:"cover_image"`
+	PublishedAt          *time.Time `json:"published_at"`
+	User                 struct {
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+// devToTags はdev.toのタグ
+// APIのエンドポイントによって配列と「go, webdev」形式の文字列の両方があるため、どちらも受け付ける
+type devToTags []string
+
+// UnmarshalJSON はタグの配列またはカンマ区切りの文字列をデコードする
+func (t *devToTags) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*t = list
+		return nil
+	}
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return err
+	}
+	*t = nil
+	for _, tag := range strings.Split(joined, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*t = append(*t, tag)
+		}
+	}
+	return nil
+}
+
+// extractDevTo はdev.toの記事APIからMarkdownの本文・タグ・リアクション数を取得する
+func extractDevTo(ctx context.Context, api jsonFetcher, _ *Document, u *url.URL) (*siteArticle, error) {
+	segments := pathSegments(u)
+	if api == nil || len(segments) != 2 || devToReservedPaths[segments[0]] {
+		return nil, nil
+	}
+
+	apiURL := fmt.Sprintf("https://dev.to/api/articles/%s/%s", url.PathEscape(segments[0]), url.PathEscape(segments[1]))
+	var res devToArticle
+	if err := api.FetchJSON(ctx, apiURL, &res); err != nil {
+		return nil, err
+	}
+
+	article := &siteArticle{
+		title:     res.Title,
+		markdown:  res.BodyMarkdown,
+		excerpt:   res.Description,
+		author:    res.User.Name,
+		imageURL:  res.CoverImage,
+		tags:      res.Tags,
+		likeCount: res.PublicReactionsCount,
+	}
+	if res.PublishedAt != nil {
+		article.publishedAt = *res.PublishedAt
+	}
+	return article, nil
+}
+
+// qiitaItemIDPattern はQiitaの記事IDにマッチする
+var qiitaItemIDPattern = regexp.MustCompile(`^[0-9a-f]{20}$`)
+
+// qiitaItem はQiitaの記事API（/api/v2/items/{item_id}）のレスポンス
+type qiitaItem struct {
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	LikesCount int        `json:"likes_count"`
+	CreatedAt  *time.Time `json:"created_at"`
+	Tags       []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+	User struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+// extractQiita はQiitaの記事APIからMarkdownの本文・タグ・いいね数を取得する
+// 記事のURLは /{user}/items/{item_id} 形式
+func extractQiita(ctx context.Context, api jsonFetcher, _ *Document, u *url.URL) (*siteArticle, error) {
+	segments := pathSegments(u)
+	if api == nil || len(segments) != 3 || segments[1] != "items" || !qiitaItemIDPattern.MatchString(segments[2]) {
+		return nil, nil
+	}
+
+	var res qiitaItem
+	if err := api.FetchJSON(ctx, "https://qiita.com/api/v2/items/"+segments[2], &res); err != nil {
+		return nil, err
+	}
+
+	article := &siteArticle{
+		title:     res.Title,
+		markdown:  res.Body,
+		author:    res.User.Name,
+		likeCount: res.LikesCount,
+	}
+	if article.author == "" {
+		article.author = res.User.ID
+	}
+	for _, tag := range res.Tags {
+		article.tags = append(article.tags, tag.Name)
+	}
+	if res.CreatedAt != nil {
+		article.publishedAt = *res.CreatedAt
+	}
+	return article, nil
+}
+
+// nextDataPattern はNext.jsのページに埋め込まれた__NEXT_DATA__のJSONにマッチする
+var nextDataPattern = regexp.MustCompile(`(?s)<script[^>]*id=["']__NEXT_DATA__["'][^>]*>(.*?)</script>`)
+
+// zennNextData はZennの記事ページの__NEXT_DATA__のうち、記事に関する部分
+type zennNextData struct {
+	Props struct {
+		PageProps struct {
+			Article *struct {
+				Title       string     `json:"title"`
+				BodyHTML    string     `json:"bodyHtml"`
+				LikedCount  int        `json:"likedCount"`
+				PublishedAt *time.Time `json:"publishedAt"`
+				OgImageURL  string     `json:"ogImageUrl"`
+				Topics      []struct {
+					Name        string `json:"name"`
+					DisplayName string `json:"displayName"`
+				} `json:"topics"`
+				User struct {
+					Name     string `json:"name"`
+					Username string `json:"username"`
+				} `json:"user"`
+			} `json:"article"`
+		} `json:"pageProps"`
+	} `json:"props"`
+}
+
+// extractZenn はZennの記事ページに埋め込まれた__NEXT_DATA__から本文・トピック・いいね数を取得する
+// 記事のURLは /{user}/articles/{slug} 形式。APIを呼ばず、取得済みのHTMLのみを使用する
+func extractZenn(_ context.Context, _ jsonFetcher, doc *Document, u *url.URL) (*siteArticle, error) {
+	segments := pathSegments(u)
+	if len(segments) != 3 || segments[1] != "articles" {
+		return nil, nil
+	}
+
+	m := nextDataPattern.FindStringSubmatch(doc.HTML)
+	if m == nil {
+		return nil, nil
+	}
+	var data zennNextData
+	if err := json.Unmarshal([]byte(m[1]), &data); err != nil {
+		return nil, fmt.Errorf("__NEXT_DATA__のパースに失敗: %w", err)
+	}
+	res := data.Props.PageProps.Article
+	if res == nil || res.BodyHTML == "" {
+		return nil, nil
+	}
+
+	article := &siteArticle{
+		title:     res.Title,
+		bodyHTML:  res.BodyHTML,
+		author:    res.User.Name,
+		imageURL:  res.OgImageURL,
+		likeCount: res.LikedCount,
+	}
+	if article.author == "" {
+		article.author = res.User.Username
+	}
+	for _, topic := range res.Topics {
+		name := topic.DisplayName
+		if name == "" {
+			name = topic.Name
+		}
+		article.tags = append(article.tags, name)
+	}
+	if res.PublishedAt != nil {
+		article.publishedAt = *res.PublishedAt
+	}
+	return article, nil
+}
+
+// noteKeyPattern はnoteの記事キー（n + 英数字）にマッチする
+var noteKeyPattern = regexp.MustCompile(`^n[0-9a-z]+$`)
+
+// noteResponse はnoteの記事API（/api/v3/notes/{key}）のレスポンス
+type noteResponse struct {
+	Data struct {
+		Name         string `json:"name"`
+		Body         string `json:"body"`
+		LikeCount    int    `json:"like_count"`
+		Price        int    `json:"price"`
+		PublishAt    string `json:"publish_at"`
+		Eyecatch     string `json:"eyecatch"`
+		HashtagNotes []struct {
+			Hashtag struct {
+				Name string `json:"name"`
+			} `json:"hashtag"`
+		} `json:"hashtag_notes"`
+		User struct {
+			Nickname string `json:"nickname"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+// extractNote はnoteの記事APIから本文・ハッシュタグ・スキ数を取得する
+// 記事のURLは /{user}/n/{key} 形式。有料記事は本文が冒頭部分のみのため対応しない
+func extractNote(ctx context.Context, api jsonFetcher, _ *Document, u *url.URL) (*siteArticle, error) {
+	segments := pathSegments(u)
+	if api == nil || len(segments) != 3 || segments[1] != "n" || !noteKeyPattern.MatchString(segments[2]) {
+		return nil, nil
+	}
+
+	var res noteResponse
+	if err := api.FetchJSON(ctx, "https://note.com/api/v3/notes/"+segments[2], &res); err != nil {
+		return nil, err
+	}
+	if res.Data.Price > 0 || res.Data.Body == "" {
+		return nil, nil
+	}
+
+	article := &siteArticle{
+		title:     res.Data.Name,
+		bodyHTML:  res.Data.Body,
+		author:    res.Data.User.Nickname,
+		imageURL:  res.Data.Eyecatch,
+		likeCount: res.Data.LikeCount,
+	}
+	for _, tag := range res.Data.HashtagNotes {
+		article.tags = append(article.tags, strings.TrimPrefix(tag.Hashtag.Name, "#"))
+	}
+	if publishedAt, ok := parseMetaTime(res.Data.PublishAt); ok {
+		article.publishedAt = publishedAt
+	}
+	return article, nil
+}
+
+// content は本文をMarkdownに変換し、構造の統計情報を数える
+func (a *siteArticle) content() (string, config.ContentStats, error) {
+	if a.markdown != "" {
+		text := strings.TrimSpace(a.markdown)
+		return text, markdownStats(text), nil
+	}
+	return htmlFragmentToMarkdown(a.bodyHTML)
+}
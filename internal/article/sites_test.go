@@ -0,0 +1,211 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeJSONFetcher はURLごとに固定のJSONを返すテスト用のjsonFetcher
+type fakeJSONFetcher struct {
+	responses map[string]string
+	requested []string
+}
+
+func (f *fakeJSONFetcher) FetchJSON(_ context.Context, apiURL string, v any) error {
+	f.requested = append(f.requested, apiURL)
+	body, ok := f.responses[apiURL]
+	if !ok {
+		return fmt.Errorf("HTTPステータス 404: %s", apiURL)
+	}
+	return json.Unmarshal([]byte(body), v)
+}
+
+const siteTestBody = "## Goroutineの基本\n\n本文の説明です。ゴルーチンとチャネルを使った並行処理の書き方を解説します。\n\n" +
+	"```go\ngo func() {}()\n// # コメントは見出しではない\n```\n\n詳しくは[公式ドキュメント](https://go.dev/doc/)を参照してください。"
+
+func TestExtractDocument_SiteExtractors(t *testing.T) {
+	api := &fakeJSONFetcher{responses: map[string]string{
+		"https://dev.to/api/articles/gopher/goroutines-101-abc": `{
+			"title": "Goroutines 101", "description": "並行処理の入門", "body_markdown": ` + jsonString(siteTestBody) + `,
+			"tags": "go, concurrency", "public_reactions_count": 42, "cover_image": "https://example.com/cover.png",
+			"published_at": "2024-05-01T09:00:00Z", "user": {"name": "Gopher"}}`,
+		"https://qiita.com/api/v2/items/0123456789abcdef0123": `{
+			"title": "Goのゴルーチン入門", "body": ` + jsonString(siteTestBody) + `, "likes_count": 128,
+			"created_at": "2024-05-02T10:00:00+09:00", "tags": [{"name": "Go"}, {"name": "並行処理"}],
+			"user": {"id": "gopher", "name": ""}}`,
+		"https://note.com/api/v3/notes/n0123abcd": `{"data": {
+			"name": "ゴルーチンのはなし", "body": "<h2>Goroutineの基本</h2><p>本文の説明です。ゴルーチンとチャネルを使った並行処理の書き方を解説します。</p>",
+			"like_count": 7, "price": 0, "publish_at": "2024-05-03T08:00:00+09:00",
+			"hashtag_notes": [{"hashtag": {"name": "#Go"}}], "user": {"nickname": "ごーふぁー"}}}`,
+	}}
+	extractor := NewExtractor(50, 100000)
+	extractor.api = api
+
+	tests := []struct {
+		name     string
+		url      string
+		html     string
+		title    string
+		author   string
+		siteName string
+		tags     []string
+		likes    int
+	}{
+		{
+			name: "dev.to", url: "https://dev.to/gopher/goroutines-101-abc",
+			title: "Goroutines 101", author: "Gopher", siteName: "DEV Community",
+			tags: []string{"go", "concurrency"}, likes: 42,
+		},
+		{
+			name: "Qiita", url: "https://qiita.com/gopher/items/0123456789abcdef0123",
+			title: "Goのゴルーチン入門", author: "gopher", siteName: "Qiita",
+			tags: []string{"Go", "並行処理"}, likes: 128,
+		},
+		{
+			name: "Zenn", url: "https://zenn.dev/gopher/articles/goroutines",
+			html:  zennTestHTML(`<h2 id="goroutine">Goroutineの基本</h2><p>本文の説明です。ゴルーチンとチャネルを使った並行処理の書き方を解説します。</p><pre><code class="language-go">go func() {}()</code></pre>`),
+			title: "Zennのゴルーチン", author: "ゴーファー", siteName: "Zenn",
+			tags: []string{"Go", "並行処理"}, likes: 15,
+		},
+		{
+			name: "note", url: "https://note.com/gopher/n/n0123abcd",
+			title: "ゴルーチンのはなし", author: "ごーふぁー", siteName: "note",
+			tags: []string{"Go"}, likes: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := extractor.ExtractDocument(context.Background(), &Document{URL: tt.url, HTML: tt.html})
+			if err != nil {
+				t.Fatalf("ExtractDocument failed: %v", err)
+			}
+			if content.Title != tt.title || content.Author != tt.author || content.SiteName != tt.siteName {
+				t.Errorf("title/author/siteName = %q/%q/%q", content.Title, content.Author, content.SiteName)
+			}
+			if !reflect.DeepEqual(content.Tags, tt.tags) {
+				t.Errorf("Tags = %v, want %v", content.Tags, tt.tags)
+			}
+			if content.LikeCount != tt.likes {
+				t.Errorf("LikeCount = %d, want %d", content.LikeCount, tt.likes)
+			}
+			if !strings.Contains(content.Text, "## Goroutineの基本") {
+				t.Errorf("本文がMarkdownではありません: %q", content.Text)
+			}
+			if content.PublishedAt.IsZero() {
+				t.Error("公開日時が設定されていません")
+			}
+		})
+	}
+}
+
+func TestExtractDocument_SiteExtractorFallback(t *testing.T) {
+	readable := `<html><head><title>Fallback</title></head><body><article><h1>Fallback</h1><p>` +
+		strings.Repeat("readabilityで抽出される本文です。", 20) + `</p></article></body></html>`
+
+	tests := []struct {
+		name string
+		url  string
+		html string
+	}{
+		{"未登録のホスト", "https://example.com/posts/1", readable},
+		{"APIの取得に失敗", "https://dev.to/gopher/missing-article", readable},
+		{"記事ページではない", "https://qiita.com/tags/go", readable},
+		{"__NEXT_DATA__がない", "https://zenn.dev/gopher/articles/old", readable},
+		{"有料のnote", "https://note.com/gopher/n/npaid", readable},
+	}
+
+	api := &fakeJSONFetcher{responses: map[string]string{
+		"https://note.com/api/v3/notes/npaid": `{"data": {"name": "有料記事", "body": "<p>冒頭</p>", "price": 500}}`,
+	}}
+	extractor := NewExtractor(50, 100000)
+	extractor.api = api
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := extractor.ExtractDocument(context.Background(), &Document{URL: tt.url, HTML: tt.html})
+			if err != nil {
+				t.Fatalf("ExtractDocument failed: %v", err)
+			}
+			if !strings.Contains(content.Text, "readabilityで抽出される本文です") {
+				t.Errorf("readabilityで抽出されていません: %q", content.Text)
+			}
+			if content.Tags != nil || content.LikeCount != 0 {
+				t.Errorf("フォールバック時にタグ・いいね数が設定されています: %v, %d", content.Tags, content.LikeCount)
+			}
+		})
+	}
+}
+
+func TestExtractDocument_SiteExtractorWithoutFetcher(t *testing.T) {
+	// Fetcherを設定しない場合、APIを使うサイトはreadabilityで抽出する
+	html := `<html><body><article><p>` + strings.Repeat("HTMLの本文です。", 20) + `</p></article></body></html>`
+	content, err := NewExtractor(50, 100000).ExtractDocument(context.Background(), &Document{
+		URL:  "https://qiita.com/gopher/items/0123456789abcdef0123",
+		HTML: html,
+	})
+	if err != nil {
+		t.Fatalf("ExtractDocument failed: %v", err)
+	}
+	if content.LikeCount != 0 || !strings.Contains(content.Text, "HTMLの本文です") {
+		t.Errorf("readabilityで抽出されていません: %+v", content)
+	}
+}
+
+func TestMarkdownStats(t *testing.T) {
+	stats := markdownStats(siteTestBody + "\n\n# まとめ\n\n![図](https://example.com/a.png) [目次](#top)")
+	if stats.CodeBlocks != 1 || stats.Headings != 2 || stats.Links != 1 {
+		t.Errorf("markdownStats = %+v", stats)
+	}
+}
+
+func TestFetcher_FetchJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if accept := r.Header.Get("Accept"); accept != "application/json" {
+			t.Errorf("Accept = %q", accept)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"title": "API"}`))
+	}))
+	defer server.Close()
+
+	var res struct {
+		Title string `json:"title"`
+	}
+	if err := newTestFetcher().FetchJSON(context.Background(), server.URL+"/api", &res); err != nil {
+		t.Fatalf("FetchJSON failed: %v", err)
+	}
+	if res.Title != "API" {
+		t.Errorf("Title = %q", res.Title)
+	}
+
+	if err := newTestFetcher().FetchJSON(context.Background(), server.URL+"/missing", &res); err == nil {
+		t.Error("404がエラーになりませんでした")
+	}
+}
+
+// zennTestHTML は本文のHTMLを__NEXT_DATA__に埋め込んだZennの記事ページを返す
+func zennTestHTML(bodyHTML string) string {
+	return `<html><head><title>Zenn</title></head><body><div id="__next"></div>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"article":{
+"title":"Zennのゴルーチン","bodyHtml":` + jsonString(bodyHTML) + `,"likedCount":15,
+"publishedAt":"2024-05-04T12:00:00.000+09:00","ogImageUrl":"https://res.cloudinary.com/zenn/og.png",
+"topics":[{"name":"go","displayName":"Go"},{"name":"concurrency","displayName":"並行処理"}],
+"user":{"name":"ゴーファー","username":"gopher"}}}}}</script></body></html>`
+}
+
+// jsonString は文字列をJSONの文字列リテラルに変換する
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
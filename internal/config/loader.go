@@ -4,16 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
 )
 
+// MaxConfigSize は設定ファイルの上限サイズ（1MB）
+// 設定ファイル自体の読み込みに使用するため、size_limit_settingsでは変更できない
+const MaxConfigSize = 1024 * 1024
+
 // Loader は設定ファイルを読み込むためのインターフェース
 type Loader interface {
 	Load(ctx context.Context, source string) (*Config, error)
+	// Fetch は設定ファイルと同じ方法（ファイルパスまたはURL）でファイルの内容を読み込みます
+	Fetch(ctx context.Context, source string) ([]byte, error)
 }
 
 // loader は設定ファイルローダーの実装
@@ -30,12 +39,36 @@ func NewLoader() Loader {
 	}
 }
 
+// NewLoaderWithTransport はURLからの読み込みに指定したhttp.RoundTripperを使用する設定ローダーを作成します
+// ローカル開発でHTTPレスポンスのキャッシュを使用する場合に指定します
+func NewLoaderWithTransport(transport http.RoundTripper) Loader {
+	return &loader{
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: transport,
+		},
+	}
+}
+
 // Load は指定されたソースから設定を読み込みます
 // sourceはファイルパスまたはURL（HTTPSまたはGitHub URL）を指定できます
 func (l *loader) Load(ctx context.Context, source string) (*Config, error) {
-	var data []byte
-	var err error
+	data, err := l.Fetch(ctx, source)
+	if err != nil {
+		return nil, err
+	}
 
+	// JSONをパース
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("設定のJSONパースに失敗しました: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Fetch は指定されたソース（ファイルパスまたはURL）からファイルの内容を読み込みます
+func (l *loader) Fetch(ctx context.Context, source string) ([]byte, error) {
 	// GitHub URLの場合はraw.githubusercontent.comに変換
 	// より厳密な判定: github.com/ と /blob/ の両方が含まれる場合のみ変換
 	if strings.Contains(source, "github.com/") && strings.Contains(source, "/blob/") {
@@ -43,30 +76,58 @@ func (l *loader) Load(ctx context.Context, source string) (*Config, error) {
 	}
 
 	// URLかローカルファイルかを判定
-	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
-		data, err = l.loadFromURL(ctx, source)
+	if isURL(source) {
+		data, err := l.loadFromURL(ctx, source)
 		if err != nil {
 			return nil, fmt.Errorf("URLから設定を読み込めませんでした: %w", err)
 		}
-	} else {
-		data, err = l.loadFromFile(source)
+		return data, nil
+	}
+
+	data, err := l.loadFromFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("ファイルから設定を読み込めませんでした: %w", err)
+	}
+	return data, nil
+}
+
+// ResolveSource は設定ファイルから参照されるファイル（プロンプトのテンプレートなど）の読み込み元を返します
+// refが相対パスの場合は、設定ファイル（base）と同じURLのディレクトリ・ローカルのディレクトリからの相対パスとして解決します
+func ResolveSource(base, ref string) string {
+	if isURL(ref) || base == "" {
+		return ref
+	}
+	if isURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return ref
+		}
+		refURL, err := url.Parse(filepath.ToSlash(ref))
 		if err != nil {
-			return nil, fmt.Errorf("ファイルから設定を読み込めませんでした: %w", err)
+			return ref
 		}
+		return baseURL.ResolveReference(refURL).String()
 	}
-
-	// JSONをパース
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("設定のJSONパースに失敗しました: %w", err)
+	if filepath.IsAbs(ref) {
+		return ref
 	}
+	return filepath.Join(filepath.Dir(base), ref)
+}
 
-	return &config, nil
+// isURL はソースがHTTP/HTTPSのURLかどうかを返します
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
 }
 
 // loadFromFile はローカルファイルから設定を読み込みます
 func (l *loader) loadFromFile(path string) ([]byte, error) {
-	data, err := os.ReadFile(path)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ファイル読み込みエラー: %w", err)
+	}
+	defer file.Close()
+
+	data, err := safehttp.ReadAll(file, MaxConfigSize)
 	if err != nil {
 		return nil, fmt.Errorf("ファイル読み込みエラー: %w", err)
 	}
@@ -90,7 +151,7 @@ func (l *loader) loadFromURL(ctx context.Context, url string) ([]byte, error) {
 		return nil, fmt.Errorf("HTTPステータスエラー: %d %s", resp.StatusCode, resp.Status)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := safehttp.ReadBody(resp, MaxConfigSize)
 	if err != nil {
 		return nil, fmt.Errorf("レスポンス読み込みエラー: %w", err)
 	}
@@ -148,3 +148,86 @@ func TestConvertToRawGitHubURL(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		ref      string
+		expected string
+	}{
+		{
+			name:     "ローカルの設定ファイルからの相対パス",
+			base:     "config/config.json",
+			ref:      "prompts/evaluation.tmpl",
+			expected: filepath.Join("config", "prompts", "evaluation.tmpl"),
+		},
+		{
+			name:     "絶対パス",
+			base:     "config/config.json",
+			ref:      "/etc/curator/evaluation.tmpl",
+			expected: "/etc/curator/evaluation.tmpl",
+		},
+		{
+			name:     "URLの設定ファイルからの相対パス",
+			base:     "https://example.com/curator/config.json",
+			ref:      "prompts/evaluation.tmpl",
+			expected: "https://example.com/curator/prompts/evaluation.tmpl",
+		},
+		{
+			name:     "GitHub blob URLからの相対パス",
+			base:     "https://github.com/user/repo/blob/main/config.json",
+			ref:      "prompts/evaluation.tmpl",
+			expected: "https://github.com/user/repo/blob/main/prompts/evaluation.tmpl",
+		},
+		{
+			name:     "URLの指定",
+			base:     "config.json",
+			ref:      "https://example.com/evaluation.tmpl",
+			expected: "https://example.com/evaluation.tmpl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ResolveSource(tt.base, tt.ref)
+			if result != tt.expected {
+				t.Errorf("解決結果が不正:\n期待=%s\n実際=%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLoader_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("remote template"))
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	localPath := filepath.Join(tmpDir, "evaluation.tmpl")
+	if err := os.WriteFile(localPath, []byte("local template"), 0644); err != nil {
+		t.Fatalf("テストファイルの作成に失敗: %v", err)
+	}
+
+	loader := NewLoader()
+	data, err := loader.Fetch(context.Background(), localPath)
+	if err != nil {
+		t.Fatalf("ローカルファイルの読み込みに失敗: %v", err)
+	}
+	if string(data) != "local template" {
+		t.Errorf("ローカルファイルの内容が不正: %s", data)
+	}
+
+	data, err = loader.Fetch(context.Background(), server.URL+"/evaluation.tmpl")
+	if err != nil {
+		t.Fatalf("URLからの読み込みに失敗: %v", err)
+	}
+	if string(data) != "remote template" {
+		t.Errorf("URLの内容が不正: %s", data)
+	}
+
+	if _, err := loader.Fetch(context.Background(), filepath.Join(tmpDir, "missing.tmpl")); err == nil {
+		t.Error("存在しないファイルでエラーが返されませんでした")
+	}
+}
@@ -1,20 +1,28 @@
 // Package config は設定ファイルの読み込み、検証、管理を提供します
 package config
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+)
 
 // RSSSource はRSSフィードソースを表します
 type RSSSource struct {
-	URL     string `json:"url" validate:"required,url"`
-	Name    string `json:"name" validate:"required,min=1,max=50"`
-	Enabled bool   `json:"enabled"`
+	URL       string   `json:"url" validate:"required,url"`
+	Name      string   `json:"name" validate:"required,min=1,max=50"`
+	Enabled   bool     `json:"enabled"`
+	Languages []string `json:"languages,omitempty" validate:"omitempty,dive,len=2,lowercase,alpha"` // 評価する記事の言語（ISO 639-1、空の場合はすべての言語）
 }
 
 // InterestTopic はユーザーの興味のあるトピックを表します
 type InterestTopic struct {
-	Topic    string   `json:"topic" validate:"required,min=1,max=50"`
-	Aliases  []string `json:"aliases,omitempty"`
-	Priority string   `json:"priority" validate:"required,oneof=high medium low"`
+	Topic     string   `json:"topic" validate:"required,min=1,max=50"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Priority  string   `json:"priority" validate:"required,oneof=high medium low"`
+	Languages []string `json:"languages,omitempty" validate:"omitempty,dive,len=2,lowercase,alpha"` // 評価する記事の言語（ISO 639-1、空の場合はすべての言語）
 }
 
 // GetPriorityMultiplier は優先度に応じたスコアの倍率を返します
@@ -46,12 +54,189 @@ type TimeoutSettings struct {
 	MaxTextLength              int `json:"max_text_length" validate:"required,min=1000,max=100000"`
 }
 
+// レスポンスサイズの上限のデフォルト値（KB）
+const (
+	DefaultRSSFeedMaxKB         = 5 * 1024  // 5MB
+	DefaultArticleHTMLMaxKB     = 10 * 1024 // 10MB
+	DefaultLLMResponseMaxKB     = 2 * 1024  // 2MB
+	DefaultDiscordResponseMaxKB = 1024      // 1MB
+)
+
+// SizeLimitSettings は外部から読み取るレスポンスボディの上限サイズ（KB）を表します
+// 0（未設定）の項目はデフォルト値を使用します
+type SizeLimitSettings struct {
+	RSSFeedMaxKB         int `json:"rss_feed_max_kb,omitempty" validate:"min=0,max=51200"`
+	ArticleHTMLMaxKB     int `json:"article_html_max_kb,omitempty" validate:"min=0,max=51200"`
+	LLMResponseMaxKB     int `json:"llm_response_max_kb,omitempty" validate:"min=0,max=51200"`
+	DiscordResponseMaxKB int `json:"discord_response_max_kb,omitempty" validate:"min=0,max=51200"`
+}
+
+// RSSFeedMaxBytes はRSSフィードの上限サイズ（バイト）を返します
+func (s *SizeLimitSettings) RSSFeedMaxBytes() int64 {
+	return kilobytes(s.RSSFeedMaxKB, DefaultRSSFeedMaxKB)
+}
+
+// ArticleHTMLMaxBytes は記事HTMLの上限サイズ（バイト）を返します
+func (s *SizeLimitSettings) ArticleHTMLMaxBytes() int64 {
+	return kilobytes(s.ArticleHTMLMaxKB, DefaultArticleHTMLMaxKB)
+}
+
+// LLMResponseMaxBytes はLLM APIのレスポンスの上限サイズ（バイト）を返します
+func (s *SizeLimitSettings) LLMResponseMaxBytes() int64 {
+	return kilobytes(s.LLMResponseMaxKB, DefaultLLMResponseMaxKB)
+}
+
+// DiscordResponseMaxBytes はDiscord Webhookのレスポンスの上限サイズ（バイト）を返します
+func (s *SizeLimitSettings) DiscordResponseMaxBytes() int64 {
+	return kilobytes(s.DiscordResponseMaxKB, DefaultDiscordResponseMaxKB)
+}
+
+// kilobytes はKB単位の設定値をバイト数に変換します（0以下はデフォルト値）
+func kilobytes(kb, defaultKB int) int64 {
+	if kb <= 0 {
+		kb = defaultKB
+	}
+	return int64(kb) * 1024
+}
+
+// DefaultRetentionDays は保持期間が設定されていない場合のデフォルト日数
+const DefaultRetentionDays = 30
+
+// DefaultEvaluationCacheDays は評価キャッシュの有効期間が設定されていない場合のデフォルト日数
+const DefaultEvaluationCacheDays = 7
+
+// RetentionSettings は通知済み・却下済み記事と評価記録の保持期間を表します
+// 0（未設定）の項目はデフォルト値または上位の設定にフォールバックします
+type RetentionSettings struct {
+	NotifiedDays        int            `json:"notified_days,omitempty" validate:"min=0,max=365"`
+	RejectedDays        int            `json:"rejected_days,omitempty" validate:"min=0,max=365"`
+	RejectedReasonDays  map[string]int `json:"rejected_reason_days,omitempty" validate:"dive,min=1,max=365"`
+	EvaluationDays      int            `json:"evaluation_days,omitempty" validate:"min=0,max=365"`
+	EvaluationCacheDays int            `json:"evaluation_cache_days,omitempty" validate:"min=0,max=365"`
+}
+
+// NotifiedRetention は通知済み記事の保持期間を返します
+func (r *RetentionSettings) NotifiedRetention() time.Duration {
+	return retentionDays(r.NotifiedDays)
+}
+
+// RejectedRetention は却下理由に応じた却下済み記事の保持期間を返します
+// 理由ごとの設定 → rejected_days → デフォルトの順に参照します
+func (r *RetentionSettings) RejectedRetention(reason string) time.Duration {
+	if days, ok := r.RejectedReasonDays[reason]; ok && days > 0 {
+		return retentionDays(days)
+	}
+	return retentionDays(r.RejectedDays)
+}
+
+// EvaluationRetention は評価記録の保持期間を返します
+func (r *RetentionSettings) EvaluationRetention() time.Duration {
+	return retentionDays(r.EvaluationDays)
+}
+
+// EvaluationCacheRetention は評価キャッシュの有効期間を返します
+func (r *RetentionSettings) EvaluationCacheRetention() time.Duration {
+	days := r.EvaluationCacheDays
+	if days <= 0 {
+		days = DefaultEvaluationCacheDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// retentionDays は日数を保持期間に変換します（0以下はデフォルト値）
+func retentionDays(days int) time.Duration {
+	if days <= 0 {
+		days = DefaultRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ReevaluationSettings は興味トピック変更時の却下済み記事の再評価に関する設定を表します
+type ReevaluationSettings struct {
+	// MaxRechecksPerRun は1回の実行で再評価する却下済み記事の最大数（0の場合は無制限）
+	MaxRechecksPerRun int `json:"max_rechecks_per_run" validate:"min=0,max=500"`
+}
+
+// LLMのバックエンドの種類
+const (
+	LLMProviderGemini = "gemini" // Gemini API（AI Studio）
+	LLMProviderOpenAI = "openai" // OpenAI互換のChat Completions API（OpenAI・llama.cpp・vLLMなど）
+	LLMProviderOllama = "ollama" // Ollama
+)
+
+// DefaultGeminiAPIKeySecret はGemini APIキーを保存しているSecret Managerのシークレット名
+const DefaultGeminiAPIKeySecret = "gemini-api-key"
+
+// LLMSettings は記事の評価・サマリー生成に使うLLMのバックエンドを表します
+// 省略した項目はバックエンドごとのデフォルト値（Geminiの場合は従来の設定）を使用します
+type LLMSettings struct {
+	Provider          string `json:"provider,omitempty" validate:"omitempty,oneof=gemini openai ollama"`
+	Model             string `json:"model,omitempty" validate:"max=100"`
+	Endpoint          string `json:"endpoint,omitempty" validate:"omitempty,url"`              // APIのベースURL
+	APIKeySecret      string `json:"api_key_secret,omitempty" validate:"max=100"`              // APIキーのシークレット名
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty" validate:"min=0,max=10000"` // 0の場合はバックエンドのデフォルト
+	MaxOutputTokens   int    `json:"max_output_tokens,omitempty" validate:"min=0,max=65536"`
+	TimeoutSeconds    int    `json:"timeout_seconds,omitempty" validate:"min=0,max=600"`
+	BatchSize         int    `json:"batch_size,omitempty" validate:"min=0,max=20"`              // 1回の呼び出しで評価する記事の最大数（0・1の場合は1記事ずつ）
+	BatchTokenBudget  int    `json:"batch_token_budget,omitempty" validate:"min=0,max=1000000"` // バッチ評価のプロンプトの推定トークン数の上限
+
+	DailyTokenBudget   int  `json:"daily_token_budget,omitempty" validate:"min=0,max=1000000000"` // 1日あたりの合計トークン数の上限（0の場合は無制限）
+	DailyRequestBudget int  `json:"daily_request_budget,omitempty" validate:"min=0,max=1000000"`  // 1日あたりのLLM呼び出し回数の上限（0の場合は無制限）
+	UsageFooter        bool `json:"usage_footer,omitempty"`                                       // ダイジェストのフッターにLLMの使用量を表示する
+}
+
+// ProviderName はバックエンドの種類を返します（未設定の場合はGemini）
+func (s *LLMSettings) ProviderName() string {
+	if s.Provider == "" {
+		return LLMProviderGemini
+	}
+	return s.Provider
+}
+
+// APIKeySecretName はAPIキーを取得するシークレット名を返します
+// Geminiは未設定の場合も従来のシークレットを使い、それ以外のバックエンドは設定した場合のみAPIキーを使います
+func (s *LLMSettings) APIKeySecretName() string {
+	if s.APIKeySecret == "" && s.ProviderName() == LLMProviderGemini {
+		return DefaultGeminiAPIKeySecret
+	}
+	return s.APIKeySecret
+}
+
+// PromptSettings はLLMのプロンプトのテンプレート（text/template）の読み込み元を表します
+// ファイルパスまたはURLを指定し、相対パスは設定ファイルからの相対パスとして解決します
+// 省略した項目は組み込みのプロンプトを使用します
+type PromptSettings struct {
+	Evaluation string `json:"evaluation,omitempty" validate:"max=2048"` // 記事の評価（1記事・バッチ評価）のプロンプト
+	Summary    string `json:"summary,omitempty" validate:"max=2048"`    // 記事全体のサマリーのプロンプト
+	Triage     string `json:"triage,omitempty" validate:"max=2048"`     // トリアージ（triage_settings.modeがllmの場合）のプロンプト
+}
+
+// トリアージの方式
+const (
+	TriageModeLLM     = "llm"     // タイトルとフィードの要約を複数まとめてLLMで判定する
+	TriageModeKeyword = "keyword" // タイトルとフィードの要約に興味トピック名・別名・キーワードを含むかで判定する
+)
+
+// TriageSettings は記事の本文を取得する前に、タイトルとフィードの要約で評価する記事を絞り込むトリアージの設定を表します
+// modeを省略した場合はトリアージを行わず、すべての記事の本文を取得して評価します
+type TriageSettings struct {
+	Mode      string   `json:"mode,omitempty" validate:"omitempty,oneof=llm keyword"`
+	BatchSize int      `json:"batch_size,omitempty" validate:"min=0,max=100"`            // llmで1回の呼び出しで判定する記事の最大数（0の場合はデフォルト）
+	Keywords  []string `json:"keywords,omitempty" validate:"max=100,dive,min=1,max=100"` // keywordで興味トピック名・別名に加えて一致を調べるキーワード
+}
+
 // Config はアプリケーション全体の設定を表します
 type Config struct {
 	RSSSources           []RSSSource          `json:"rss_sources" validate:"required,min=1,max=10,dive"`
 	Interests            []InterestTopic      `json:"interests" validate:"required,min=1,max=50,dive"`
 	NotificationSettings NotificationSettings `json:"notification_settings" validate:"required"`
 	TimeoutSettings      TimeoutSettings      `json:"timeout_settings" validate:"required"`
+	RetentionSettings    RetentionSettings    `json:"retention_settings"`
+	ReevaluationSettings ReevaluationSettings `json:"reevaluation_settings"`
+	SizeLimitSettings    SizeLimitSettings    `json:"size_limit_settings"`
+	LLMSettings          LLMSettings          `json:"llm_settings"`
+	PromptSettings       PromptSettings       `json:"prompt_settings"`
+	TriageSettings       TriageSettings       `json:"triage_settings"`
 }
 
 // GetEnabledSources は有効なRSSソースのみを返します
@@ -65,24 +250,248 @@ func (c *Config) GetEnabledSources() []RSSSource {
 	return enabled
 }
 
+// SourceAcceptsLanguage はソースの言語フィルターで、指定された言語の記事を評価するかどうかを返します
+// ソースが見つからない場合や、言語を判定できなかった場合（空文字列）は評価します
+func (c *Config) SourceAcceptsLanguage(sourceName, language string) bool {
+	for _, source := range c.RSSSources {
+		if source.Name == sourceName {
+			return MatchesLanguage(source.Languages, language)
+		}
+	}
+	return true
+}
+
+// TopicsForLanguage は指定された言語の記事の評価に使う興味トピックを返します
+// 言語フィルターが設定されたトピックは、その言語の記事のみで評価します
+func (c *Config) TopicsForLanguage(language string) []InterestTopic {
+	var topics []InterestTopic
+	for _, interest := range c.Interests {
+		if MatchesLanguage(interest.Languages, language) {
+			topics = append(topics, interest)
+		}
+	}
+	return topics
+}
+
+// MatchesLanguage は言語フィルターに言語が含まれるかどうかを返します
+// フィルターが空の場合や、言語を判定できなかった場合（空文字列）はtrueを返します
+func MatchesLanguage(languages []string, language string) bool {
+	if len(languages) == 0 || language == "" {
+		return true
+	}
+	for _, l := range languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// languageNames は主な言語コード（ISO 639-1）の表示名
+var languageNames = map[string]string{
+	"ja": "日本語",
+	"en": "英語",
+	"zh": "中国語",
+	"ko": "韓国語",
+	"ru": "ロシア語",
+	"de": "ドイツ語",
+	"fr": "フランス語",
+	"es": "スペイン語",
+	"pt": "ポルトガル語",
+}
+
+// LanguageName は言語コードの表示名を返します（表示名がない言語はコードをそのまま返します）
+func LanguageName(language string) string {
+	if name, ok := languageNames[language]; ok {
+		return name
+	}
+	return language
+}
+
+// InterestsHash は興味トピックの内容から算出したハッシュを返します
+// トピックの並び順には依存せず、トピック名・エイリアス・優先度・言語のいずれかが変わると値が変わります
+// ソースの言語フィルターによる却下（language_mismatch）も再評価の対象とするため、ソースの言語フィルターも含めます
+func (c *Config) InterestsHash() string {
+	interests := make([]InterestTopic, len(c.Interests))
+	copy(interests, c.Interests)
+	sort.Slice(interests, func(i, j int) bool {
+		return interests[i].Topic < interests[j].Topic
+	})
+
+	data, _ := json.Marshal(interests) // 構造体のマーシャルは常に成功する
+	// 言語フィルターを設定したソースがない場合は、興味トピックのみのハッシュと同じ値になる
+	if filters := c.sourceLanguageFilters(); len(filters) > 0 {
+		sourceData, _ := json.Marshal(filters) // マップのキーはソートされる
+		data = append(data, sourceData...)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// sourceLanguageFilters は言語フィルターを設定したソースの名前ごとの言語（ソート済み）を返します
+func (c *Config) sourceLanguageFilters() map[string][]string {
+	filters := make(map[string][]string)
+	for _, source := range c.RSSSources {
+		if len(source.Languages) == 0 {
+			continue
+		}
+		languages := append([]string(nil), source.Languages...)
+		sort.Strings(languages)
+		filters[source.Name] = languages
+	}
+	return filters
+}
+
 // Article はRSSフィードから取得した記事を表します
 type Article struct {
-	Title         string    `json:"title" validate:"required,min=5,max=500"`
-	URL           string    `json:"url" validate:"required,url"`
-	PublishedDate time.Time `json:"published_date,omitempty"`
-	SourceFeed    string    `json:"source_feed"`
-	ContentText   string    `json:"content_text,omitempty"`
-	FetchedAt     time.Time `json:"fetched_at"`
+	Title         string       `json:"title" validate:"required,min=5,max=500"`
+	URL           string       `json:"url" validate:"required,url"`
+	OriginalURL   string       `json:"original_url,omitempty"` // リダイレクト前のフィードのリンク（リダイレクトがない場合は空）
+	PublishedDate time.Time    `json:"published_date,omitempty"`
+	SourceFeed    string       `json:"source_feed"`
+	ContentText   string       `json:"content_text,omitempty"`
+	FetchedAt     time.Time    `json:"fetched_at"`
+	ContentStats  ContentStats `json:"content_stats"`
+
+	// 記事ページから抽出したメタデータ（Discordの埋め込み表示に使用）
+	Author             string `json:"author,omitempty"`
+	SiteName           string `json:"site_name,omitempty"`
+	ImageURL           string `json:"image_url,omitempty"`
+	ReadingTimeMinutes int    `json:"reading_time_minutes,omitempty"`
+	Language           string `json:"language,omitempty"` // 本文から判定した言語（ISO 639-1）
+
+	// Paywalled は有料記事・ログイン必須の記事のため、本文の代わりにフィードの要約を使用したことを表します
+	Paywalled bool `json:"paywalled,omitempty"`
+
+	// サイト固有の抽出（Zenn・Qiita・dev.to・note）で取得したタグといいね数
+	Tags      []string `json:"tags,omitempty"`
+	LikeCount int      `json:"like_count,omitempty"`
+}
+
+// ContentStats は抽出した記事本文の構造に関する統計情報を表します
+// プロンプトに含めることで、LLMがコード例や解説の構成を判断する材料にします
+type ContentStats struct {
+	CodeBlocks int `firestore:"code_blocks" json:"code_blocks"`
+	Headings   int `firestore:"headings" json:"headings"`
+	Links      int `firestore:"links" json:"links"`
 }
 
 // ArticleEvaluation はLLMによる記事の評価結果を表します
 type ArticleEvaluation struct {
-	ArticleURL     string    `json:"article_url" validate:"required,url"`
-	RelevanceScore int       `json:"relevance_score" validate:"min=0,max=100"`
-	MatchingTopics []string  `json:"matching_topics"`
-	Summary        string    `json:"summary" validate:"required,min=50,max=200"`
-	EvaluatedAt    time.Time `json:"evaluated_at"`
-	IsRelevant     bool      `json:"is_relevant"`
+	ArticleURL       string     `json:"article_url" validate:"required,url"`
+	RelevanceScore   int        `json:"relevance_score" validate:"min=0,max=100"` // LLMによるスコア（通知の閾値に使用）
+	RankingScore     int        `json:"ranking_score" validate:"min=0,max=100"`   // LLMのスコアに一致トピックの優先度の倍率を掛けたスコア（通知する記事の順位付けに使用）
+	ScoreExplanation string     `json:"score_explanation,omitempty"`              // ランキングスコアの計算の説明
+	MatchingTopics   []string   `json:"matching_topics"`                          // 興味トピックの正式名に正規化した一致トピック
+	Summary          string     `json:"summary" validate:"required,min=50,max=200"`
+	EvaluatedAt      time.Time  `json:"evaluated_at"`
+	IsRelevant       bool       `json:"is_relevant"`
+	Reasoning        string     `json:"reasoning,omitempty"`
+	IsAIGenerated    bool       `json:"is_ai_generated"`
+	PromptVersion    string     `json:"prompt_version,omitempty"`
+	Model            string     `json:"model,omitempty"`
+	TokenUsage       TokenUsage `json:"token_usage"`
+	ContentLength    int        `json:"content_length"`   // 評価対象の本文の文字数（切り詰め前）
+	Cached           bool       `json:"cached,omitempty"` // 評価キャッシュの結果を使用した（LLMを呼び出していない）
+}
+
+// TokenUsage はLLM呼び出し1回分のトークン使用量を表します
+type TokenUsage struct {
+	PromptTokens     int `firestore:"prompt_tokens" json:"prompt_tokens"`
+	CandidatesTokens int `firestore:"candidates_tokens" json:"candidates_tokens"`
+	TotalTokens      int `firestore:"total_tokens" json:"total_tokens"`
+}
+
+// Add はトークン使用量を合計した値を返します
+func (u TokenUsage) Add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CandidatesTokens: u.CandidatesTokens + other.CandidatesTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+	}
+}
+
+// DailyUsage は1日分のLLMの使用量の合計を表します（Firestore保存用、ドキュメントIDは日付）
+type DailyUsage struct {
+	Date             string    `firestore:"date" json:"date"` // YYYY-MM-DD
+	PromptTokens     int       `firestore:"prompt_tokens" json:"prompt_tokens"`
+	CandidatesTokens int       `firestore:"candidates_tokens" json:"candidates_tokens"`
+	TotalTokens      int       `firestore:"total_tokens" json:"total_tokens"`
+	Requests         int       `firestore:"requests" json:"requests"` // LLMの呼び出し回数
+	UpdatedAt        time.Time `firestore:"updated_at" json:"updated_at"`
+}
+
+// EvaluationRecord はLLMによる評価結果の全体を表します（Firestore保存用）
+// スコアの根拠を後から監査できるよう、通知・却下にかかわらずすべての評価を記録します
+type EvaluationRecord struct {
+	ArticleURL       string       `firestore:"article_url" json:"article_url"`
+	ArticleTitle     string       `firestore:"article_title" json:"article_title"`
+	OriginalURL      string       `firestore:"original_url,omitempty" json:"original_url,omitempty"` // リダイレクト前のフィードのリンク
+	SourceFeed       string       `firestore:"source_feed,omitempty" json:"source_feed,omitempty"`
+	EvaluatedAt      time.Time    `firestore:"evaluated_at" json:"evaluated_at"`
+	RelevanceScore   int          `firestore:"relevance_score" json:"relevance_score"`
+	RankingScore     int          `firestore:"ranking_score" json:"ranking_score"`
+	ScoreExplanation string       `firestore:"score_explanation,omitempty" json:"score_explanation,omitempty"`
+	IsRelevant       bool         `firestore:"is_relevant" json:"is_relevant"`
+	MatchingTopics   []string     `firestore:"matching_topics" json:"matching_topics"`
+	Summary          string       `firestore:"summary" json:"summary"`
+	Reasoning        string       `firestore:"reasoning" json:"reasoning"`
+	IsAIGenerated    bool         `firestore:"is_ai_generated" json:"is_ai_generated"`
+	PromptVersion    string       `firestore:"prompt_version" json:"prompt_version"`
+	InterestsHash    string       `firestore:"interests_hash,omitempty" json:"interests_hash,omitempty"`
+	Model            string       `firestore:"model" json:"model"`
+	TokenUsage       TokenUsage   `firestore:"token_usage" json:"token_usage"`
+	ContentLength    int          `firestore:"content_length" json:"content_length"`
+	ContentStats     ContentStats `firestore:"content_stats" json:"content_stats"`
+	Paywalled        bool         `firestore:"paywalled,omitempty" json:"paywalled,omitempty"` // フィードの要約のみで評価した有料記事
+	Language         string       `firestore:"language,omitempty" json:"language,omitempty"`   // 本文から判定した言語（ISO 639-1）
+	Cached           bool         `firestore:"cached,omitempty" json:"cached,omitempty"`       // 評価キャッシュの結果を使用した評価
+	ExpireAt         time.Time    `firestore:"expire_at,omitempty" json:"expire_at,omitempty"` // FirestoreネイティブTTLの削除対象日時
+}
+
+// NewEvaluationRecord は記事と評価結果から保存用の評価記録を作成します
+func NewEvaluationRecord(article *Article, evaluation *ArticleEvaluation) *EvaluationRecord {
+	return &EvaluationRecord{
+		ArticleURL:       evaluation.ArticleURL,
+		ArticleTitle:     article.Title,
+		OriginalURL:      article.OriginalURL,
+		SourceFeed:       article.SourceFeed,
+		EvaluatedAt:      evaluation.EvaluatedAt,
+		RelevanceScore:   evaluation.RelevanceScore,
+		RankingScore:     evaluation.RankingScore,
+		ScoreExplanation: evaluation.ScoreExplanation,
+		IsRelevant:       evaluation.IsRelevant,
+		MatchingTopics:   evaluation.MatchingTopics,
+		Summary:          evaluation.Summary,
+		Reasoning:        evaluation.Reasoning,
+		IsAIGenerated:    evaluation.IsAIGenerated,
+		PromptVersion:    evaluation.PromptVersion,
+		Model:            evaluation.Model,
+		TokenUsage:       evaluation.TokenUsage,
+		ContentLength:    evaluation.ContentLength,
+		ContentStats:     article.ContentStats,
+		Paywalled:        article.Paywalled,
+		Language:         article.Language,
+		Cached:           evaluation.Cached,
+	}
+}
+
+// CachedEvaluation はLLMによる評価結果のキャッシュを表します（Firestore保存用）
+// 同じ本文・興味トピック・プロンプトのバージョン・モデルの組み合わせでの再評価を避けるために使用します
+// スコアの正規化と関連性の判定は評価のたびに行うため、LLMの出力をそのまま保持します
+type CachedEvaluation struct {
+	ContentHash    string     `firestore:"content_hash" json:"content_hash"` // 評価した本文のSHA-256
+	InterestsHash  string     `firestore:"interests_hash" json:"interests_hash"`
+	PromptVersion  string     `firestore:"prompt_version" json:"prompt_version"`
+	Model          string     `firestore:"model" json:"model"`
+	RelevanceScore int        `firestore:"relevance_score" json:"relevance_score"`
+	MatchingTopics []string   `firestore:"matching_topics" json:"matching_topics"`
+	Summary        string     `firestore:"summary" json:"summary"`
+	Reasoning      string     `firestore:"reasoning" json:"reasoning"`
+	IsAIGenerated  bool       `firestore:"is_ai_generated" json:"is_ai_generated"`
+	TokenUsage     TokenUsage `firestore:"token_usage" json:"token_usage"` // キャッシュ作成時の評価で使用したトークン数
+	CachedAt       time.Time  `firestore:"cached_at" json:"cached_at"`
+	ExpireAt       time.Time  `firestore:"expire_at,omitempty" json:"expire_at,omitempty"` // FirestoreネイティブTTLの削除対象日時
 }
 
 // NotifiedArticle はDiscordに通知済みの記事を表します（Firestore保存用）
@@ -91,13 +500,17 @@ type NotifiedArticle struct {
 	DiscordMessageID string    `firestore:"discord_message_id"`
 	ArticleTitle     string    `firestore:"article_title"`
 	RelevanceScore   int       `firestore:"relevance_score"`
+	ExpireAt         time.Time `firestore:"expire_at,omitempty"` // FirestoreネイティブTTLの削除対象日時
 }
 
 // RejectedArticle は却下された記事を表します（Firestore保存用）
 type RejectedArticle struct {
 	EvaluatedAt    time.Time `firestore:"evaluated_at"`
-	Reason         string    `firestore:"reason"` // "low_relevance" | "no_topic_match" | "content_extraction_failed"
+	Reason         string    `firestore:"reason"` // "low_relevance" | "no_topic_match" | "content_extraction_failed" | "robots_disallowed" | "blocked_destination" | "paywalled" | "duplicate" | "language_mismatch" | "triage_rejected"
 	RelevanceScore *int      `firestore:"relevance_score,omitempty"`
+	ExpireAt       time.Time `firestore:"expire_at,omitempty"`      // FirestoreネイティブTTLの削除対象日時
+	InterestsHash  string    `firestore:"interests_hash,omitempty"` // 評価時の興味トピックのハッシュ
+	PromptVersion  string    `firestore:"prompt_version,omitempty"` // 評価時のプロンプトバージョン
 }
 
 // RejectedArticleReason は記事が却下された理由を表す定数
@@ -105,4 +518,65 @@ const (
 	ReasonLowRelevance            = "low_relevance"
 	ReasonNoTopicMatch            = "no_topic_match"
 	ReasonContentExtractionFailed = "content_extraction_failed"
+	ReasonRobotsDisallowed        = "robots_disallowed"
+	ReasonBlockedDestination      = "blocked_destination"
+	ReasonPaywalled               = "paywalled"
+	ReasonDuplicate               = "duplicate"
+	ReasonLanguageMismatch        = "language_mismatch"
+	ReasonTriageRejected          = "triage_rejected" // 本文を取得する前のトリアージで除外した
+)
+
+// RejectionReasons は定義済みの却下理由の一覧です
+var RejectionReasons = []string{
+	ReasonLowRelevance,
+	ReasonNoTopicMatch,
+	ReasonContentExtractionFailed,
+	ReasonRobotsDisallowed,
+	ReasonBlockedDestination,
+	ReasonPaywalled,
+	ReasonDuplicate,
+	ReasonLanguageMismatch,
+	ReasonTriageRejected,
+}
+
+// IsEvaluationRejection は却下理由がLLMの評価結果または興味トピックの設定によるものかどうかを返します
+// これらの却下は興味トピックやプロンプトが変わると再評価の対象になります
+func IsEvaluationRejection(reason string) bool {
+	return reason == ReasonLowRelevance || reason == ReasonNoTopicMatch || reason == ReasonLanguageMismatch || reason == ReasonTriageRejected
+}
+
+// IsValidRejectionReason は却下理由が定義済みかどうかを返します
+func IsValidRejectionReason(reason string) bool {
+	for _, r := range RejectionReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// RunStatus は実行履歴のステータスを表す定数
+const (
+	RunStatusSuccess = "success"
+	RunStatusFailed  = "failed"
 )
+
+// RunHistory はキュレーション処理1回分の実行履歴を表します（Firestore保存用）
+type RunHistory struct {
+	StartedAt        time.Time  `firestore:"started_at" json:"started_at"`
+	FinishedAt       time.Time  `firestore:"finished_at" json:"finished_at"`
+	Status           string     `firestore:"status" json:"status"` // "success" | "failed"
+	FetchedCount     int        `firestore:"fetched_count" json:"fetched_count"`
+	FilteredCount    int        `firestore:"filtered_count" json:"filtered_count"`
+	EvaluatedCount   int        `firestore:"evaluated_count" json:"evaluated_count"`
+	NotifiedCount    int        `firestore:"notified_count" json:"notified_count"`
+	RecheckedCount   int        `firestore:"rechecked_count" json:"rechecked_count"`                       // 評価条件の変更により再評価した却下済み記事数
+	CacheHits        int        `firestore:"cache_hits" json:"cache_hits"`                                 // 評価キャッシュを使用した記事数
+	CacheMisses      int        `firestore:"cache_misses" json:"cache_misses"`                             // 評価キャッシュになくLLMで評価した記事数
+	TriageRejected   int        `firestore:"triage_rejected" json:"triage_rejected"`                       // 本文を取得する前のトリアージで除外した記事数
+	TokenUsage       TokenUsage `firestore:"token_usage" json:"token_usage"`                               // この実行でのLLMのトークン使用量
+	LLMRequests      int        `firestore:"llm_requests" json:"llm_requests"`                             // この実行でのLLMの呼び出し回数
+	BudgetExhausted  bool       `firestore:"budget_exhausted,omitempty" json:"budget_exhausted,omitempty"` // 1日あたりの使用量の上限に達して評価を中止した
+	DiscordMessageID string     `firestore:"discord_message_id,omitempty" json:"discord_message_id,omitempty"`
+	ErrorMessage     string     `firestore:"error_message,omitempty" json:"error_message,omitempty"`
+}
@@ -71,6 +71,13 @@ func (v *configValidator) Validate(config *Config) error {
 			config.NotificationSettings.MaxArticles)
 	}
 
+	// カスタムバリデーション: 理由ごとの保持期間は定義済みの却下理由のみ指定可能
+	for reason := range config.RetentionSettings.RejectedReasonDays {
+		if !IsValidRejectionReason(reason) {
+			return fmt.Errorf("未知の却下理由に保持期間が指定されています: %s", reason)
+		}
+	}
+
 	return nil
 }
 
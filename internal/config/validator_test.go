@@ -116,6 +116,128 @@ func TestValidator_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "min_articles (5) はmax_articles (3) 以下である必要があります",
 		},
+		{
+			name: "未知の却下理由に保持期間を指定",
+			config: &Config{
+				RSSSources: []RSSSource{
+					{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true},
+				},
+				Interests: []InterestTopic{
+					{Topic: "Go", Priority: "high"},
+				},
+				NotificationSettings: NotificationSettings{
+					MaxArticles:       5,
+					MinArticles:       3,
+					MinRelevanceScore: 70,
+				},
+				TimeoutSettings: TimeoutSettings{
+					RSSFetchTimeoutSeconds:     10,
+					ArticleFetchTimeoutSeconds: 10,
+					MinTextLength:              100,
+					MaxTextLength:              50000,
+				},
+				RetentionSettings: RetentionSettings{
+					RejectedReasonDays: map[string]int{"unknown_reason": 7},
+				},
+			},
+			wantErr: true,
+			errMsg:  "未知の却下理由に保持期間が指定されています: unknown_reason",
+		},
+		{
+			name: "未知のLLMバックエンド",
+			config: &Config{
+				RSSSources: []RSSSource{
+					{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true},
+				},
+				Interests: []InterestTopic{
+					{Topic: "Go", Priority: "high"},
+				},
+				NotificationSettings: NotificationSettings{
+					MaxArticles:       5,
+					MinArticles:       3,
+					MinRelevanceScore: 70,
+				},
+				TimeoutSettings: TimeoutSettings{
+					RSSFetchTimeoutSeconds:     10,
+					ArticleFetchTimeoutSeconds: 10,
+					MinTextLength:              100,
+					MaxTextLength:              50000,
+				},
+				LLMSettings: LLMSettings{Provider: "anthropic"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "1日あたりのトークン数の上限が負",
+			config: &Config{
+				RSSSources: []RSSSource{
+					{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true},
+				},
+				Interests: []InterestTopic{
+					{Topic: "Go", Priority: "high"},
+				},
+				NotificationSettings: NotificationSettings{
+					MaxArticles:       5,
+					MinArticles:       3,
+					MinRelevanceScore: 70,
+				},
+				TimeoutSettings: TimeoutSettings{
+					RSSFetchTimeoutSeconds:     10,
+					ArticleFetchTimeoutSeconds: 10,
+					MinTextLength:              100,
+					MaxTextLength:              50000,
+				},
+				LLMSettings: LLMSettings{DailyTokenBudget: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "トリアージの方式が不正",
+			config: &Config{
+				RSSSources: []RSSSource{
+					{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true},
+				},
+				Interests: []InterestTopic{
+					{Topic: "Go", Priority: "high"},
+				},
+				NotificationSettings: NotificationSettings{
+					MaxArticles:       5,
+					MinArticles:       3,
+					MinRelevanceScore: 70,
+				},
+				TimeoutSettings: TimeoutSettings{
+					RSSFetchTimeoutSeconds:     10,
+					ArticleFetchTimeoutSeconds: 10,
+					MinTextLength:              100,
+					MaxTextLength:              50000,
+				},
+				TriageSettings: TriageSettings{Mode: "embedding"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "言語コードが不正",
+			config: &Config{
+				RSSSources: []RSSSource{
+					{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true, Languages: []string{"English"}},
+				},
+				Interests: []InterestTopic{
+					{Topic: "Go", Priority: "high"},
+				},
+				NotificationSettings: NotificationSettings{
+					MaxArticles:       5,
+					MinArticles:       3,
+					MinRelevanceScore: 70,
+				},
+				TimeoutSettings: TimeoutSettings{
+					RSSFetchTimeoutSeconds:     10,
+					ArticleFetchTimeoutSeconds: 10,
+					MinTextLength:              100,
+					MaxTextLength:              50000,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -260,3 +382,177 @@ func TestInterestTopic_GetPriorityMultiplier(t *testing.T) {
 		})
 	}
 }
+
+func TestSizeLimitSettings(t *testing.T) {
+	settings := &SizeLimitSettings{RSSFeedMaxKB: 100}
+	if got := settings.RSSFeedMaxBytes(); got != 100*1024 {
+		t.Errorf("RSSFeedMaxBytes() = %d, 期待 %d", got, 100*1024)
+	}
+
+	// 未設定の場合はデフォルト値
+	if got := settings.ArticleHTMLMaxBytes(); got != DefaultArticleHTMLMaxKB*1024 {
+		t.Errorf("ArticleHTMLMaxBytes() = %d, 期待 %d", got, DefaultArticleHTMLMaxKB*1024)
+	}
+	if got := settings.LLMResponseMaxBytes(); got != DefaultLLMResponseMaxKB*1024 {
+		t.Errorf("LLMResponseMaxBytes() = %d, 期待 %d", got, DefaultLLMResponseMaxKB*1024)
+	}
+	if got := settings.DiscordResponseMaxBytes(); got != DefaultDiscordResponseMaxKB*1024 {
+		t.Errorf("DiscordResponseMaxBytes() = %d, 期待 %d", got, DefaultDiscordResponseMaxKB*1024)
+	}
+}
+
+func TestRetentionSettings(t *testing.T) {
+	day := 24 * time.Hour
+
+	settings := &RetentionSettings{
+		NotifiedDays: 60,
+		RejectedDays: 14,
+		RejectedReasonDays: map[string]int{
+			ReasonContentExtractionFailed: 3,
+		},
+		EvaluationDays: 90,
+	}
+
+	if got := settings.NotifiedRetention(); got != 60*day {
+		t.Errorf("NotifiedRetention() = %v, 期待 %v", got, 60*day)
+	}
+	if got := settings.RejectedRetention(ReasonContentExtractionFailed); got != 3*day {
+		t.Errorf("RejectedRetention(content_extraction_failed) = %v, 期待 %v", got, 3*day)
+	}
+	if got := settings.RejectedRetention(ReasonLowRelevance); got != 14*day {
+		t.Errorf("RejectedRetention(low_relevance) = %v, 期待 %v", got, 14*day)
+	}
+	if got := settings.EvaluationRetention(); got != 90*day {
+		t.Errorf("EvaluationRetention() = %v, 期待 %v", got, 90*day)
+	}
+
+	// 未設定の場合はデフォルト値
+	empty := &RetentionSettings{}
+	if got := empty.NotifiedRetention(); got != DefaultRetentionDays*day {
+		t.Errorf("NotifiedRetention() = %v, 期待 %v", got, DefaultRetentionDays*day)
+	}
+	if got := empty.RejectedRetention(ReasonNoTopicMatch); got != DefaultRetentionDays*day {
+		t.Errorf("RejectedRetention() = %v, 期待 %v", got, DefaultRetentionDays*day)
+	}
+	if got := empty.EvaluationCacheRetention(); got != DefaultEvaluationCacheDays*day {
+		t.Errorf("EvaluationCacheRetention() = %v, 期待 %v", got, DefaultEvaluationCacheDays*day)
+	}
+}
+
+func TestConfig_InterestsHash(t *testing.T) {
+	base := &Config{
+		Interests: []InterestTopic{
+			{Topic: "Go", Aliases: []string{"Golang"}, Priority: "high"},
+			{Topic: "Kubernetes", Priority: "medium"},
+		},
+	}
+
+	// 並び順が異なっても同じハッシュになる
+	reordered := &Config{
+		Interests: []InterestTopic{
+			{Topic: "Kubernetes", Priority: "medium"},
+			{Topic: "Go", Aliases: []string{"Golang"}, Priority: "high"},
+		},
+	}
+	if base.InterestsHash() != reordered.InterestsHash() {
+		t.Error("並び順の違いでハッシュが変わりました")
+	}
+
+	// トピックを追加するとハッシュが変わる
+	added := &Config{
+		Interests: append([]InterestTopic{{Topic: "Rust", Priority: "high"}}, base.Interests...),
+	}
+	if base.InterestsHash() == added.InterestsHash() {
+		t.Error("トピックを追加してもハッシュが変わりませんでした")
+	}
+
+	// 優先度を変えるとハッシュが変わる
+	changed := &Config{
+		Interests: []InterestTopic{
+			{Topic: "Go", Aliases: []string{"Golang"}, Priority: "low"},
+			{Topic: "Kubernetes", Priority: "medium"},
+		},
+	}
+	if base.InterestsHash() == changed.InterestsHash() {
+		t.Error("優先度を変更してもハッシュが変わりませんでした")
+	}
+}
+
+func TestConfig_LanguageFilters(t *testing.T) {
+	cfg := &Config{
+		RSSSources: []RSSSource{
+			{URL: "https://zenn.dev/feed", Name: "Zenn", Enabled: true, Languages: []string{"ja"}},
+			{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true},
+		},
+		Interests: []InterestTopic{
+			{Topic: "Go", Priority: "high"},
+			{Topic: "個人開発", Priority: "medium", Languages: []string{"ja"}},
+			{Topic: "Rust", Priority: "low", Languages: []string{"en", "ja"}},
+		},
+	}
+
+	sourceTests := []struct {
+		source   string
+		language string
+		want     bool
+	}{
+		{"Zenn", "ja", true},
+		{"Zenn", "en", false},
+		{"Zenn", "", true}, // 言語を判定できない場合は評価する
+		{"Dev.to", "ko", true},
+		{"未登録", "en", true},
+	}
+	for _, tt := range sourceTests {
+		if got := cfg.SourceAcceptsLanguage(tt.source, tt.language); got != tt.want {
+			t.Errorf("SourceAcceptsLanguage(%q, %q) = %v, 期待 %v", tt.source, tt.language, got, tt.want)
+		}
+	}
+
+	topicTests := []struct {
+		language string
+		want     string
+	}{
+		{"ja", "Go,個人開発,Rust"},
+		{"en", "Go,Rust"},
+		{"ko", "Go"},
+		{"", "Go,個人開発,Rust"},
+	}
+	for _, tt := range topicTests {
+		var names []string
+		for _, topic := range cfg.TopicsForLanguage(tt.language) {
+			names = append(names, topic.Topic)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("TopicsForLanguage(%q) = %q, 期待 %q", tt.language, got, tt.want)
+		}
+	}
+
+	// 言語フィルターを変えると興味トピックのハッシュが変わる
+	changed := &Config{Interests: append([]InterestTopic(nil), cfg.Interests...)}
+	changed.Interests[0].Languages = []string{"en"}
+	if cfg.InterestsHash() == changed.InterestsHash() {
+		t.Error("言語フィルターを変更してもハッシュが変わりませんでした")
+	}
+
+	// ソースの言語フィルターを変えた場合もハッシュが変わる（言語の並び順には依存しない）
+	sourceChanged := &Config{RSSSources: append([]RSSSource(nil), cfg.RSSSources...), Interests: cfg.Interests}
+	sourceChanged.RSSSources[0].Languages = []string{"ja", "en"}
+	if cfg.InterestsHash() == sourceChanged.InterestsHash() {
+		t.Error("ソースの言語フィルターを変更してもハッシュが変わりませんでした")
+	}
+	reordered := &Config{RSSSources: append([]RSSSource(nil), sourceChanged.RSSSources...), Interests: cfg.Interests}
+	reordered.RSSSources[0].Languages = []string{"en", "ja"}
+	if sourceChanged.InterestsHash() != reordered.InterestsHash() {
+		t.Error("ソースの言語の並び順でハッシュが変わりました")
+	}
+
+	// 言語フィルターのないソースは興味トピックのハッシュに影響しない
+	noFilter := &Config{RSSSources: []RSSSource{{URL: "https://dev.to/feed", Name: "Dev.to", Enabled: true}}, Interests: cfg.Interests}
+	if noFilter.InterestsHash() != (&Config{Interests: cfg.Interests}).InterestsHash() {
+		t.Error("言語フィルターのないソースでハッシュが変わりました")
+	}
+
+	if !IsEvaluationRejection(ReasonLanguageMismatch) {
+		t.Error("言語フィルターによる却下が再評価の対象になっていません")
+	}
+}
@@ -5,18 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
+	"github.com/kaka0913/discord-article-bot/internal/config"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
 )
 
 // Client は Discord Webhook API クライアント
 type Client struct {
-	webhookURL string
-	httpClient *http.Client
-	logger     logging.Logger
+	webhookURL      string
+	httpClient      *http.Client
+	logger          logging.Logger
+	maxResponseSize int64 // レスポンスボディの上限サイズ（バイト）
 }
 
 // NewClient は新しいDiscordクライアントを作成
@@ -26,18 +28,31 @@ func NewClient(webhookURL string, logger logging.Logger) *Client {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		logger: logger,
+		logger:          logger,
+		maxResponseSize: int64(config.DefaultDiscordResponseMaxKB) * 1024,
 	}
 }
 
+// SetMaxResponseSize はレスポンスボディの上限サイズ（バイト）を設定
+func (c *Client) SetMaxResponseSize(maxSize int64) {
+	c.maxResponseSize = maxSize
+}
+
 // Article はキュレーションされた記事を表す
 type Article struct {
-	Title       string
-	Description string
-	URL         string
-	Relevance   int
-	Topics      []string
-	Source      string
+	Title              string
+	Description        string
+	URL                string
+	Relevance          int
+	Topics             []string
+	Source             string
+	SiteName           string    // 記事ページのサイト名（空の場合は表示しない）
+	Author             string    // 著者名（空の場合は表示しない）
+	ThumbnailURL       string    // サムネイル画像のURL（空の場合は表示しない）
+	PublishedAt        time.Time // 公開日時（ゼロ値の場合は表示しない）
+	ReadingTimeMinutes int       // 推定読了時間（0の場合は表示しない）
+	Paywalled          bool      // 有料記事（フィードの要約のみで評価した記事）
+	Language           string    // 記事の言語（ISO 639-1、空の場合は表示しない）
 }
 
 // WebhookPayload はDiscord Webhook APIのリクエストペイロード
@@ -54,6 +69,9 @@ type EmbedObject struct {
 	Color       int           `json:"color,omitempty"`
 	Fields      []EmbedField  `json:"fields,omitempty"`
 	Footer      *EmbedFooter  `json:"footer,omitempty"`
+	Author      *EmbedAuthor    `json:"author,omitempty"`
+	Thumbnail   *EmbedThumbnail `json:"thumbnail,omitempty"`
+	Timestamp   string          `json:"timestamp,omitempty"` // ISO 8601形式
 }
 
 // EmbedField はEmbedsのフィールド
@@ -68,6 +86,16 @@ type EmbedFooter struct {
 	Text string `json:"text"`
 }
 
+// EmbedAuthor はEmbedsの著者欄
+type EmbedAuthor struct {
+	Name string `json:"name"`
+}
+
+// EmbedThumbnail はEmbedsのサムネイル画像
+type EmbedThumbnail struct {
+	URL string `json:"url"`
+}
+
 // WebhookResponse はDiscord Webhook APIのレスポンス
 type WebhookResponse struct {
 	ID        string          `json:"id"`
@@ -172,8 +200,8 @@ func (c *Client) post(ctx context.Context, payload WebhookPayload) (string, erro
 	}
 	defer resp.Body.Close()
 
-	// レスポンスボディを読み込み
-	body, err := io.ReadAll(resp.Body)
+	// レスポンスボディを上限サイズまで読み込み
+	body, err := safehttp.ReadBody(resp, c.maxResponseSize)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -247,6 +275,10 @@ func isRateLimitError(err error) bool {
 
 // isFatalError は致命的なエラーかどうかをチェック（リトライ不要）
 func isFatalError(err error) bool {
+	// レスポンスが大きすぎる場合は送信済みの可能性があるため、重複投稿を避けてリトライしない
+	if safehttp.IsTooLarge(err) {
+		return true
+	}
 	if apiErr, ok := err.(*DiscordAPIError); ok {
 		// 404 Not Found (無効なwebhook)
 		if apiErr.StatusCode == http.StatusNotFound {
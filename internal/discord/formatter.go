@@ -3,6 +3,9 @@ package discord
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
 )
 
 const (
@@ -12,6 +15,7 @@ const (
 	maxFieldNameLength   = 256
 	maxFieldValueLength  = 1024
 	maxFooterLength      = 2048
+	maxAuthorNameLength  = 256
 
 	// デフォルトのEmbed色（#58A5EF = 5814783）
 	defaultEmbedColor = 5814783
@@ -22,6 +26,22 @@ type ArticlesSummary struct {
 	OverallSummary  string
 	MustRead        string
 	Recommendations []string
+	Footer          string // LLMの使用量など（空の場合はフッターを表示しない）
+}
+
+// FormatUsageFooter はダイジェストのフッターに表示するLLMの使用量（この実行と当日の合計）をフォーマット
+// 1日あたりの上限が設定されている場合は上限も併記する
+func FormatUsageFooter(runUsage config.TokenUsage, runRequests int, daily config.DailyUsage, settings config.LLMSettings) string {
+	today := fmt.Sprintf("%d", daily.TotalTokens)
+	if settings.DailyTokenBudget > 0 {
+		today = fmt.Sprintf("%d/%d", daily.TotalTokens, settings.DailyTokenBudget)
+	}
+	todayRequests := fmt.Sprintf("%d", daily.Requests)
+	if settings.DailyRequestBudget > 0 {
+		todayRequests = fmt.Sprintf("%d/%d", daily.Requests, settings.DailyRequestBudget)
+	}
+	return fmt.Sprintf("LLM usage: %d tokens, %d requests | Today: %s tokens, %s requests",
+		runUsage.TotalTokens, runRequests, today, todayRequests)
 }
 
 // FormatArticlesPayload は記事リストをDiscord Webhook用のペイロードにフォーマット
@@ -73,11 +93,15 @@ func formatSummaryEmbed(summary *ArticlesSummary, articleCount int) EmbedObject
 	description := strings.Join(descriptionParts, "\n")
 	description = truncateString(description, maxDescriptionLength)
 
-	return EmbedObject{
+	embed := EmbedObject{
 		Title:       fmt.Sprintf("📊 本日の厳選記事 (%d件)", articleCount),
 		Description: description,
 		Color:       0x3498db, // 青色（#3498db = 3447003）
 	}
+	if summary.Footer != "" {
+		embed.Footer = &EmbedFooter{Text: truncateString(summary.Footer, maxFooterLength)}
+	}
+	return embed
 }
 
 // formatArticleEmbed は個別の記事をEmbedオブジェクトにフォーマット
@@ -108,12 +132,43 @@ func formatArticleEmbed(article Article) EmbedObject {
 		},
 	}
 
-	// フッターを作成
+	// 読了時間（推定できた場合のみ）
+	if article.ReadingTimeMinutes > 0 {
+		fields = append(fields, EmbedField{
+			Name:   "Reading Time",
+			Value:  fmt.Sprintf("⏱ %d min read", article.ReadingTimeMinutes),
+			Inline: true,
+		})
+	}
+
+	// 記事の言語（判定できた場合のみ）
+	if article.Language != "" {
+		fields = append(fields, EmbedField{
+			Name:   "Language",
+			Value:  fmt.Sprintf("🌐 %s", config.LanguageName(article.Language)),
+			Inline: true,
+		})
+	}
+
+	// 有料記事のタグ（本文を読まずにフィードの要約のみで評価したことを示す）
+	if article.Paywalled {
+		fields = append(fields, EmbedField{
+			Name:   "Tags",
+			Value:  "🔒 paywalled",
+			Inline: true,
+		})
+	}
+
+	// フッターを作成（サイト名がフィード名と異なる場合は併記）
+	source := article.Source
+	if article.SiteName != "" && article.SiteName != article.Source {
+		source = fmt.Sprintf("%s (%s)", article.Source, article.SiteName)
+	}
 	footer := &EmbedFooter{
-		Text: truncateString(fmt.Sprintf("Source: %s", article.Source), maxFooterLength),
+		Text: truncateString(fmt.Sprintf("Source: %s", source), maxFooterLength),
 	}
 
-	return EmbedObject{
+	embed := EmbedObject{
 		Title:       title,
 		Description: description,
 		URL:         article.URL,
@@ -121,6 +176,18 @@ func formatArticleEmbed(article Article) EmbedObject {
 		Fields:      fields,
 		Footer:      footer,
 	}
+
+	if article.Author != "" {
+		embed.Author = &EmbedAuthor{Name: truncateString(article.Author, maxAuthorNameLength)}
+	}
+	if article.ThumbnailURL != "" {
+		embed.Thumbnail = &EmbedThumbnail{URL: article.ThumbnailURL}
+	}
+	if !article.PublishedAt.IsZero() {
+		embed.Timestamp = article.PublishedAt.UTC().Format(time.RFC3339)
+	}
+
+	return embed
 }
 
 // truncateString は文字列を指定された長さに切り詰める（末尾に"..."を付ける）
@@ -79,6 +79,8 @@ var (
 	// ネットワーク関連
 	ErrNetworkTimeout    = New(ErrorTypeNetwork, "ネットワークタイムアウトが発生しました")
 	ErrConnectionFailed  = New(ErrorTypeNetwork, "接続に失敗しました")
+	ErrBlockedDestination = New(ErrorTypeNetwork, "安全でない宛先へのリクエストを拒否しました")
+	ErrResponseTooLarge  = New(ErrorTypeNetwork, "レスポンスが大きすぎます")
 
 	// ストレージ関連
 	ErrStorageNotFound   = New(ErrorTypeStorage, "データが見つかりません")
@@ -87,6 +89,10 @@ var (
 	// LLM関連
 	ErrLLMAPIFailed      = New(ErrorTypeLLM, "LLM APIの呼び出しに失敗しました")
 	ErrLLMQuotaExceeded  = New(ErrorTypeLLM, "LLMのクォータを超過しました")
+	ErrLLMTransient      = New(ErrorTypeLLM, "LLM APIで一時的なエラーが発生しました")
+	ErrLLMAuthFailed     = New(ErrorTypeLLM, "LLM APIの認証に失敗しました")
+	ErrLLMBadRequest     = New(ErrorTypeLLM, "LLM APIへのリクエストが不正です")
+	ErrLLMBudgetExceeded = New(ErrorTypeLLM, "LLMの1日あたりの使用量の上限に達しました")
 
 	// RSS関連
 	ErrRSSFetchFailed    = New(ErrorTypeRSS, "RSSフィードの取得に失敗しました")
@@ -95,6 +101,10 @@ var (
 	// Discord関連
 	ErrDiscordAPIFailed  = New(ErrorTypeDiscord, "Discord APIの呼び出しに失敗しました")
 	ErrDiscordSendFailed = New(ErrorTypeDiscord, "メッセージの送信に失敗しました")
+
+	// 記事関連
+	ErrRobotsDisallowed = New(ErrorTypeArticle, "robots.txtによりクロールが禁止されています")
+	ErrPaywalled        = New(ErrorTypeArticle, "有料記事またはログインが必要な記事です")
 )
 
 // NewConfigError は設定関連のエラーを作成します
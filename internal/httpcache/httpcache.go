@@ -0,0 +1,240 @@
+// Package httpcache はHTTPレスポンスをディスクに保存するキャッシュ（http.RoundTripper）を提供する
+//
+// cmd/local-testでプロンプトを調整する際に、実行のたびにフィードや記事を取得し直さないために使用する。
+// レスポンスはURLごとにファイルとして保存し、最大保持期間を過ぎたものは取得し直す。
+// 凍結モードではキャッシュ済みのレスポンスのみを返し、ネットワークにアクセスしないため、
+// 同じ入力で実行を再現でき、オフラインでも動作する
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+const (
+	// DefaultMaxAge はキャッシュしたレスポンスのデフォルトの最大保持期間
+	DefaultMaxAge = 24 * time.Hour
+
+	// DefaultDir はキャッシュを保存するデフォルトのディレクトリ
+	DefaultDir = ".httpcache"
+)
+
+// ErrNotCached は凍結モードでキャッシュにないURLへのリクエストを拒否したことを表す
+var ErrNotCached = stderrors.New("凍結モードのためキャッシュにないURLは取得できません")
+
+// Options はキャッシュの設定
+type Options struct {
+	// Dir はレスポンスを保存するディレクトリ（空の場合はDefaultDir）
+	Dir string
+	// MaxAge はキャッシュしたレスポンスの最大保持期間（0以下の場合は期限なし）
+	MaxAge time.Duration
+	// Frozen はキャッシュ済みのレスポンスのみを返し、ネットワークにアクセスしないモード
+	Frozen bool
+}
+
+// OptionsFromEnv は環境変数HTTP_CACHE_DIR・HTTP_CACHE_MAX_AGE・HTTP_CACHE_FROZENからキャッシュの設定を作成する
+// HTTP_CACHE_DIRとHTTP_CACHE_FROZENのどちらも設定されていない場合はfalseを返す（キャッシュを使用しない）
+func OptionsFromEnv() (Options, bool, error) {
+	opts := Options{Dir: os.Getenv("HTTP_CACHE_DIR"), MaxAge: DefaultMaxAge}
+
+	if v := os.Getenv("HTTP_CACHE_FROZEN"); v != "" {
+		frozen, err := strconv.ParseBool(v)
+		if err != nil {
+			return Options{}, false, fmt.Errorf("HTTP_CACHE_FROZENの値が不正です: %q", v)
+		}
+		opts.Frozen = frozen
+	}
+	if v := os.Getenv("HTTP_CACHE_MAX_AGE"); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil {
+			return Options{}, false, fmt.Errorf("HTTP_CACHE_MAX_AGEの値が不正です（例: 12h）: %q", v)
+		}
+		opts.MaxAge = maxAge
+	}
+	enabled := opts.Dir != "" || opts.Frozen
+	if opts.Dir == "" {
+		opts.Dir = DefaultDir
+	}
+	return opts, enabled, nil
+}
+
+// Cache はディスク上のレスポンスキャッシュ
+// 複数のHTTPクライアントのTransportをWrapで包むことで、同じキャッシュを共有できる
+type Cache struct {
+	dir    string
+	maxAge time.Duration
+	frozen bool
+	now    func() time.Time
+}
+
+// New はキャッシュを作成する（保存先のディレクトリがない場合は作成する）
+func New(opts Options) (*Cache, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("キャッシュディレクトリの作成に失敗: %w", err)
+	}
+	return &Cache{dir: dir, maxAge: opts.MaxAge, frozen: opts.Frozen, now: time.Now}, nil
+}
+
+// Wrap はbaseの前段でキャッシュを参照するhttp.RoundTripperを返す
+// baseがnilの場合はhttp.DefaultTransportを使用する
+func (c *Cache) Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{cache: c, base: base}
+}
+
+// entry はディスクに保存するレスポンス
+type entry struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// path はURLに対応するキャッシュファイルのパスを返す
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load はURLのキャッシュを読み込む（ない場合・読み込めない場合はnil）
+func (c *Cache) load(url string) *entry {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != url {
+		return nil
+	}
+	return &e
+}
+
+// fresh はキャッシュが最大保持期間内かどうかを返す
+func (c *Cache) fresh(e *entry) bool {
+	return c.maxAge <= 0 || c.now().Sub(e.StoredAt) <= c.maxAge
+}
+
+// store はレスポンスをキャッシュに保存する
+// 書き込み途中のファイルを読まないよう、一時ファイルに書き込んでから置き換える
+func (c *Cache) store(e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(e.URL))
+}
+
+// cacheable はレスポンスをキャッシュするかどうかを返す
+// リダイレクトを再現できるよう3xxも保存し、一時的なエラー（429・5xx）は保存しない
+func cacheable(statusCode int) bool {
+	return (statusCode >= 200 && statusCode < 400) || statusCode == http.StatusNotFound || statusCode == http.StatusGone
+}
+
+// transport はキャッシュを参照するhttp.RoundTripper
+type transport struct {
+	cache *Cache
+	base  http.RoundTripper
+}
+
+// RoundTrip はキャッシュがあればそれを返し、なければbaseで取得してキャッシュする
+// GET以外のリクエストはキャッシュしない（凍結モードでは拒否する）
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := logging.FromContext(req.Context())
+	url := req.URL.String()
+
+	if req.Method == http.MethodGet {
+		if e := t.cache.load(url); e != nil && (t.cache.frozen || t.cache.fresh(e)) {
+			logger.Debug("HTTPキャッシュから応答します", "url", url, "storedAt", e.StoredAt)
+			return e.response(req), nil
+		}
+	}
+	if t.cache.frozen {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s %s", ErrNotCached, req.Method, url)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet || !cacheable(resp.StatusCode) {
+		return resp, err
+	}
+
+	// 呼び出し側がボディを最後まで読んだ場合のみ保存する（サイズ上限などで中断したレスポンスは保存しない）
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		onComplete: func(body []byte) {
+			e := &entry{URL: url, StatusCode: resp.StatusCode, Header: resp.Header, Body: body, StoredAt: t.cache.now()}
+			if err := t.cache.store(e); err != nil {
+				logger.Warn("HTTPキャッシュの保存に失敗", "url", url, "error", err)
+			}
+		},
+	}
+	return resp, nil
+}
+
+// response はキャッシュからレスポンスを復元する
+func (e *entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// recordingBody は読み取った内容を記録し、最後まで読み取られた時点でonCompleteを呼ぶレスポンスボディ
+type recordingBody struct {
+	io.ReadCloser
+	buf        bytes.Buffer
+	onComplete func(body []byte)
+	done       bool
+}
+
+// Read はボディを読み取り、内容を記録する
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF && !b.done {
+		b.done = true
+		b.onComplete(b.buf.Bytes())
+	}
+	return n, err
+}
@@ -0,0 +1,183 @@
+package httpcache
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServer はリクエスト数を数えるテスト用サーバーを作成する
+func newTestServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, server.URL+"/article", http.StatusFound)
+		case "/error":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>article</html>"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// get はURLを取得し、ボディを最後まで読み取って返す
+func get(t *testing.T, client *http.Client, url string) (string, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestCache_StoresAndServes(t *testing.T) {
+	server, hits := newTestServer(t)
+	cache, err := New(Options{Dir: t.TempDir(), MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	client := &http.Client{Transport: cache.Wrap(nil)}
+
+	for i := 0; i < 3; i++ {
+		body, err := get(t, client, server.URL+"/article")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if body != "<html>article</html>" {
+			t.Errorf("body = %q", body)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("サーバーへのリクエスト数 = %d, キャッシュされていません", got)
+	}
+
+	// 一時的なエラーはキャッシュしない
+	for i := 0; i < 2; i++ {
+		get(t, client, server.URL+"/error")
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("サーバーへのリクエスト数 = %d, 5xxがキャッシュされています", got)
+	}
+}
+
+func TestCache_MaxAge(t *testing.T) {
+	server, hits := newTestServer(t)
+	cache, err := New(Options{Dir: t.TempDir(), MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	client := &http.Client{Transport: cache.Wrap(nil)}
+
+	get(t, client, server.URL+"/article")
+	now = now.Add(59 * time.Minute)
+	get(t, client, server.URL+"/article")
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("保持期間内に再取得しました: %d", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	get(t, client, server.URL+"/article")
+	if got := hits.Load(); got != 2 {
+		t.Errorf("保持期間を過ぎたキャッシュが使われました: %d", got)
+	}
+}
+
+func TestCache_PartialReadIsNotStored(t *testing.T) {
+	server, hits := newTestServer(t)
+	cache, err := New(Options{Dir: t.TempDir(), MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	client := &http.Client{Transport: cache.Wrap(nil)}
+
+	// サイズ上限などで途中までしか読まなかったレスポンスは保存しない
+	resp, err := client.Get(server.URL + "/article")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	io.ReadFull(resp.Body, make([]byte, 4))
+	resp.Body.Close()
+
+	get(t, client, server.URL+"/article")
+	if got := hits.Load(); got != 2 {
+		t.Errorf("途中まで読んだレスポンスがキャッシュされました: %d", got)
+	}
+}
+
+func TestCache_Frozen(t *testing.T) {
+	server, hits := newTestServer(t)
+	dir := t.TempDir()
+
+	warm, err := New(Options{Dir: dir, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	body, err := get(t, &http.Client{Transport: warm.Wrap(nil)}, server.URL+"/redirect")
+	if err != nil || body != "<html>article</html>" {
+		t.Fatalf("キャッシュの作成に失敗: %q, %v", body, err)
+	}
+	server.Close()
+
+	frozen, err := New(Options{Dir: dir, MaxAge: time.Nanosecond, Frozen: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	client := &http.Client{Transport: frozen.Wrap(nil)}
+
+	// 保持期間を過ぎていても、リダイレクトを含めてキャッシュから再現する
+	resp, err := client.Get(server.URL + "/redirect")
+	if err != nil {
+		t.Fatalf("凍結モードでキャッシュ済みのURLの取得に失敗: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Request.URL.String() != server.URL+"/article" {
+		t.Errorf("最終URL = %s", resp.Request.URL)
+	}
+
+	_, err = get(t, client, server.URL+"/uncached")
+	if !stderrors.Is(err, ErrNotCached) {
+		t.Errorf("キャッシュにないURLでErrNotCachedが返りませんでした: %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("凍結モードでサーバーにアクセスしました: %d", got)
+	}
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("HTTP_CACHE_DIR", "")
+	t.Setenv("HTTP_CACHE_FROZEN", "")
+	t.Setenv("HTTP_CACHE_MAX_AGE", "")
+	if _, enabled, err := OptionsFromEnv(); err != nil || enabled {
+		t.Errorf("環境変数なしでキャッシュが有効になりました: %v, %v", enabled, err)
+	}
+
+	t.Setenv("HTTP_CACHE_FROZEN", "true")
+	t.Setenv("HTTP_CACHE_MAX_AGE", "30m")
+	opts, enabled, err := OptionsFromEnv()
+	if err != nil || !enabled {
+		t.Fatalf("OptionsFromEnv = %v, %v", enabled, err)
+	}
+	if opts.Dir != DefaultDir || !opts.Frozen || opts.MaxAge != 30*time.Minute {
+		t.Errorf("opts = %+v", opts)
+	}
+
+	t.Setenv("HTTP_CACHE_MAX_AGE", "1日")
+	if _, _, err := OptionsFromEnv(); err == nil {
+		t.Error("不正なHTTP_CACHE_MAX_AGEがエラーになりませんでした")
+	}
+}
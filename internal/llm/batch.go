@@ -0,0 +1,262 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+const (
+	// DefaultBatchTokenBudget はバッチ評価の1回のプロンプトの推定トークン数のデフォルトの上限
+	DefaultBatchTokenBudget = 30000
+
+	// estimatedOutputTokensPerArticle はバッチ評価の応答で1記事あたりに必要な出力トークン数の見積もり
+	// （要約200文字・評価理由・トピックを含むJSONの要素1つ分）
+	estimatedOutputTokensPerArticle = 400
+)
+
+// BatchOptions はバッチ評価の制限を表します
+// MaxArticlesが1以下の場合はバッチ評価を行わず、1記事ずつ評価します
+type BatchOptions struct {
+	MaxArticles       int // 1回の呼び出しで評価する記事の最大数
+	InputTokenBudget  int // プロンプトの推定トークン数の上限
+	OutputTokenBudget int // 応答の最大トークン数（記事数×1記事あたりの見積もりがこれを超えないようにする）
+}
+
+// BatchOptionsFromSettings はLLMの設定からバッチ評価の制限を作成します
+func BatchOptionsFromSettings(settings config.LLMSettings) BatchOptions {
+	opts := BatchOptions{
+		MaxArticles:       settings.BatchSize,
+		InputTokenBudget:  settings.BatchTokenBudget,
+		OutputTokenBudget: settings.MaxOutputTokens,
+	}
+	if opts.InputTokenBudget <= 0 {
+		opts.InputTokenBudget = DefaultBatchTokenBudget
+	}
+	if opts.OutputTokenBudget <= 0 {
+		opts.OutputTokenBudget = MaxOutputTokens
+	}
+	return opts
+}
+
+// SetBatchOptions はEvaluateArticlesのバッチ評価の制限を設定します
+func (e *Evaluator) SetBatchOptions(opts BatchOptions) {
+	e.batch = opts
+}
+
+// BatchItem はバッチ評価する記事と、評価に使う興味トピックを表します
+type BatchItem struct {
+	Article *config.Article
+	Topics  []config.InterestTopic
+}
+
+// BatchResult はバッチ評価の記事ごとの結果を表します（Errがnilの場合はEvaluationが設定されます）
+type BatchResult struct {
+	Article    *config.Article
+	Evaluation *config.ArticleEvaluation
+	Err        error
+}
+
+// BatchEvaluationResult はLLMからのバッチ評価の結果を表します
+// OpenAI互換APIのJSONモードは応答の最上位がオブジェクトである必要があるため、配列をオブジェクトで包みます
+type BatchEvaluationResult struct {
+	Evaluations []ArticleEvaluationResult `json:"evaluations" description:"記事ごとの評価（記事ごとに1つずつ）"`
+}
+
+// ArticleEvaluationResult はバッチ評価の記事ごとの評価結果を表します
+type ArticleEvaluationResult struct {
+	ArticleID string `json:"article_id" description:"評価した記事の記事ID"`
+	EvaluationResult
+}
+
+// batchEvaluationResultSchema はバッチ評価の結果のresponseSchema
+var batchEvaluationResultSchema = SchemaFor(BatchEvaluationResult{})
+
+// EvaluateArticles は複数の記事を評価し、記事と同じ順序で結果を返します
+// 評価キャッシュにある記事はキャッシュの結果を使い、残りの記事のうち同じ興味トピックで評価する記事を、
+// 記事数とトークン数の上限に収まる範囲で1回のLLM呼び出しにまとめます
+// 応答に含まれない記事・応答が不正だった記事は、1記事ずつ評価し直します
+func (e *Evaluator) EvaluateArticles(ctx context.Context, items []BatchItem, minRelevanceScore int) []BatchResult {
+	results := make([]BatchResult, len(items))
+	var pending []int
+	for i, item := range items {
+		results[i].Article = item.Article
+		if evaluation := e.cachedEvaluation(ctx, item.Article, item.Topics, minRelevanceScore); evaluation != nil {
+			results[i].Evaluation = evaluation
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	for _, batch := range e.planBatches(items, pending) {
+		if len(batch) == 1 {
+			e.evaluateOne(ctx, items, results, batch[0], minRelevanceScore)
+			continue
+		}
+		e.evaluateBatch(ctx, items, results, batch, minRelevanceScore)
+	}
+	return results
+}
+
+// planBatches は評価する記事（itemsのインデックス）を評価する単位に分割します
+// 興味トピックが異なる記事は同じプロンプトで評価できないため、トピックの組み合わせごとにまとめます
+// 評価プロンプトにバッチ評価のテンプレートがない場合は1記事ずつ評価します
+func (e *Evaluator) planBatches(items []BatchItem, pending []int) [][]int {
+	if e.batch.MaxArticles <= 1 || !e.prompts.Evaluation.defines(BatchPromptName) {
+		batches := make([][]int, len(pending))
+		for j, i := range pending {
+			batches[j] = []int{i}
+		}
+		return batches
+	}
+
+	var groupOrder []string
+	groups := map[string][]int{}
+	for _, i := range pending {
+		key := string(topicsKey(items[i].Topics))
+		if _, ok := groups[key]; !ok {
+			groupOrder = append(groupOrder, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	maxArticles := e.batch.MaxArticles
+	if byOutput := e.batch.OutputTokenBudget / estimatedOutputTokensPerArticle; byOutput < maxArticles {
+		maxArticles = byOutput
+	}
+
+	var batches [][]int
+	for _, key := range groupOrder {
+		topics := items[groups[key][0]].Topics
+		baseTokens := e.batchPromptTokens(nil, topics)
+
+		var batch []int
+		tokens := baseTokens
+		for _, i := range groups[key] {
+			articleTokens := e.batchPromptTokens([]*config.Article{items[i].Article}, topics) - baseTokens
+			// 上限を超える場合は現在のバッチを確定する（1記事だけで上限を超える記事は単独で評価する）
+			if len(batch) > 0 && (len(batch) >= maxArticles || tokens+articleTokens > e.batch.InputTokenBudget) {
+				batches = append(batches, batch)
+				batch, tokens = nil, baseTokens
+			}
+			batch = append(batch, i)
+			tokens += articleTokens
+		}
+		if len(batch) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+	return batches
+}
+
+// evaluateOne は1記事をLLMで評価して結果に設定します
+func (e *Evaluator) evaluateOne(ctx context.Context, items []BatchItem, results []BatchResult, i, minRelevanceScore int) {
+	results[i].Evaluation, results[i].Err = e.evaluateArticle(ctx, items[i].Article, items[i].Topics, minRelevanceScore)
+}
+
+// evaluateBatch は複数の記事を1回のLLM呼び出しで評価して結果に設定します
+// 呼び出しに失敗した場合や、応答に含まれない・不正な評価の記事は1記事ずつ評価し直します
+func (e *Evaluator) evaluateBatch(ctx context.Context, items []BatchItem, results []BatchResult, batch []int, minRelevanceScore int) {
+	logger := logging.FromContext(ctx)
+
+	articles := make([]*config.Article, len(batch))
+	for j, i := range batch {
+		articles[j] = items[i].Article
+	}
+	prompt, err := e.prompts.Evaluation.execute(BatchPromptName, newEvaluationPromptData(articles, items[batch[0]].Topics, true))
+
+	retry := batch
+	var response *Generation
+	if err == nil {
+		response, err = e.generateJSON(ctx, prompt, batchEvaluationResultSchema)
+	}
+	var parsed BatchEvaluationResult
+	if err == nil {
+		err = ParseJSONResponse(response.Text, &parsed)
+	}
+	if err != nil && (IsQuotaExceeded(err) || IsBudgetExceeded(err)) {
+		// クォータ超過・使用量の上限は1記事ずつ評価し直しても回復しないため、バッチの全記事を評価失敗とする
+		for _, i := range batch {
+			results[i].Err = fmt.Errorf("failed to generate content: %w", err)
+		}
+		return
+	}
+	if err != nil {
+		logger.Warn("バッチ評価に失敗しました。1記事ずつ評価します", "articleCount", len(batch), "error", err)
+	} else {
+		byID := make(map[string]*EvaluationResult, len(parsed.Evaluations))
+		for k := range parsed.Evaluations {
+			byID[parsed.Evaluations[k].ArticleID] = &parsed.Evaluations[k].EvaluationResult
+		}
+
+		// トークン使用量は記事ごとに分けられないため、バッチの記事数で按分する
+		usage := splitUsage(response.Usage, len(batch))
+		retry = nil
+		for j, i := range batch {
+			result, ok := byID[batchArticleID(j)]
+			if !ok {
+				retry = append(retry, i)
+				continue
+			}
+			if err := ValidateEvaluationResult(result); err != nil {
+				logger.Debug("バッチ評価の結果が不正です", "url", items[i].Article.URL, "error", err)
+				retry = append(retry, i)
+				continue
+			}
+			e.saveCache(ctx, items[i].Article, result, usage)
+			results[i].Evaluation = e.newArticleEvaluation(items[i].Article, items[i].Topics, result, usage, minRelevanceScore)
+		}
+		if len(retry) > 0 {
+			logger.Info("バッチ評価の応答に含まれない記事を1記事ずつ評価します", "articleCount", len(batch), "retryCount", len(retry))
+		}
+	}
+
+	for _, i := range retry {
+		e.evaluateOne(ctx, items, results, i, minRelevanceScore)
+	}
+}
+
+// topicsKey は興味トピックの組み合わせ（別名・優先度を含む）を識別するキーを返します
+func topicsKey(topics []config.InterestTopic) []byte {
+	key, _ := json.Marshal(topics) // []InterestTopic のマーシャルは常に成功する
+	return key
+}
+
+// batchArticleID はバッチ内のj番目（0始まり）の記事の記事IDを返します
+func batchArticleID(j int) string {
+	return fmt.Sprintf("A%d", j+1)
+}
+
+// batchPromptTokens はバッチ評価のプロンプトのトークン数を見積もります
+// テンプレートは起動時に検証済みのため、適用に失敗した場合は0として扱います
+func (e *Evaluator) batchPromptTokens(articles []*config.Article, topics []config.InterestTopic) int {
+	prompt, _ := e.prompts.Evaluation.execute(BatchPromptName, newEvaluationPromptData(articles, topics, true))
+	return estimateTokens(prompt)
+}
+
+// estimateTokens はテキストのトークン数を見積もります
+// 英数字は約4文字で1トークン、日本語などの非ASCII文字は約1文字で1トークンとして数えます
+func estimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return ascii/4 + other
+}
+
+// splitUsage はトークン使用量をn記事で按分します
+func splitUsage(usage config.TokenUsage, n int) config.TokenUsage {
+	return config.TokenUsage{
+		PromptTokens:     usage.PromptTokens / n,
+		CandidatesTokens: usage.CandidatesTokens / n,
+		TotalTokens:      usage.TotalTokens / n,
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+// EvaluationCache は評価結果のキャッシュの保存先を表します（storage.Storeが実装します）
+type EvaluationCache interface {
+	// GetCachedEvaluation はキーに対応する有効期間内の評価キャッシュを返します（存在しない場合はnil）
+	GetCachedEvaluation(ctx context.Context, key string) (*config.CachedEvaluation, error)
+	// SaveCachedEvaluation は評価キャッシュを保存します
+	SaveCachedEvaluation(ctx context.Context, key string, entry *config.CachedEvaluation) error
+}
+
+// SetCache は評価結果のキャッシュを設定します
+// interestsHashには興味トピックのハッシュ（config.Config.InterestsHash）を指定し、
+// 本文・タイトル・タグ・フィードの要約のみで評価したか・興味トピック・プロンプトのバージョン・モデルがすべて同じ場合のみキャッシュを使用します
+func (e *Evaluator) SetCache(cache EvaluationCache, interestsHash string) {
+	e.cache = cache
+	e.interestsHash = interestsHash
+}
+
+// CacheStats は評価キャッシュを使用した記事数と、キャッシュになくLLMで評価した記事数を返します
+func (e *Evaluator) CacheStats() (hits, misses int) {
+	return e.cacheHits, e.cacheMisses
+}
+
+// ContentHash は記事本文のSHA-256を返します
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheKey は評価キャッシュのキー（本文のハッシュ・興味トピックのハッシュ・プロンプトのバージョン・モデルのSHA-256）を返します
+func cacheKey(contentHash, interestsHash, promptVersion, model string) string {
+	return ContentHash(contentHash + "\x00" + interestsHash + "\x00" + promptVersion + "\x00" + model)
+}
+
+// articleCacheKey は記事の評価キャッシュのキーを返します
+func (e *Evaluator) articleCacheKey(article *config.Article) string {
+	return cacheKey(articlePromptHash(article), e.interestsHash, e.PromptVersion(), e.provider.Model())
+}
+
+// articlePromptHash は評価プロンプトに含める記事の内容のハッシュを返します
+// 本文が同じでも、タイトル・タグ・フィードの要約のみで評価したかが異なる記事は別の評価として扱います
+func articlePromptHash(article *config.Article) string {
+	return ContentHash(strings.Join([]string{
+		ContentHash(article.ContentText),
+		article.Title,
+		strings.Join(article.Tags, ","),
+		strconv.FormatBool(article.Paywalled),
+	}, "\x00"))
+}
+
+// cachedEvaluation は評価キャッシュから記事の評価を返します（キャッシュがない場合はnil）
+// キャッシュの読み取りに失敗した場合は、キャッシュがないものとしてLLMで評価します
+func (e *Evaluator) cachedEvaluation(ctx context.Context, article *config.Article, topics []config.InterestTopic, minRelevanceScore int) *config.ArticleEvaluation {
+	if e.cache == nil {
+		return nil
+	}
+
+	entry, err := e.cache.GetCachedEvaluation(ctx, e.articleCacheKey(article))
+	if err != nil {
+		logging.FromContext(ctx).Warn("評価キャッシュの取得に失敗", "url", article.URL, "error", err)
+	}
+	if entry == nil {
+		e.cacheMisses++
+		return nil
+	}
+	e.cacheHits++
+
+	result := &EvaluationResult{
+		RelevanceScore: entry.RelevanceScore,
+		MatchingTopics: entry.MatchingTopics,
+		Summary:        entry.Summary,
+		Reasoning:      entry.Reasoning,
+		IsAIGenerated:  entry.IsAIGenerated,
+	}
+	// LLMを呼び出していないため、トークン使用量は0とする
+	evaluation := e.newArticleEvaluation(article, topics, result, config.TokenUsage{}, minRelevanceScore)
+	evaluation.Cached = true
+	return evaluation
+}
+
+// saveCache はLLMによる評価結果を評価キャッシュに保存します（失敗しても評価は継続する）
+func (e *Evaluator) saveCache(ctx context.Context, article *config.Article, result *EvaluationResult, usage config.TokenUsage) {
+	if e.cache == nil {
+		return
+	}
+
+	entry := &config.CachedEvaluation{
+		ContentHash:    ContentHash(article.ContentText),
+		InterestsHash:  e.interestsHash,
+		PromptVersion:  e.PromptVersion(),
+		Model:          e.provider.Model(),
+		RelevanceScore: result.RelevanceScore,
+		MatchingTopics: result.MatchingTopics,
+		Summary:        result.Summary,
+		Reasoning:      result.Reasoning,
+		IsAIGenerated:  result.IsAIGenerated,
+		TokenUsage:     usage,
+	}
+	if err := e.cache.SaveCachedEvaluation(ctx, e.articleCacheKey(article), entry); err != nil {
+		logging.FromContext(ctx).Warn("評価キャッシュの保存に失敗", "url", article.URL, "error", err)
+	}
+}
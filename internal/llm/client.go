@@ -1,4 +1,4 @@
-// Package llm はGemini API統合を提供します
+// Package llm はLLM（Gemini API・OpenAI互換API・Ollama）による記事の評価とサマリー生成を提供します
 package llm
 
 import (
@@ -6,16 +6,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"golang.org/x/time/rate"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
 )
 
 const (
+	// ModelName は評価・サマリー生成に使用するGeminiモデル名
+	ModelName = "gemini-2.0-flash"
+
+	// GeminiAPIBaseURL はGemini APIのベースURL
+	GeminiAPIBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
 	// GeminiAPIURL はGemini 2.0 Flash APIのエンドポイント（AI Studio無料版）
-	GeminiAPIURL = "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
+	GeminiAPIURL = GeminiAPIBaseURL + "/models/" + ModelName + ":generateContent"
 
 	// Temperature はGemini APIの温度パラメータ（一貫性のために低く設定）
 	Temperature = 0.3
@@ -30,11 +40,16 @@ const (
 	BurstLimit = 2
 )
 
-// Client はGemini APIクライアントを表します
+// Client はGemini APIクライアントを表します（Providerの実装）
 type Client struct {
-	apiKey     string
-	httpClient *http.Client
-	limiter    *rate.Limiter
+	apiKey          string
+	model           string
+	endpoint        string // APIのベースURL
+	maxOutputTokens int
+	httpClient      *http.Client
+	limiter         *rate.Limiter
+	maxResponseSize int64 // レスポンスボディの上限サイズ（バイト）
+	retry           RetryPolicy
 }
 
 // NewClient は新しいGemini APIクライアントを作成します
@@ -43,12 +58,41 @@ func NewClient(apiKey string) *Client {
 	limiter := rate.NewLimiter(rate.Every(RequestInterval), BurstLimit)
 
 	return &Client{
-		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-		limiter:    limiter,
+		apiKey:          apiKey,
+		model:           ModelName,
+		endpoint:        GeminiAPIBaseURL,
+		maxOutputTokens: MaxOutputTokens,
+		httpClient:      &http.Client{Timeout: DefaultRequestTimeout},
+		limiter:         limiter,
+		maxResponseSize: int64(config.DefaultLLMResponseMaxKB) * 1024,
+		retry:           DefaultRetryPolicy,
+	}
+}
+
+// newGeminiClient は設定したモデル・エンドポイント・制限でGemini APIクライアントを作成します
+func newGeminiClient(opts ProviderOptions) *Client {
+	return &Client{
+		apiKey:          opts.APIKey,
+		model:           opts.Model,
+		endpoint:        strings.TrimSuffix(opts.Endpoint, "/"),
+		maxOutputTokens: opts.MaxOutputTokens,
+		httpClient:      &http.Client{Timeout: opts.Timeout},
+		limiter:         newLimiter(opts.RequestsPerMinute),
+		maxResponseSize: opts.MaxResponseSize,
+		retry:           DefaultRetryPolicy,
 	}
 }
 
+// SetMaxResponseSize はレスポンスボディの上限サイズ（バイト）を設定します
+func (c *Client) SetMaxResponseSize(maxSize int64) {
+	c.maxResponseSize = maxSize
+}
+
+// SetRetryPolicy は一時的なエラーとクォータ超過時の再試行の設定を変更します
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
 // GeminiRequest はGemini APIへのリクエストを表します
 type GeminiRequest struct {
 	Contents         []Content         `json:"contents"`
@@ -67,9 +111,12 @@ type Part struct {
 }
 
 // GenerationConfig は生成設定を表します
+// ResponseMimeTypeにapplication/jsonを指定すると、応答はResponseSchemaに従ったJSONのみになります
 type GenerationConfig struct {
-	Temperature     float64 `json:"temperature"`
-	MaxOutputTokens int     `json:"maxOutputTokens"`
+	Temperature      float64 `json:"temperature"`
+	MaxOutputTokens  int     `json:"maxOutputTokens"`
+	ResponseMimeType string  `json:"responseMimeType,omitempty"`
+	ResponseSchema   *Schema `json:"responseSchema,omitempty"`
 }
 
 // GeminiResponse はGemini APIからの応答を表します
@@ -98,6 +145,15 @@ type UsageMetadata struct {
 	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
+// TokenUsage はトークン使用量を保存用の形式に変換します
+func (u UsageMetadata) TokenUsage() config.TokenUsage {
+	return config.TokenUsage{
+		PromptTokens:     u.PromptTokenCount,
+		CandidatesTokens: u.CandidatesTokenCount,
+		TotalTokens:      u.TotalTokenCount,
+	}
+}
+
 // GeminiError はGemini APIからのエラー応答を表します
 type GeminiError struct {
 	Error ErrorDetail `json:"error"`
@@ -105,13 +161,58 @@ type GeminiError struct {
 
 // ErrorDetail はエラーの詳細を表します
 type ErrorDetail struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Status  string `json:"status"`
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Details []ErrorDetailInfo `json:"details,omitempty"`
+}
+
+// ErrorDetailInfo はエラーの追加情報（google.rpc.ErrorInfo・google.rpc.RetryInfoなど）を表します
+type ErrorDetailInfo struct {
+	Type       string `json:"@type"`
+	Reason     string `json:"reason,omitempty"`     // ErrorInfoの理由（API_KEY_INVALIDなど）
+	RetryDelay string `json:"retryDelay,omitempty"` // RetryInfoの再試行までの待機時間（"37s"など）
+}
+
+// hasReason は追加情報に指定された理由が含まれるかどうかを返します
+func (d ErrorDetail) hasReason(reason string) bool {
+	for _, info := range d.Details {
+		if info.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay は追加情報で指定された再試行までの待機時間を返します（指定がない場合は0）
+func (d ErrorDetail) retryDelay() time.Duration {
+	for _, info := range d.Details {
+		if delay, err := time.ParseDuration(info.RetryDelay); err == nil && delay > 0 {
+			return delay
+		}
+	}
+	return 0
 }
 
 // GenerateContent はGemini APIにリクエストを送信してコンテンツを生成します
 func (c *Client) GenerateContent(ctx context.Context, prompt string) (*GeminiResponse, error) {
+	response, _, err := c.generateContent(ctx, prompt, "", nil)
+	return response, err
+}
+
+// generateContent はGemini APIにリクエストを送信してコンテンツを生成します
+// mimeTypeにapplication/jsonを指定した場合は応答をJSONに、schemaも指定した場合はスキーマに従ったJSONに制限します
+// 一時的なエラーとクォータ超過の場合は、Retry-After・retryDelayを考慮した指数バックオフで再試行し、
+// 送信したリクエストの数（再試行を含む）も返します
+func (c *Client) generateContent(ctx context.Context, prompt, mimeType string, schema *Schema) (*GeminiResponse, int, error) {
+	return withRetry(ctx, c.retry, func() (*GeminiResponse, error) {
+		return c.generateContentOnce(ctx, prompt, mimeType, schema)
+	})
+}
+
+// generateContentOnce はGemini APIにリクエストを1回送信してコンテンツを生成します
+// APIのエラー応答とリクエストの送信の失敗は、分類したAPIErrorを含むAppErrorとして返します
+func (c *Client) generateContentOnce(ctx context.Context, prompt, mimeType string, schema *Schema) (*GeminiResponse, error) {
 	// レート制限を適用（次のトークンが利用可能になるまで待機）
 	if err := c.limiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limiter wait failed: %w", err)
@@ -129,9 +230,11 @@ func (c *Client) GenerateContent(ctx context.Context, prompt string) (*GeminiRes
 		},
 		GenerationConfig: GenerationConfig{
 			Temperature:     Temperature,
-			MaxOutputTokens: MaxOutputTokens,
+			MaxOutputTokens: c.maxOutputTokens,
 		},
 	}
+	reqBody.GenerationConfig.ResponseMimeType = mimeType
+	reqBody.GenerationConfig.ResponseSchema = schema
 
 	// JSONにエンコード
 	jsonData, err := json.Marshal(reqBody)
@@ -140,7 +243,7 @@ func (c *Client) GenerateContent(ctx context.Context, prompt string) (*GeminiRes
 	}
 
 	// HTTPリクエストを作成
-	url := fmt.Sprintf("%s?key=%s", GeminiAPIURL, c.apiKey)
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", c.endpoint, c.model, c.apiKey)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -151,12 +254,17 @@ func (c *Client) GenerateContent(ctx context.Context, prompt string) (*GeminiRes
 	// リクエストを送信
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		// タイムアウト・接続エラーは一時的なエラーとして再試行する
+		return nil, errors.NewLLMError("Gemini APIへのリクエストの送信に失敗しました",
+			&APIError{Kind: errors.ErrLLMTransient, Message: err.Error()})
 	}
 	defer resp.Body.Close()
 
-	// レスポンスボディを読み取り
-	body, err := io.ReadAll(resp.Body)
+	// レスポンスボディを上限サイズまで読み取り
+	body, err := safehttp.ReadBody(resp, c.maxResponseSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -165,10 +273,10 @@ func (c *Client) GenerateContent(ctx context.Context, prompt string) (*GeminiRes
 	if resp.StatusCode != http.StatusOK {
 		var geminiErr GeminiError
 		if err := json.Unmarshal(body, &geminiErr); err != nil {
-			return nil, fmt.Errorf("unexpected error response (status %d): %s", resp.StatusCode, string(body))
+			// ゲートウェイのエラーページなどJSONでない応答は、ステータスのみで分類する
+			geminiErr.Error = ErrorDetail{Message: fmt.Sprintf("unexpected error response: %s", string(body))}
 		}
-		return nil, fmt.Errorf("gemini api error (status %d): %s - %s",
-			resp.StatusCode, geminiErr.Error.Status, geminiErr.Error.Message)
+		return nil, newGeminiError(resp.StatusCode, resp.Header, geminiErr.Error)
 	}
 
 	// 成功応答をパース
@@ -202,3 +310,31 @@ func (c *Client) GenerateContent(ctx context.Context, prompt string) (*GeminiRes
 
 	return &geminiResp, nil
 }
+
+// GenerateText はプロンプトに対する応答のテキストを生成します
+func (c *Client) GenerateText(ctx context.Context, prompt string) (*Generation, error) {
+	return c.generation(c.generateContent(ctx, prompt, "", nil))
+}
+
+// GenerateJSON はresponseMimeType（application/json）とresponseSchemaを指定して、スキーマに従ったJSONを生成します
+func (c *Client) GenerateJSON(ctx context.Context, prompt string, schema *Schema) (*Generation, error) {
+	return c.generation(c.generateContent(ctx, prompt, "application/json", schema))
+}
+
+// generation はGemini APIの応答をGenerationに変換します
+// 失敗した場合も、使用量の記録のため送信したリクエストの数だけを持つGenerationを返します
+func (c *Client) generation(response *GeminiResponse, requests int, err error) (*Generation, error) {
+	if err != nil {
+		return &Generation{Requests: requests}, err
+	}
+	return &Generation{
+		Text:     response.Candidates[0].Content.Parts[0].Text,
+		Usage:    response.UsageMetadata.TokenUsage(),
+		Requests: requests,
+	}, nil
+}
+
+// Model はモデル名を返します
+func (c *Client) Model() string {
+	return c.model
+}
@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// OllamaClient はOllamaの生成API（/api/generate）のクライアントを表します（Providerの実装）
+type OllamaClient struct {
+	model           string
+	endpoint        string // OllamaのベースURL（例: http://localhost:11434）
+	maxOutputTokens int
+	httpClient      *http.Client
+	limiter         *rate.Limiter
+	maxResponseSize int64 // レスポンスボディの上限サイズ（バイト）
+}
+
+// NewOllamaClient は新しいOllamaクライアントを作成します
+func NewOllamaClient(opts ProviderOptions) *OllamaClient {
+	return &OllamaClient{
+		model:           opts.Model,
+		endpoint:        strings.TrimSuffix(opts.Endpoint, "/"),
+		maxOutputTokens: opts.MaxOutputTokens,
+		httpClient:      &http.Client{Timeout: opts.Timeout},
+		limiter:         newLimiter(opts.RequestsPerMinute),
+		maxResponseSize: opts.MaxResponseSize,
+	}
+}
+
+// OllamaRequest は/api/generateへのリクエストを表します
+type OllamaRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Format  string        `json:"format,omitempty"` // JSONモードの場合は"json"
+	Options OllamaOptions `json:"options"`
+}
+
+// OllamaOptions はモデルの生成パラメータを表します
+type OllamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict"`
+}
+
+// OllamaResponse は/api/generateからの応答（stream: falseの場合）を表します
+type OllamaResponse struct {
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// OllamaError はOllamaからのエラー応答を表します
+type OllamaError struct {
+	Error string `json:"error"`
+}
+
+// GenerateText はプロンプトに対する応答のテキストを生成します
+func (c *OllamaClient) GenerateText(ctx context.Context, prompt string) (*Generation, error) {
+	return c.generate(ctx, prompt, "")
+}
+
+// GenerateJSON はJSONモード（format: json）で応答を生成します
+// スキーマによる出力の制限はOllamaのバージョンに依存するため、スキーマは指定しません
+func (c *OllamaClient) GenerateJSON(ctx context.Context, prompt string, _ *Schema) (*Generation, error) {
+	return c.generate(ctx, prompt, "json")
+}
+
+// Model はモデル名を返します
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
+// generate は/api/generateにリクエストを送信して応答を生成します
+func (c *OllamaClient) generate(ctx context.Context, prompt, format string) (*Generation, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter wait failed: %w", err)
+	}
+
+	reqBody := OllamaRequest{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: false,
+		Format: format,
+		Options: OllamaOptions{
+			Temperature: Temperature,
+			NumPredict:  c.maxOutputTokens,
+		},
+	}
+
+	status, body, err := postJSON(ctx, c.httpClient, c.endpoint+"/api/generate", nil, reqBody, c.maxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		var apiErr OllamaError
+		if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Error == "" {
+			return nil, fmt.Errorf("unexpected error response (status %d): %s", status, string(body))
+		}
+		return nil, fmt.Errorf("ollama api error (status %d): %s", status, apiErr.Error)
+	}
+
+	var resp OllamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !resp.Done {
+		return nil, fmt.Errorf("incomplete response from ollama")
+	}
+	if resp.DoneReason == "length" {
+		return nil, fmt.Errorf("response truncated: max tokens limit reached")
+	}
+
+	return &Generation{
+		Text: resp.Response,
+		Usage: config.TokenUsage{
+			PromptTokens:     resp.PromptEvalCount,
+			CandidatesTokens: resp.EvalCount,
+			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+		},
+	}, nil
+}
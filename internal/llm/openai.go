@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// OpenAIClient はOpenAI互換のChat Completions APIのクライアントを表します（Providerの実装）
+// OpenAIのほか、同じAPIを提供するllama.cpp・vLLMなどのローカルサーバーにも接続できます
+type OpenAIClient struct {
+	apiKey          string
+	model           string
+	endpoint        string // APIのベースURL（例: https://api.openai.com/v1）
+	maxOutputTokens int
+	httpClient      *http.Client
+	limiter         *rate.Limiter
+	maxResponseSize int64 // レスポンスボディの上限サイズ（バイト）
+}
+
+// NewOpenAIClient は新しいOpenAI互換APIクライアントを作成します
+func NewOpenAIClient(opts ProviderOptions) *OpenAIClient {
+	return &OpenAIClient{
+		apiKey:          opts.APIKey,
+		model:           opts.Model,
+		endpoint:        strings.TrimSuffix(opts.Endpoint, "/"),
+		maxOutputTokens: opts.MaxOutputTokens,
+		httpClient:      &http.Client{Timeout: opts.Timeout},
+		limiter:         newLimiter(opts.RequestsPerMinute),
+		maxResponseSize: opts.MaxResponseSize,
+	}
+}
+
+// OpenAIRequest はChat Completions APIへのリクエストを表します
+type OpenAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []OpenAIMessage       `json:"messages"`
+	Temperature    float64               `json:"temperature"`
+	MaxTokens      int                   `json:"max_tokens"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// OpenAIMessage はチャットのメッセージを表します
+type OpenAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OpenAIResponseFormat は応答の形式を表します（JSONモードの場合は"json_object"）
+type OpenAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// OpenAIResponse はChat Completions APIからの応答を表します
+type OpenAIResponse struct {
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   OpenAIUsage    `json:"usage"`
+}
+
+// OpenAIChoice は応答の候補を表します
+type OpenAIChoice struct {
+	Message      OpenAIMessage `json:"message"`
+	FinishReason string        `json:"finish_reason"`
+}
+
+// OpenAIUsage はトークン使用量を表します
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// OpenAIError はOpenAI互換APIからのエラー応答を表します
+type OpenAIError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// GenerateText はプロンプトに対する応答のテキストを生成します
+func (c *OpenAIClient) GenerateText(ctx context.Context, prompt string) (*Generation, error) {
+	return c.generate(ctx, prompt, nil)
+}
+
+// GenerateJSON はJSONモード（response_format: json_object）で応答を生成します
+// json_schemaに対応しないローカルサーバーがあるため、スキーマは指定しません
+func (c *OpenAIClient) GenerateJSON(ctx context.Context, prompt string, _ *Schema) (*Generation, error) {
+	return c.generate(ctx, prompt, &OpenAIResponseFormat{Type: "json_object"})
+}
+
+// Model はモデル名を返します
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+// generate はChat Completions APIにリクエストを送信して応答を生成します
+func (c *OpenAIClient) generate(ctx context.Context, prompt string, format *OpenAIResponseFormat) (*Generation, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter wait failed: %w", err)
+	}
+
+	reqBody := OpenAIRequest{
+		Model:          c.model,
+		Messages:       []OpenAIMessage{{Role: "user", Content: prompt}},
+		Temperature:    Temperature,
+		MaxTokens:      c.maxOutputTokens,
+		ResponseFormat: format,
+	}
+
+	// ローカルサーバーはAPIキーが不要な場合がある
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+
+	status, body, err := postJSON(ctx, c.httpClient, c.endpoint+"/chat/completions", headers, reqBody, c.maxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		var apiErr OpenAIError
+		if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Error.Message == "" {
+			return nil, fmt.Errorf("unexpected error response (status %d): %s", status, string(body))
+		}
+		return nil, fmt.Errorf("openai api error (status %d): %s - %s", status, apiErr.Error.Type, apiErr.Error.Message)
+	}
+
+	var resp OpenAIResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice := resp.Choices[0]
+	switch choice.FinishReason {
+	case "stop", "":
+		// 正常完了（finish_reasonを返さないローカルサーバーもある）
+	case "length":
+		return nil, fmt.Errorf("response truncated: max tokens limit reached")
+	case "content_filter":
+		return nil, fmt.Errorf("content blocked by content filter")
+	default:
+		return nil, fmt.Errorf("unexpected finish reason: %s", choice.FinishReason)
+	}
+
+	return &Generation{
+		Text: choice.Message.Content,
+		Usage: config.TokenUsage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CandidatesTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}, nil
+}
@@ -0,0 +1,395 @@
+package llm
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// builtinPromptFiles は組み込みのプロンプトのテンプレート（prompt_settingsを省略した場合に使用）
+//
+//go:embed prompts/*.tmpl
+var builtinPromptFiles embed.FS
+
+// BatchPromptName は評価プロンプトのテンプレートで、バッチ評価のプロンプトを定義するテンプレート名
+// 評価プロンプトのファイル全体が1記事の評価のプロンプト、{{define "batch"}}がバッチ評価のプロンプトになります
+// バッチ評価を行わない（batch_sizeが0・1）場合は定義を省略できます
+const BatchPromptName = "batch"
+
+// PromptTemplate はバージョンと必須変数を宣言したプロンプトのテンプレート（text/template）を表します
+// テンプレートの先頭には、次の形式のコメントでバージョンと必須変数を宣言します
+//
+//	{{/*
+//	version: v3
+//	required: Topics, Article
+//	*/}}
+type PromptTemplate struct {
+	Source   string   // テンプレートの読み込み元（組み込みの場合はbuiltin:ファイル名）
+	Version  string   // プロンプトのバージョン（評価プロンプトのバージョンは評価記録・評価キャッシュ・再評価の判定に使用）
+	Required []string // テンプレートが必要とする変数（起動時にプログラムが渡す変数に含まれるかを検証する）
+	tmpl     *template.Template
+}
+
+// ParsePromptTemplate はテンプレートを解析し、先頭のコメントからバージョンと必須変数を読み取ります
+func ParsePromptTemplate(source string, data []byte) (*PromptTemplate, error) {
+	version, required, err := parsePromptHeader(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template header %s: %w", source, err)
+	}
+
+	tmpl, err := template.New(source).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", source, err)
+	}
+
+	return &PromptTemplate{
+		Source:   source,
+		Version:  version,
+		Required: required,
+		tmpl:     tmpl,
+	}, nil
+}
+
+// parsePromptHeader はテンプレートの先頭のコメント（{{/* ... */}}）からバージョンと必須変数を読み取ります
+func parsePromptHeader(text string) (version string, required []string, err error) {
+	text = strings.TrimLeft(text, "\ufeff \t\r\n")
+	if !strings.HasPrefix(text, "{{/*") && !strings.HasPrefix(text, "{{- /*") {
+		return "", nil, fmt.Errorf("template must start with a {{/* ... */}} comment declaring version and required variables")
+	}
+	start := strings.Index(text, "/*") + len("/*")
+	end := strings.Index(text, "*/")
+	if end < 0 {
+		return "", nil, fmt.Errorf("header comment is not closed")
+	}
+
+	for _, line := range strings.Split(text[start:end], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid header line %q (expected key: value)", line)
+		}
+		switch strings.TrimSpace(key) {
+		case "version":
+			version = strings.TrimSpace(value)
+		case "required":
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					required = append(required, name)
+				}
+			}
+		default:
+			return "", nil, fmt.Errorf("unknown header key %q", strings.TrimSpace(key))
+		}
+	}
+
+	if version == "" {
+		return "", nil, fmt.Errorf("version is not declared")
+	}
+	return version, required, nil
+}
+
+// execute はテンプレート（nameが空の場合はファイル全体）にdataを適用したプロンプトを返します
+func (p *PromptTemplate) execute(name string, data any) (string, error) {
+	var b strings.Builder
+	var err error
+	if name == "" {
+		err = p.tmpl.Execute(&b, data)
+	} else {
+		err = p.tmpl.ExecuteTemplate(&b, name, data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to execute prompt template %s: %w", p.Source, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// defines はテンプレートがnameのテンプレートを定義しているかどうかを返します
+func (p *PromptTemplate) defines(name string) bool {
+	return p.tmpl.Lookup(name) != nil
+}
+
+// validate はテンプレートを検証します
+// 宣言された必須変数がすべてdataの変数に含まれること、各テンプレートがdataを適用して空でないプロンプトを生成できることを確認します
+func (p *PromptTemplate) validate(data any, samples map[string]any) error {
+	dataType := reflect.TypeOf(data)
+	for _, name := range p.Required {
+		if _, ok := dataType.FieldByName(name); !ok {
+			return fmt.Errorf("prompt template %s requires unknown variable %q (available: %s)", p.Source, name, strings.Join(promptVariables(dataType), ", "))
+		}
+	}
+
+	for name, sample := range samples {
+		if name != "" && !p.defines(name) {
+			return fmt.Errorf("prompt template %s does not define template %q", p.Source, name)
+		}
+		prompt, err := p.execute(name, sample)
+		if err != nil {
+			return err
+		}
+		if prompt == "" {
+			return fmt.Errorf("prompt template %s generated an empty prompt", p.Source)
+		}
+	}
+	return nil
+}
+
+// promptVariables はテンプレートに渡す変数の名前を返します
+func promptVariables(dataType reflect.Type) []string {
+	names := make([]string, dataType.NumField())
+	for i := range names {
+		names[i] = dataType.Field(i).Name
+	}
+	return names
+}
+
+// EvaluationPromptData は評価プロンプトのテンプレートに渡す変数を表します
+type EvaluationPromptData struct {
+	Topics       string          // 興味トピック名のJSON配列
+	TopicAliases string          // 興味トピックの別名の注記（改行で始まる、別名がない場合は空）
+	Article      *PromptArticle  // 評価する記事（1記事の評価のみ）
+	Articles     []PromptArticle // 評価する記事（バッチ評価のみ）
+}
+
+// PromptArticle はプロンプトのテンプレートに渡す記事の情報を表します
+type PromptArticle struct {
+	ID         string   // バッチ評価の記事ID（A1など、1記事の評価では空）
+	Title      string   // 記事タイトル
+	Content    string   // 記事内容（Markdown、MaxPromptContentLength文字で切り詰め済み）
+	CodeBlocks int      // コードブロックの数
+	Headings   int      // 見出しの数
+	Links      int      // リンクの数
+	Paywalled  bool     // 有料記事のため記事内容がフィードの要約のみ
+	Tags       []string // 記事サイトが付与したタグ
+	LikeCount  int      // 記事サイトのいいね数
+	Language   string   // 記事の言語（ISO 639-1、判定できなかった場合は空）
+	Notes      string   // 有料記事・記事サイトの情報・記事の言語の注記（各行が改行で始まる）
+}
+
+// SummaryPromptData はサマリーのプロンプトのテンプレートに渡す変数を表します
+type SummaryPromptData struct {
+	ArticlesJSON string                 // 記事の情報（ArticleForSummary）のJSON配列
+	Articles     []SummaryPromptArticle // 記事の情報
+}
+
+// SummaryPromptArticle はサマリーのプロンプトのテンプレートに渡す記事の情報を表します
+type SummaryPromptArticle struct {
+	Number int // 記事の番号（1始まり）
+	ArticleForSummary
+}
+
+// TriagePromptData はトリアージのプロンプトのテンプレートに渡す変数を表します
+type TriagePromptData struct {
+	Topics       string                // 興味トピック名のJSON配列
+	TopicAliases string                // 興味トピックの別名の注記（改行で始まる、別名がない場合は空）
+	Articles     []TriagePromptArticle // 判定する記事
+}
+
+// TriagePromptArticle はトリアージのプロンプトのテンプレートに渡す記事の情報を表します
+type TriagePromptArticle struct {
+	ID          string // 記事ID（T1など）
+	Title       string // 記事タイトル
+	Description string // フィードの要約（MaxTriageDescriptionLength文字で切り詰め済み、ない場合は空）
+}
+
+// newPromptArticle は記事からプロンプトのテンプレートに渡す記事の情報を作成します
+func newPromptArticle(id string, article *config.Article) PromptArticle {
+	return PromptArticle{
+		ID:         id,
+		Title:      article.Title,
+		Content:    TruncateContent(article.ContentText, MaxPromptContentLength),
+		CodeBlocks: article.ContentStats.CodeBlocks,
+		Headings:   article.ContentStats.Headings,
+		Links:      article.ContentStats.Links,
+		Paywalled:  article.Paywalled,
+		Tags:       article.Tags,
+		LikeCount:  article.LikeCount,
+		Language:   article.Language,
+		Notes:      paywallNote(article) + siteSignalsNote(article) + languageNote(article),
+	}
+}
+
+// newEvaluationPromptData は1記事の評価（articlesが1件でbatchがfalse）またはバッチ評価のテンプレートに渡す変数を作成します
+func newEvaluationPromptData(articles []*config.Article, topics []config.InterestTopic, batch bool) EvaluationPromptData {
+	data := EvaluationPromptData{
+		Topics:       string(topicNamesJSON(topics)),
+		TopicAliases: topicAliasesNote(topics),
+	}
+	if !batch {
+		article := newPromptArticle("", articles[0])
+		data.Article = &article
+		return data
+	}
+	data.Articles = make([]PromptArticle, len(articles))
+	for j, article := range articles {
+		data.Articles[j] = newPromptArticle(batchArticleID(j), article)
+	}
+	return data
+}
+
+// newSummaryPromptData はサマリーのテンプレートに渡す変数を作成します
+func newSummaryPromptData(articles []ArticleForSummary) SummaryPromptData {
+	// 記事情報をJSON形式で整形 (構造体のマーシャルは常に成功する)
+	articlesJSON, _ := json.MarshalIndent(articles, "", "  ")
+
+	data := SummaryPromptData{
+		ArticlesJSON: string(articlesJSON),
+		Articles:     make([]SummaryPromptArticle, len(articles)),
+	}
+	for i, article := range articles {
+		data.Articles[i] = SummaryPromptArticle{Number: i + 1, ArticleForSummary: article}
+	}
+	return data
+}
+
+// newTriagePromptData はトリアージのテンプレートに渡す変数を作成します
+func newTriagePromptData(items []TriageItem, topics []config.InterestTopic) TriagePromptData {
+	data := TriagePromptData{
+		Topics:       string(topicNamesJSON(topics)),
+		TopicAliases: triageAliasesNote(topics),
+		Articles:     make([]TriagePromptArticle, len(items)),
+	}
+	for j, item := range items {
+		data.Articles[j] = TriagePromptArticle{
+			ID:          triageArticleID(j),
+			Title:       item.Title,
+			Description: truncateRunes(strings.TrimSpace(item.Description), MaxTriageDescriptionLength),
+		}
+	}
+	return data
+}
+
+// Prompts は記事の評価・サマリー生成・トリアージに使うプロンプトのテンプレートを表します
+type Prompts struct {
+	Evaluation *PromptTemplate // 記事の評価（ファイル全体が1記事の評価、"batch"テンプレートがバッチ評価）
+	Summary    *PromptTemplate // 記事全体のサマリー
+	Triage     *PromptTemplate // 本文を取得する前のトリアージ（タイトルとフィードの要約で判定）
+}
+
+// defaultPrompts は組み込みのプロンプトのテンプレート
+var defaultPrompts = mustLoadBuiltinPrompts()
+
+// DefaultPrompts は組み込みのプロンプトのテンプレートを返します
+func DefaultPrompts() *Prompts {
+	prompts := *defaultPrompts
+	return &prompts
+}
+
+// mustLoadBuiltinPrompts は組み込みのプロンプトのテンプレートを読み込みます（不正な場合はパニック）
+func mustLoadBuiltinPrompts() *Prompts {
+	load := func(name string) *PromptTemplate {
+		data, err := builtinPromptFiles.ReadFile("prompts/" + name)
+		if err != nil {
+			panic(err)
+		}
+		prompt, err := ParsePromptTemplate("builtin:"+name, data)
+		if err != nil {
+			panic(err)
+		}
+		return prompt
+	}
+
+	prompts := &Prompts{
+		Evaluation: load("evaluation.tmpl"),
+		Summary:    load("summary.tmpl"),
+		Triage:     load("triage.tmpl"),
+	}
+	if err := prompts.Validate(); err != nil {
+		panic(err)
+	}
+	return prompts
+}
+
+// Validate はプロンプトのテンプレートを検証します
+// サンプルの記事でテンプレートを適用し、未定義の変数・テンプレートの参照などの誤りを起動時に検出します
+// バッチ評価のテンプレートは、評価プロンプトで定義されている場合のみ検証します
+func (p *Prompts) Validate() error {
+	sample := []*config.Article{samplePromptArticle(), samplePromptArticle()}
+	topics := []config.InterestTopic{{Topic: "Go", Aliases: []string{"Golang"}, Priority: "high"}}
+	evaluationSamples := map[string]any{"": newEvaluationPromptData(sample[:1], topics, false)}
+	if p.Evaluation.defines(BatchPromptName) {
+		evaluationSamples[BatchPromptName] = newEvaluationPromptData(sample, topics, true)
+	}
+	err := p.Evaluation.validate(EvaluationPromptData{}, evaluationSamples)
+	if err != nil {
+		return err
+	}
+
+	err = p.Summary.validate(SummaryPromptData{}, map[string]any{
+		"": newSummaryPromptData([]ArticleForSummary{{Title: "サンプル記事", Summary: "要約", RelevanceScore: 80, MatchingTopics: []string{"Go"}}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.Triage.validate(TriagePromptData{}, map[string]any{
+		"": newTriagePromptData([]TriageItem{{Title: "サンプル記事", Description: "Goの並行処理の解説"}, {Title: "要約のない記事"}}, topics),
+	})
+}
+
+// samplePromptArticle はテンプレートの検証に使うサンプルの記事を返します
+func samplePromptArticle() *config.Article {
+	return &config.Article{
+		Title:       "サンプル記事",
+		URL:         "https://example.com/sample",
+		ContentText: "Goの並行処理の解説\n\n```go\ngo func() {}()\n```",
+		Paywalled:   true,
+		Tags:        []string{"go"},
+		LikeCount:   10,
+		Language:    "en",
+	}
+}
+
+// PromptFetcher はプロンプトのテンプレートを読み込みます（config.Loaderが実装します）
+type PromptFetcher interface {
+	Fetch(ctx context.Context, source string) ([]byte, error)
+}
+
+// LoadPrompts は設定で指定されたプロンプトのテンプレートを読み込んで検証します
+// 相対パスは設定ファイル（configSource）からの相対パスとして解決し、指定のない項目は組み込みのテンプレートを使います
+// バッチ評価を行う（batchのMaxArticlesが2以上の）場合は、評価プロンプトにバッチ評価のテンプレートが必要です
+func LoadPrompts(ctx context.Context, fetcher PromptFetcher, configSource string, settings config.PromptSettings, batch BatchOptions) (*Prompts, error) {
+	prompts := DefaultPrompts()
+
+	load := func(ref string) (*PromptTemplate, error) {
+		source := config.ResolveSource(configSource, ref)
+		data, err := fetcher.Fetch(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prompt template %s: %w", source, err)
+		}
+		return ParsePromptTemplate(source, data)
+	}
+
+	var err error
+	if settings.Evaluation != "" {
+		if prompts.Evaluation, err = load(settings.Evaluation); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Summary != "" {
+		if prompts.Summary, err = load(settings.Summary); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Triage != "" {
+		if prompts.Triage, err = load(settings.Triage); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := prompts.Validate(); err != nil {
+		return nil, err
+	}
+	if batch.MaxArticles > 1 && !prompts.Evaluation.defines(BatchPromptName) {
+		return nil, fmt.Errorf("prompt template %s does not define template %q (required when llm_settings.batch_size is 2 or more)", prompts.Evaluation.Source, BatchPromptName)
+	}
+	return prompts, nil
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
+)
+
+const (
+	// DefaultOpenAIModel はOpenAI互換バックエンドのデフォルトのモデル名
+	DefaultOpenAIModel = "gpt-4o-mini"
+
+	// DefaultOpenAIEndpoint はOpenAI互換バックエンドのデフォルトのベースURL
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+
+	// DefaultOllamaModel はOllamaバックエンドのデフォルトのモデル名
+	DefaultOllamaModel = "llama3.1"
+
+	// DefaultOllamaEndpoint はOllamaバックエンドのデフォルトのベースURL
+	DefaultOllamaEndpoint = "http://localhost:11434"
+
+	// DefaultRequestTimeout はLLM APIへのリクエストのデフォルトのタイムアウト
+	DefaultRequestTimeout = 30 * time.Second
+
+	// DefaultOllamaRequestTimeout はOllamaへのリクエストのデフォルトのタイムアウト（ローカルのモデルは生成が遅いため長めにする）
+	DefaultOllamaRequestTimeout = 120 * time.Second
+)
+
+// Provider はLLMのバックエンド（Gemini・OpenAI互換・Ollama）を表します
+type Provider interface {
+	// GenerateText はプロンプトに対する応答のテキストを生成します
+	GenerateText(ctx context.Context, prompt string) (*Generation, error)
+
+	// GenerateJSON はプロンプトに対するJSONの応答を生成します
+	// JSONモードに対応したバックエンドでは応答がJSONになるよう指定し、
+	// スキーマに対応したバックエンド（Gemini）では応答をschemaに従わせます
+	// スキーマに対応しないバックエンドの応答は、呼び出し側でParseJSONResponseにより寛容にパースします
+	GenerateJSON(ctx context.Context, prompt string, schema *Schema) (*Generation, error)
+
+	// Model は評価記録に残すモデル名を返します
+	Model() string
+}
+
+// Generation はLLMが生成した応答を表します
+type Generation struct {
+	Text     string
+	Usage    config.TokenUsage
+	Requests int // 送信したAPIリクエストの数（再試行を含む、0の場合は1とみなす）
+}
+
+// ProviderOptions はバックエンドの接続先と制限を表します
+type ProviderOptions struct {
+	Model             string
+	Endpoint          string // APIのベースURL
+	APIKey            string
+	RequestsPerMinute int // 0の場合はレート制限なし
+	MaxOutputTokens   int
+	Timeout           time.Duration
+	MaxResponseSize   int64 // レスポンスボディの上限サイズ（バイト）
+}
+
+// NewProvider は設定に応じたLLMのバックエンドを作成します
+// 設定で省略した項目はバックエンドごとのデフォルト値を使用します
+func NewProvider(settings config.LLMSettings, apiKey string, maxResponseSize int64) (Provider, error) {
+	opts := ProviderOptions{
+		Model:             settings.Model,
+		Endpoint:          settings.Endpoint,
+		APIKey:            apiKey,
+		RequestsPerMinute: settings.RequestsPerMinute,
+		MaxOutputTokens:   settings.MaxOutputTokens,
+		Timeout:           time.Duration(settings.TimeoutSeconds) * time.Second,
+		MaxResponseSize:   maxResponseSize,
+	}
+	if opts.MaxOutputTokens <= 0 {
+		opts.MaxOutputTokens = MaxOutputTokens
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultRequestTimeout
+	}
+
+	switch settings.ProviderName() {
+	case config.LLMProviderGemini:
+		if opts.APIKey == "" {
+			return nil, fmt.Errorf("gemini api key is required")
+		}
+		if opts.Model == "" {
+			opts.Model = ModelName
+		}
+		if opts.Endpoint == "" {
+			opts.Endpoint = GeminiAPIBaseURL
+		}
+		if opts.RequestsPerMinute == 0 {
+			opts.RequestsPerMinute = int(time.Minute / RequestInterval)
+		}
+		return newGeminiClient(opts), nil
+	case config.LLMProviderOpenAI:
+		if opts.Model == "" {
+			opts.Model = DefaultOpenAIModel
+		}
+		if opts.Endpoint == "" {
+			opts.Endpoint = DefaultOpenAIEndpoint
+		}
+		return NewOpenAIClient(opts), nil
+	case config.LLMProviderOllama:
+		if opts.Model == "" {
+			opts.Model = DefaultOllamaModel
+		}
+		if opts.Endpoint == "" {
+			opts.Endpoint = DefaultOllamaEndpoint
+		}
+		if settings.TimeoutSeconds <= 0 {
+			opts.Timeout = DefaultOllamaRequestTimeout
+		}
+		return NewOllamaClient(opts), nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider: %s", settings.Provider)
+	}
+}
+
+// newLimiter は1分あたりのリクエスト数からレート制限を作成します（0以下の場合は制限なし）
+func newLimiter(requestsPerMinute int) *rate.Limiter {
+	if requestsPerMinute <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(requestsPerMinute)), BurstLimit)
+}
+
+// postJSON はリクエストボディをJSONで送信し、レスポンスボディを上限サイズまで読み取って返します
+// HTTPステータスの判定は呼び出し側で行います
+func postJSON(ctx context.Context, httpClient *http.Client, url string, headers map[string]string, reqBody any, maxResponseSize int64) (int, []byte, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := safehttp.ReadBody(resp, maxResponseSize)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
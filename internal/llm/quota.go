@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+// QuotaErrorsBeforeStop は評価を中止するまでに許容する連続したクォータ超過エラーの数
+// （Gemini APIのクライアントは再試行してもクォータ超過が続いた場合にエラーを返すため、それが続いた場合は回復しないとみなす）
+const QuotaErrorsBeforeStop = 2
+
+// QuotaExhausted はクォータ超過が続いたため、この実行でのLLMの呼び出しを中止したかどうかを返します
+func (e *Evaluator) QuotaExhausted() bool {
+	return e.quotaErrors >= QuotaErrorsBeforeStop
+}
+
+// generateJSON はLLMを呼び出し、使用量と連続したクォータ超過エラーの数を記録します
+// 1日あたりの使用量の上限に達した後はerrors.ErrLLMBudgetExceededを、
+// クォータ超過が続いた後はerrors.ErrLLMQuotaExceededを、LLMを呼び出さずに返します
+func (e *Evaluator) generateJSON(ctx context.Context, prompt string, schema *Schema) (*Generation, error) {
+	if e.BudgetExhausted() {
+		return nil, fmt.Errorf("daily llm budget exhausted: %w", errors.ErrLLMBudgetExceeded)
+	}
+	if e.QuotaExhausted() {
+		return nil, fmt.Errorf("llm calls stopped for this run: %w", errors.ErrLLMQuotaExceeded)
+	}
+
+	response, err := e.provider.GenerateJSON(ctx, prompt, schema)
+	e.recordUsage(response)
+	switch {
+	case err == nil:
+		e.quotaErrors = 0
+		logging.FromContext(ctx).Debug("LLMを呼び出しました",
+			"promptTokens", response.Usage.PromptTokens,
+			"candidatesTokens", response.Usage.CandidatesTokens,
+			"totalTokens", response.Usage.TotalTokens,
+		)
+	case IsQuotaExceeded(err):
+		e.quotaErrors++
+	}
+	return response, err
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+// RetryPolicy はLLM APIの一時的なエラーとクォータ超過時の再試行の設定を表します
+type RetryPolicy struct {
+	MaxRetries     int           // 最大再試行回数（0の場合は再試行しない）
+	InitialBackoff time.Duration // 1回目の再試行までの待機時間（以降は2倍ずつ増やす）
+	MaxBackoff     time.Duration // 指数バックオフの待機時間の上限
+	MaxRetryAfter  time.Duration // Retry-After・retryDelayがこれより長い場合は再試行しない（1日あたりのクォータ超過など）
+}
+
+// DefaultRetryPolicy はGemini APIの再試行のデフォルト設定
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 2 * time.Second,
+	MaxBackoff:     30 * time.Second,
+	MaxRetryAfter:  time.Minute,
+}
+
+// backoff はattempt回目（0始まり）の再試行までの待機時間を返します
+// APIが待機時間を指定した場合（retryAfter）は、指数バックオフの待機時間より長ければそちらを使います
+// 指定された待機時間がMaxRetryAfterを超える場合はfalseを返します
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	if retryAfter > p.MaxRetryAfter {
+		return 0, false
+	}
+	wait := p.InitialBackoff << attempt
+	if wait > p.MaxBackoff || wait <= 0 {
+		wait = p.MaxBackoff
+	}
+	return max(wait, retryAfter), true
+}
+
+// APIError はLLM APIの呼び出しの失敗を表します
+// Kindに分類（errors.ErrLLMQuotaExceeded・ErrLLMTransient・ErrLLMAuthFailed・ErrLLMBadRequest）を持ち、
+// errors.Is(err, errors.ErrLLMQuotaExceeded)などで判定できます
+type APIError struct {
+	Kind       *errors.AppError
+	StatusCode int           // HTTPステータス（リクエストの送信に失敗した場合は0）
+	Status     string        // APIのエラーステータス（RESOURCE_EXHAUSTEDなど）
+	Message    string        // APIのエラーメッセージ
+	RetryAfter time.Duration // Retry-AfterヘッダーまたはretryDelayで指定された待機時間
+}
+
+// Error はerrorインターフェースを実装します
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("gemini api request failed: %s", e.Message)
+	}
+	return fmt.Sprintf("gemini api error (status %d): %s - %s", e.StatusCode, e.Status, e.Message)
+}
+
+// Unwrap は分類のセンチネルエラーを返します（errors.Is対応）
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// IsQuotaExceeded はエラーがLLMのクォータ超過によるものかどうかを返します
+func IsQuotaExceeded(err error) bool {
+	return stderrors.Is(err, errors.ErrLLMQuotaExceeded)
+}
+
+// isRetryable は再試行で回復する可能性のあるエラー（一時的なエラー・クォータ超過）かどうかを返します
+func isRetryable(err error) bool {
+	return stderrors.Is(err, errors.ErrLLMTransient) || stderrors.Is(err, errors.ErrLLMQuotaExceeded)
+}
+
+// retryAfterOf はエラーでAPIが指定した待機時間を返します（指定がない場合は0）
+func retryAfterOf(err error) time.Duration {
+	var apiErr *APIError
+	if stderrors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// newGeminiError はGemini APIのエラー応答を分類したエラーを作成します
+func newGeminiError(statusCode int, header http.Header, detail ErrorDetail) error {
+	apiErr := &APIError{
+		Kind:       classifyGeminiError(statusCode, detail),
+		StatusCode: statusCode,
+		Status:     detail.Status,
+		Message:    detail.Message,
+		RetryAfter: parseRetryAfter(header.Get("Retry-After")),
+	}
+	if delay := detail.retryDelay(); delay > apiErr.RetryAfter {
+		apiErr.RetryAfter = delay
+	}
+	return errors.NewLLMError("Gemini APIの呼び出しに失敗しました", apiErr)
+}
+
+// classifyGeminiError はHTTPステータスとエラーの詳細からエラーを分類します
+func classifyGeminiError(statusCode int, detail ErrorDetail) *errors.AppError {
+	switch {
+	// APIキーが不正な場合は400（INVALID_ARGUMENT）に理由API_KEY_INVALIDが付く
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden ||
+		detail.Status == "UNAUTHENTICATED" || detail.Status == "PERMISSION_DENIED" || detail.hasReason("API_KEY_INVALID"):
+		return errors.ErrLLMAuthFailed
+	case statusCode == http.StatusTooManyRequests || detail.Status == "RESOURCE_EXHAUSTED":
+		return errors.ErrLLMQuotaExceeded
+	case statusCode >= http.StatusInternalServerError || statusCode == http.StatusRequestTimeout:
+		return errors.ErrLLMTransient
+	default:
+		return errors.ErrLLMBadRequest
+	}
+}
+
+// parseRetryAfter はRetry-Afterヘッダー（秒数またはHTTP日付）を待機時間に変換します（不正な場合は0）
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// withRetry は一時的なエラーとクォータ超過の場合に、指数バックオフで待機してcallを再試行します
+// callを呼び出した回数（再試行を含む）も返します
+func withRetry[T any](ctx context.Context, policy RetryPolicy, call func() (T, error)) (T, int, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := call()
+		if err == nil || !isRetryable(err) || attempt >= policy.MaxRetries {
+			return result, attempt + 1, err
+		}
+
+		wait, ok := policy.backoff(attempt, retryAfterOf(err))
+		if !ok {
+			// 1日あたりのクォータ超過など、待機しても回復しない場合は再試行しない
+			return result, attempt + 1, err
+		}
+		logging.FromContext(ctx).Warn("LLM APIの呼び出しを再試行します",
+			"attempt", attempt+1,
+			"maxRetries", policy.MaxRetries,
+			"wait", wait,
+			"error", err,
+		)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return result, attempt + 1, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Schema はGemini APIのresponseSchema（OpenAPI 3.0のスキーマのサブセット）を表します
+type Schema struct {
+	Type             string             `json:"type"`
+	Description      string             `json:"description,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	PropertyOrdering []string           `json:"propertyOrdering,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+}
+
+// SchemaFor はGoの値の型からresponseSchemaを生成します
+// プロパティ名はjsonタグ、説明はdescriptionタグから取得し、omitemptyのないフィールドを必須とします
+// 埋め込み構造体のフィールドは親のプロパティとして展開します
+func SchemaFor(v any) *Schema {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+// schemaForType は型に対応するスキーマを返します
+func schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &Schema{Type: "OBJECT", Properties: map[string]*Schema{}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			// jsonタグのない埋め込み構造体のフィールドは、encoding/jsonと同様に展開する
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				embedded := schemaForType(field.Type)
+				for _, p := range embedded.PropertyOrdering {
+					schema.Properties[p] = embedded.Properties[p]
+					schema.PropertyOrdering = append(schema.PropertyOrdering, p)
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			prop := schemaForType(field.Type)
+			prop.Description = field.Tag.Get("description")
+			schema.Properties[name] = prop
+			schema.PropertyOrdering = append(schema.PropertyOrdering, name)
+			if !strings.Contains(opts, "omitempty") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "ARRAY", Items: schemaForType(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "STRING"}
+	case reflect.Bool:
+		return &Schema{Type: "BOOLEAN"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "INTEGER"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "NUMBER"}
+	default:
+		return &Schema{Type: "OBJECT"}
+	}
+}
+
+// ParseJSONResponse はLLMの応答からJSONを取り出してvにデコードします
+// スキーマを指定できないバックエンドでは、JSONの前後に説明文やマークダウンのコードブロックが付くことがあるため、
+// 応答全体のデコードに失敗した場合は、応答中で最初にデコードできるJSONのオブジェクト・配列を使用します
+func ParseJSONResponse(text string, v any) error {
+	text = extractJSONFromMarkdown(text)
+	err := json.Unmarshal([]byte(text), v)
+	if err == nil {
+		return nil
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '{' && text[i] != '[' {
+			continue
+		}
+		// Decoderは最初のJSONの値だけを読み、後続のテキストは無視する
+		var raw json.RawMessage
+		if json.NewDecoder(strings.NewReader(text[i:])).Decode(&raw) != nil {
+			continue
+		}
+		resetValue(v)
+		if json.Unmarshal(raw, v) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no valid json in response: %w", err)
+}
+
+// resetValue はデコードに失敗して途中まで書き込まれた値をゼロ値に戻します
+func resetValue(v any) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	}
+}
+
+// extractJSONFromMarkdown はマークダウンコードブロックからJSONを抽出します
+// Gemini 2.0は ```json ... ``` の形式でJSONを返すことがあるため、これを除去します
+func extractJSONFromMarkdown(text string) string {
+	text = strings.TrimSpace(text)
+
+	// ```json で始まり ``` で終わる場合
+	if strings.HasPrefix(text, "```json") && strings.HasSuffix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimSuffix(text, "```")
+		return strings.TrimSpace(text)
+	}
+
+	// ``` で始まり ``` で終わる場合（言語指定なし）
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(text, "```")
+		return strings.TrimSpace(text)
+	}
+
+	// マークダウンコードブロックがない場合はそのまま返す
+	return text
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// MaxRankingScore はランキングスコアの上限
+const MaxRankingScore = 100
+
+// NormalizeMatchingTopics はLLMが返した一致トピックを興味トピックの正式名に正規化します
+// 別名や大文字・小文字の違いで返されたトピックは正式名に置き換え、興味トピックにないトピック（幻覚トピック）は除外します
+// 結果は重複を除き、LLMが返した順序を保ちます
+func NormalizeMatchingTopics(matched []string, topics []config.InterestTopic) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, name := range matched {
+		topic := findTopic(name, topics)
+		if topic == nil || seen[topic.Topic] {
+			continue
+		}
+		seen[topic.Topic] = true
+		normalized = append(normalized, topic.Topic)
+	}
+	return normalized
+}
+
+// findTopic はトピック名または別名が一致する興味トピックを返します（見つからない場合はnil）
+func findTopic(name string, topics []config.InterestTopic) *config.InterestTopic {
+	name = strings.TrimSpace(name)
+	for i := range topics {
+		if strings.EqualFold(name, topics[i].Topic) {
+			return &topics[i]
+		}
+		for _, alias := range topics[i].Aliases {
+			if strings.EqualFold(name, strings.TrimSpace(alias)) {
+				return &topics[i]
+			}
+		}
+	}
+	return nil
+}
+
+// RankingScore はLLMのスコアと一致トピックの優先度から、通知する記事の順位付けに使うスコアと計算の説明を返します
+// 一致トピックのうち最も高い優先度の倍率をLLMのスコアに掛け、0〜MaxRankingScoreに収めます
+// matchedには正規化済み（NormalizeMatchingTopicsの結果）の一致トピックを指定します
+func RankingScore(llmScore int, matched []string, topics []config.InterestTopic) (int, string) {
+	if len(matched) == 0 {
+		return 0, fmt.Sprintf("LLMスコア%d、興味トピックに一致しないため0", llmScore)
+	}
+
+	var best *config.InterestTopic
+	for _, name := range matched {
+		topic := findTopic(name, topics)
+		if topic != nil && (best == nil || topic.GetPriorityMultiplier() > best.GetPriorityMultiplier()) {
+			best = topic
+		}
+	}
+	if best == nil {
+		return 0, fmt.Sprintf("LLMスコア%d、興味トピックに一致しないため0", llmScore)
+	}
+
+	multiplier := best.GetPriorityMultiplier()
+	raw := int(math.Round(float64(llmScore) * multiplier))
+	explanation := fmt.Sprintf("LLMスコア%d × 倍率%.1f（%s: 優先度%s）= %d", llmScore, multiplier, best.Topic, best.Priority, raw)
+
+	score := min(max(raw, 0), MaxRankingScore)
+	if score != raw {
+		explanation += fmt.Sprintf(" → %d（0〜%dに制限）", score, MaxRankingScore)
+	}
+	return score, explanation
+}
+
+// topicNamesJSON は興味トピックの正式名のJSON配列を返します
+func topicNamesJSON(topics []config.InterestTopic) []byte {
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Topic
+	}
+	namesJSON, _ := json.Marshal(names) // []string のマーシャルは常に成功する
+	return namesJSON
+}
+
+// topicAliasesNote は別名が設定された興味トピックがある場合に、別名をプロンプトに伝える行を返します
+func topicAliasesNote(topics []config.InterestTopic) string {
+	aliases := topicAliasesList(topics)
+	if aliases == "" {
+		return ""
+	}
+	return "\nトピックの別名: " + aliases + "（別名での言及もそのトピックへの言及とみなし、matching_topicsには別名ではなくトピック名を記載すること）"
+}
+
+// triageAliasesNote は別名が設定された興味トピックがある場合に、別名をトリアージのプロンプトに伝える行を返します
+func triageAliasesNote(topics []config.InterestTopic) string {
+	aliases := topicAliasesList(topics)
+	if aliases == "" {
+		return ""
+	}
+	return "\nトピックの別名: " + aliases + "（別名での言及もそのトピックへの言及とみなすこと）"
+}
+
+// topicAliasesList は興味トピックの別名を「トピック名 = 別名, 別名 / ...」の形式で返します（別名がない場合は空）
+func topicAliasesList(topics []config.InterestTopic) string {
+	var parts []string
+	for _, topic := range topics {
+		if len(topic.Aliases) > 0 {
+			parts = append(parts, topic.Topic+" = "+strings.Join(topic.Aliases, ", "))
+		}
+	}
+	return strings.Join(parts, " / ")
+}
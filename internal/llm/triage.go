@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+const (
+	// DefaultTriageBatchSize はトリアージで1回の呼び出しで判定する記事のデフォルトの最大数
+	DefaultTriageBatchSize = 30
+
+	// MaxTriageDescriptionLength はトリアージのプロンプトに含めるフィードの要約の最大文字数
+	MaxTriageDescriptionLength = 300
+)
+
+// TriageOptions は本文を取得する前のトリアージの設定を表します（Modeが空の場合はトリアージを行わない）
+type TriageOptions struct {
+	Mode      string   // config.TriageModeLLM または config.TriageModeKeyword
+	BatchSize int      // LLMで1回の呼び出しで判定する記事の最大数
+	Keywords  []string // 興味トピック名・別名に加えて一致を調べるキーワード
+}
+
+// TriageOptionsFromSettings はトリアージの設定からTriageOptionsを作成します
+func TriageOptionsFromSettings(settings config.TriageSettings) TriageOptions {
+	return TriageOptions{
+		Mode:      settings.Mode,
+		BatchSize: settings.BatchSize,
+		Keywords:  settings.Keywords,
+	}
+}
+
+// SetTriageOptions はTriageArticlesのトリアージの設定を設定します
+// BatchSizeが0以下の場合はDefaultTriageBatchSizeを使います
+func (e *Evaluator) SetTriageOptions(opts TriageOptions) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultTriageBatchSize
+	}
+	e.triage = opts
+}
+
+// TriageEnabled はトリアージを行うかどうかを返します
+func (e *Evaluator) TriageEnabled() bool {
+	return e.triage.Mode != ""
+}
+
+// TriageItem はトリアージする記事のタイトルとフィードの要約を表します
+type TriageItem struct {
+	Title       string
+	Description string
+}
+
+// TriageResult はLLMからのトリアージの結果を表します
+// OpenAI互換APIのJSONモードは応答の最上位がオブジェクトである必要があるため、配列をオブジェクトで包みます
+type TriageResult struct {
+	Articles []TriageDecision `json:"articles" description:"記事ごとの判定（記事ごとに1つずつ）"`
+}
+
+// TriageDecision はトリアージの記事ごとの判定を表します
+type TriageDecision struct {
+	ArticleID string `json:"article_id" description:"判定した記事の記事ID"`
+	Plausible bool   `json:"plausible" description:"トピックに関連する可能性がある、または判断できない場合はtrue"`
+}
+
+// triageResultSchema はトリアージの結果のresponseSchema
+var triageResultSchema = SchemaFor(TriageResult{})
+
+// TriageArticles は記事のタイトルとフィードの要約から、本文を取得して評価する記事を判定します
+// 記事と同じ順序で、評価の候補として残す記事はtrueを返します（トリアージを行わない場合はすべてtrue）
+// LLMの呼び出しに失敗した記事や応答に含まれない記事は、評価の漏れを避けるため候補として残します
+func (e *Evaluator) TriageArticles(ctx context.Context, items []TriageItem, topics []config.InterestTopic) []bool {
+	keep := make([]bool, len(items))
+	switch e.triage.Mode {
+	case config.TriageModeKeyword:
+		keywords := triageKeywords(topics, e.triage.Keywords)
+		for i, item := range items {
+			keep[i] = matchesAnyKeyword(item.Title+"\n"+item.Description, keywords)
+		}
+	case config.TriageModeLLM:
+		for start := 0; start < len(items); start += e.triage.BatchSize {
+			end := min(start+e.triage.BatchSize, len(items))
+			e.triageBatch(ctx, items[start:end], topics, keep[start:end])
+		}
+	default:
+		for i := range keep {
+			keep[i] = true
+		}
+	}
+	return keep
+}
+
+// triageBatch は複数の記事を1回のLLM呼び出しで判定してkeepに設定します
+func (e *Evaluator) triageBatch(ctx context.Context, items []TriageItem, topics []config.InterestTopic, keep []bool) {
+	for i := range keep {
+		keep[i] = true
+	}
+
+	prompt, err := e.prompts.Triage.execute("", newTriagePromptData(items, topics))
+	var response *Generation
+	if err == nil {
+		response, err = e.generateJSON(ctx, prompt, triageResultSchema)
+	}
+	var parsed TriageResult
+	if err == nil {
+		err = ParseJSONResponse(response.Text, &parsed)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("トリアージに失敗しました。すべての記事を評価の候補とします", "articleCount", len(items), "error", err)
+		return
+	}
+
+	for _, decision := range parsed.Articles {
+		if j, ok := triageArticleIndex(decision.ArticleID); ok && j < len(keep) && !decision.Plausible {
+			keep[j] = false
+		}
+	}
+}
+
+// triageArticleID はトリアージ内のj番目（0始まり）の記事の記事IDを返します
+func triageArticleID(j int) string {
+	return fmt.Sprintf("T%d", j+1)
+}
+
+// triageArticleIndex は記事IDからトリアージ内の記事の位置（0始まり）を返します
+func triageArticleIndex(id string) (int, bool) {
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimSpace(id), "T%d", &n); err != nil || n < 1 {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// triageKeywords はキーワードによるトリアージで一致を調べるキーワード（興味トピック名・別名・追加のキーワード）を返します
+func triageKeywords(topics []config.InterestTopic, extra []string) []string {
+	var keywords []string
+	for _, topic := range topics {
+		keywords = append(keywords, topic.Topic)
+		keywords = append(keywords, topic.Aliases...)
+	}
+	keywords = append(keywords, extra...)
+
+	normalized := keywords[:0]
+	for _, keyword := range keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			normalized = append(normalized, keyword)
+		}
+	}
+	return normalized
+}
+
+// matchesAnyKeyword はテキストがいずれかのキーワードを含むかどうかを返します（大文字・小文字は区別しない）
+// 英数字のキーワードは単語の一部には一致させません（「Go」は「Google」に一致しない）
+func matchesAnyKeyword(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, keyword := range keywords {
+		if containsWord(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord はtextがkeywordを含み、その前後が英数字でないかどうかを返します
+func containsWord(text, keyword string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], keyword)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(keyword)
+		if !isASCIIAlnumBefore(text, start) && !isASCIIAlnumAt(text, end) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isASCIIAlnumBefore はtextのi番目のバイトの直前が英数字かどうかを返します
+func isASCIIAlnumBefore(text string, i int) bool {
+	return i > 0 && isASCIIAlnum(text[i-1])
+}
+
+// isASCIIAlnumAt はtextのi番目のバイトが英数字かどうかを返します
+func isASCIIAlnumAt(text string, i int) bool {
+	return i < len(text) && isASCIIAlnum(text[i])
+}
+
+// isASCIIAlnum はバイトが英数字かどうかを返します
+func isASCIIAlnum(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// truncateRunes は文字列を最大maxLen文字に切り詰めます（切り詰めた場合は末尾に...を付ける）
+func truncateRunes(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	return string([]rune(s)[:maxLen]) + "..."
+}
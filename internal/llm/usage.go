@@ -0,0 +1,70 @@
+package llm
+
+import (
+	stderrors "errors"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+)
+
+// Budget はLLMの1日あたりの使用量の上限を表します（0の項目は無制限）
+type Budget struct {
+	DailyTokens   int // 1日あたりの合計トークン数の上限
+	DailyRequests int // 1日あたりのLLM呼び出し回数の上限
+}
+
+// BudgetFromSettings はLLMの設定から1日あたりの使用量の上限を作成します
+func BudgetFromSettings(settings config.LLMSettings) Budget {
+	return Budget{
+		DailyTokens:   settings.DailyTokenBudget,
+		DailyRequests: settings.DailyRequestBudget,
+	}
+}
+
+// SetBudget は1日あたりの使用量の上限と、この実行の前までの当日の使用量を設定します
+// 当日の使用量とこの実行の使用量の合計が上限に達した後は、LLMを呼び出さずにerrors.ErrLLMBudgetExceededを返します
+func (e *Evaluator) SetBudget(budget Budget, usedToday config.DailyUsage) {
+	e.budget = budget
+	e.usedToday = usedToday
+}
+
+// Usage はこの実行でのLLMのトークン使用量と呼び出し回数を返します
+func (e *Evaluator) Usage() (config.TokenUsage, int) {
+	return e.usage, e.requests
+}
+
+// DailyUsage はこの実行の使用量を含めた当日のLLMの使用量を返します
+func (e *Evaluator) DailyUsage() config.DailyUsage {
+	daily := e.usedToday
+	daily.PromptTokens += e.usage.PromptTokens
+	daily.CandidatesTokens += e.usage.CandidatesTokens
+	daily.TotalTokens += e.usage.TotalTokens
+	daily.Requests += e.requests
+	return daily
+}
+
+// BudgetExhausted は当日のLLMの使用量が1日あたりの上限に達したかどうかを返します
+// トークン数は呼び出し後にしか分からないため、上限に達するまでの最後の呼び出しで上限を少し超える場合があります
+func (e *Evaluator) BudgetExhausted() bool {
+	daily := e.DailyUsage()
+	if e.budget.DailyTokens > 0 && daily.TotalTokens >= e.budget.DailyTokens {
+		return true
+	}
+	return e.budget.DailyRequests > 0 && daily.Requests >= e.budget.DailyRequests
+}
+
+// IsBudgetExceeded はエラーがLLMの1日あたりの使用量の上限によるものかどうかを返します
+func IsBudgetExceeded(err error) bool {
+	return stderrors.Is(err, errors.ErrLLMBudgetExceeded)
+}
+
+// recordUsage はLLMの呼び出し1回分の使用量をこの実行の使用量に加算します
+// バックエンドが再試行した場合は、送信したリクエストの数だけ呼び出し回数に加算します
+func (e *Evaluator) recordUsage(response *Generation) {
+	requests := 1
+	if response != nil {
+		e.usage = e.usage.Add(response.Usage)
+		requests = max(requests, response.Requests)
+	}
+	e.requests += requests
+}
@@ -3,28 +3,37 @@ package rss
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
 	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/httpcache"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
+	"github.com/kaka0913/discord-article-bot/internal/safehttp"
 )
 
 // Fetcher はRSSフィードのHTTPリクエストを担当する
 type Fetcher struct {
-	client *http.Client
+	client  *http.Client
+	maxSize int64 // RSSフィードの上限サイズ（バイト）
 }
 
 // NewFetcher は新しいFetcherインスタンスを作成する
-func NewFetcher(timeout time.Duration) *Fetcher {
+// 内部ネットワークへのリクエストを防ぐため、SSRF対策を施したHTTPクライアントを使用する
+// maxSizeを超えるフィードは読み取りの途中で中止する
+func NewFetcher(timeout time.Duration, maxSize int64) *Fetcher {
 	return &Fetcher{
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  safehttp.NewClient(safehttp.Options{Timeout: timeout}),
+		maxSize: maxSize,
 	}
 }
 
+// SetCache はHTTPレスポンスのキャッシュを設定する（ローカル開発用）
+// SSRF対策はキャッシュにないURLを取得する際に適用される
+func (f *Fetcher) SetCache(cache *httpcache.Cache) {
+	f.client.Transport = cache.Wrap(f.client.Transport)
+}
+
 // Fetch は指定されたRSSフィードURLからXMLコンテンツを取得する
 // エラーが発生した場合はエラーを返す
 func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
@@ -44,6 +53,9 @@ func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 	// HTTPリクエストを実行
 	resp, err := f.client.Do(req)
 	if err != nil {
+		if safehttp.IsBlocked(err) {
+			logger.Warn("セキュリティ上の理由でRSSフィードの取得を拒否しました", "url", url, "rejection", "security", "error", err)
+		}
 		return nil, errors.NewRSSError("RSSフィードの取得に失敗", err)
 	}
 	defer resp.Body.Close()
@@ -57,8 +69,11 @@ func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 	}
 
 	// レスポンスボディを読み取り
-	body, err := io.ReadAll(resp.Body)
+	body, err := safehttp.ReadBody(resp, f.maxSize)
 	if err != nil {
+		if safehttp.IsTooLarge(err) {
+			return nil, errors.NewRSSError(fmt.Sprintf("RSSフィードが大きすぎる: %s", url), err)
+		}
 		return nil, errors.NewRSSError("レスポンスボディの読み取りに失敗", err)
 	}
 
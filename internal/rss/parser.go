@@ -7,6 +7,8 @@ import (
 	"unicode/utf8"
 
 	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 
 	"github.com/kaka0913/discord-article-bot/internal/errors"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
@@ -16,6 +18,9 @@ const (
 	// maxTitleLength は記事タイトルの最大長
 	// 500文字以上のタイトルは異常に長いため切り詰める
 	maxTitleLength = 500
+
+	// maxSummaryLength はフィードの要約の最大長（バイト）
+	maxSummaryLength = 5000
 )
 
 // Article は取得したRSS記事を表す
@@ -25,6 +30,7 @@ type Article struct {
 	PublishedDate time.Time // 公開日時
 	SourceFeed    string    // ソースフィード名
 	FetchedAt     time.Time // 取得日時
+	Summary       string    // フィードに含まれる記事の要約（HTMLタグを除いたテキスト）
 }
 
 // Parser はRSSフィードのXMLをパースする
@@ -95,6 +101,7 @@ func (p *Parser) Parse(ctx context.Context, xmlData []byte, sourceFeedName strin
 			PublishedDate: publishedDate,
 			SourceFeed:    sourceFeedName,
 			FetchedAt:     now,
+			Summary:       itemSummary(item),
 		}
 
 		articles = append(articles, article)
@@ -109,6 +116,38 @@ func (p *Parser) Parse(ctx context.Context, xmlData []byte, sourceFeedName strin
 	return articles, nil
 }
 
+// itemSummary はフィードの項目から要約をテキストとして取得する
+// descriptionがない場合はcontentを使用し、HTMLタグを除いて連続する空白を1つにまとめる
+func itemSummary(item *gofeed.Item) string {
+	summary := item.Description
+	if strings.TrimSpace(summary) == "" {
+		summary = item.Content
+	}
+	text := strings.Join(strings.Fields(htmlText(summary)), " ")
+	return truncateUTF8(text, maxSummaryLength)
+}
+
+// htmlText はHTML断片からテキストのみを取り出す（プレーンテキストの場合はそのまま返す）
+func htmlText(fragment string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			b.Write(z.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			// ブロック要素の境界で単語が連結されないよう空白を入れる
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Br, atom.P, atom.Div, atom.Li, atom.Blockquote, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
+
 // truncateUTF8 はUTF-8文字列を安全に切り詰める
 // マルチバイト文字の途中で切断されることを防ぐ
 func truncateUTF8(s string, maxBytes int) string {
@@ -0,0 +1,65 @@
+package safehttp
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+)
+
+// TooLargeError はレスポンスボディが上限サイズを超えたことを表す
+// errors.Is(err, errors.ErrResponseTooLarge) で判定できる
+type TooLargeError struct {
+	Limit int64 // 上限サイズ（バイト）
+	Size  int64 // Content-Lengthで判明したサイズ（不明な場合は0）
+}
+
+// Error はエラーメッセージを返す
+func (e *TooLargeError) Error() string {
+	if e.Size > 0 {
+		return fmt.Sprintf("レスポンスが大きすぎます: %d bytes (最大 %d bytes)", e.Size, e.Limit)
+	}
+	return fmt.Sprintf("レスポンスが大きすぎます: 最大 %d bytes を超えました", e.Limit)
+}
+
+// Unwrap はerrors.Isでの判定用にセンチネルエラーを返す
+func (e *TooLargeError) Unwrap() error {
+	return errors.ErrResponseTooLarge
+}
+
+// IsTooLarge はエラーがレスポンスサイズの上限超過かどうかを返す
+func IsTooLarge(err error) bool {
+	var tooLarge *TooLargeError
+	return stderrors.As(err, &tooLarge)
+}
+
+// ReadBody はレスポンスボディを上限サイズまで読み取る
+// Content-Lengthが上限を超える場合は読み取らずに、それ以外は上限を1バイトでも超えた時点で読み取りを中止して
+// TooLargeErrorを返す。limitが0以下の場合は制限しない
+func ReadBody(resp *http.Response, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	if resp.ContentLength > limit {
+		return nil, &TooLargeError{Limit: limit, Size: resp.ContentLength}
+	}
+	return ReadAll(resp.Body, limit)
+}
+
+// ReadAll はReaderから上限サイズまで読み取り、上限を超えた場合はTooLargeErrorを返す
+// limitが0以下の場合は制限しない
+func ReadAll(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &TooLargeError{Limit: limit}
+	}
+	return data, nil
+}
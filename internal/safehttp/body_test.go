@@ -0,0 +1,66 @@
+package safehttp
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+)
+
+func TestReadBody(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		limit         int64
+		wantErr       bool
+	}{
+		{"上限以内", "hello", -1, 5, false},
+		{"上限を超える（Content-Lengthなし）", "hello!", -1, 5, true},
+		{"Content-Lengthが上限を超える", "", 1 << 30, 5, true},
+		{"制限なし", strings.Repeat("a", 100), -1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Body:          io.NopCloser(strings.NewReader(tt.body)),
+				ContentLength: tt.contentLength,
+			}
+			data, err := ReadBody(resp, tt.limit)
+			if tt.wantErr {
+				if !IsTooLarge(err) || !stderrors.Is(err, errors.ErrResponseTooLarge) {
+					t.Errorf("TooLargeErrorが返りませんでした: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadBody failed: %v", err)
+			}
+			if string(data) != tt.body {
+				t.Errorf("読み取った内容 = %q, 期待 %q", data, tt.body)
+			}
+		})
+	}
+}
+
+func TestReadAll_StopsAtLimit(t *testing.T) {
+	// 無限に続くReaderでも上限+1バイトで読み取りを中止する
+	_, err := ReadAll(infiniteReader{}, 1024)
+	if !IsTooLarge(err) {
+		t.Errorf("TooLargeErrorが返りませんでした: %v", err)
+	}
+}
+
+// infiniteReader は終わりのないデータを返すReader
+type infiniteReader struct{}
+
+func (infiniteReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
@@ -0,0 +1,242 @@
+// Package safehttp はSSRF（サーバーサイドリクエストフォージェリ）対策を施したHTTPクライアントを提供する
+//
+// RSSフィードや記事のURLは外部から与えられるため、そのまま取得するとメタデータサーバー（169.254.169.254）や
+// ローカルホスト、VPC内のプライベートアドレスにリクエストが届いてしまう。
+// このパッケージのクライアントは、接続時に解決済みのIPアドレスを検査し、リダイレクトのたびに
+// スキーム・回数・リダイレクト先のアドレスを検査することで、内部ネットワークへのアクセスを拒否する
+package safehttp
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+)
+
+const (
+	// DefaultMaxRedirects はリダイレクトを追跡する最大回数
+	DefaultMaxRedirects = 5
+
+	// dialTimeout はTCP接続の確立を待つ最大時間
+	dialTimeout = 10 * time.Second
+)
+
+// allowedSchemes はリクエストを許可するURLスキーム
+var allowedSchemes = map[string]bool{
+	"http":  true,
+	"https": true,
+}
+
+// blockedPrefixes はnetip.Addrの判定メソッドでは検出できない、接続を拒否するアドレス範囲
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // 「このネットワーク」
+	netip.MustParsePrefix("100.64.0.0/10"),  // キャリアグレードNAT（共有アドレス）
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETFプロトコル割り当て
+	netip.MustParsePrefix("198.18.0.0/15"),  // ベンチマーク用
+	netip.MustParsePrefix("240.0.0.0/4"),    // 予約済み（ブロードキャストを含む）
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64（IPv4アドレスを埋め込めるため）
+	netip.MustParsePrefix("64:ff9b:1::/48"), // ローカル用NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // ドキュメント用
+}
+
+// BlockedError は安全でない宛先へのリクエストを拒否したことを表す
+// errors.Is(err, errors.ErrBlockedDestination) で判定できる
+type BlockedError struct {
+	URL     string // 拒否したリクエストのURL（接続時に拒否した場合は空）
+	Address string // 拒否したIPアドレス（スキームや回数で拒否した場合は空）
+	Reason  string // 拒否した理由
+}
+
+// Error はエラーメッセージを返す
+func (e *BlockedError) Error() string {
+	target := e.URL
+	if target == "" {
+		target = e.Address
+	} else if e.Address != "" {
+		target = fmt.Sprintf("%s (%s)", e.URL, e.Address)
+	}
+	return fmt.Sprintf("安全でない宛先へのリクエストを拒否: %s: %s", target, e.Reason)
+}
+
+// Unwrap はerrors.Isでの判定用にセンチネルエラーを返す
+func (e *BlockedError) Unwrap() error {
+	return errors.ErrBlockedDestination
+}
+
+// IsBlocked はエラーがSSRF対策による拒否かどうかを返す
+func IsBlocked(err error) bool {
+	var blocked *BlockedError
+	return stderrors.As(err, &blocked)
+}
+
+// Options はHTTPクライアントの設定
+type Options struct {
+	// Timeout はリクエスト全体のタイムアウト
+	Timeout time.Duration
+	// MaxRedirects はリダイレクトを追跡する最大回数（0以下の場合はDefaultMaxRedirects）
+	MaxRedirects int
+	// AllowPrivateNetworks はプライベート・ループバックアドレスへの接続を許可する
+	// httptestのサーバーに接続するテスト専用で、本番では使用しない
+	AllowPrivateNetworks bool
+}
+
+// NewClient はSSRF対策を施したHTTPクライアントを作成する
+func NewClient(opts Options) *http.Client {
+	maxRedirects := opts.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	g := &guard{allowPrivate: opts.AllowPrivateNetworks, resolver: net.DefaultResolver}
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: &transport{guard: g, base: newTransport(g)},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return &BlockedError{URL: req.URL.String(), Reason: fmt.Sprintf("リダイレクトが多すぎます（最大 %d 回）", maxRedirects)}
+			}
+			return g.checkRedirect(req)
+		},
+	}
+}
+
+// newTransport は接続先のIPアドレスを検査するhttp.Transportを作成する
+// 環境変数のプロキシを経由すると接続先の検査ができないため、プロキシは使用しない
+func newTransport(g *guard) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   g.control,
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = nil
+	base.DialContext = dialer.DialContext
+	return base
+}
+
+// guard は宛先の安全性を検査する
+type guard struct {
+	allowPrivate bool
+	resolver     *net.Resolver
+}
+
+// control はDNS解決後、実際に接続するIPアドレスを検査する
+// DNSの応答が検査後に変わる（DNSリバインディング）場合でも、接続の直前に検査するため回避できない
+func (g *guard) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return &BlockedError{Address: address, Reason: "接続先のアドレスを解析できません"}
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return &BlockedError{Address: address, Reason: "接続先のアドレスを解析できません"}
+	}
+	if reason := g.blockedReason(addr); reason != "" {
+		return &BlockedError{Address: addr.String(), Reason: reason}
+	}
+	return nil
+}
+
+// checkURL はURLのスキームと、IPアドレスで指定されたホストを検査する
+func (g *guard) checkURL(u *url.URL) error {
+	if !allowedSchemes[u.Scheme] {
+		return &BlockedError{URL: u.String(), Reason: fmt.Sprintf("許可されていないスキームです: %q", u.Scheme)}
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if reason := g.blockedReason(addr); reason != "" {
+			return &BlockedError{URL: u.String(), Address: addr.String(), Reason: reason}
+		}
+	}
+	return nil
+}
+
+// checkRedirect はリダイレクト先のURLを検査する
+// ホスト名を解決し、いずれかのアドレスが安全でない場合は追跡しない
+func (g *guard) checkRedirect(req *http.Request) error {
+	if err := g.checkURL(req.URL); err != nil {
+		return err
+	}
+	host := req.URL.Hostname()
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+
+	addrs, err := g.resolver.LookupNetIP(req.Context(), "ip", host)
+	if err != nil {
+		return fmt.Errorf("リダイレクト先のホスト名の解決に失敗: %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if reason := g.blockedReason(addr); reason != "" {
+			return &BlockedError{URL: req.URL.String(), Address: addr.String(), Reason: reason}
+		}
+	}
+	return nil
+}
+
+// blockedReason はアドレスへの接続を拒否する理由を返す（許可する場合は空文字列）
+func (g *guard) blockedReason(addr netip.Addr) string {
+	if g.allowPrivate {
+		return ""
+	}
+	return blockedReason(addr)
+}
+
+// blockedReason はアドレスへの接続を拒否する理由を返す（許可する場合は空文字列）
+// IPv4射影IPv6アドレス（::ffff:127.0.0.1など）はIPv4アドレスとして判定する
+func blockedReason(addr netip.Addr) string {
+	addr = addr.Unmap()
+	switch {
+	case !addr.IsValid():
+		return "無効なアドレスです"
+	case addr.IsLoopback():
+		return "ループバックアドレスです"
+	case addr.IsPrivate():
+		return "プライベートアドレスです"
+	case addr.IsLinkLocalUnicast():
+		// 169.254.169.254（クラウドのメタデータサーバー）を含む
+		return "リンクローカルアドレスです"
+	case addr.IsUnspecified():
+		return "未指定アドレスです"
+	case addr.IsMulticast():
+		return "マルチキャストアドレスです"
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return "予約済みのアドレスです"
+		}
+	}
+	return ""
+}
+
+// transport は最初のリクエストのURLを検査してから送信するhttp.RoundTripper
+type transport struct {
+	guard *guard
+	base  http.RoundTripper
+}
+
+// RoundTrip はURLを検査し、安全な場合のみリクエストを送信する
+// 接続時に拒否した場合は、ログで追跡できるようにURLを記録したエラーを返す
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.checkURL(req.URL); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		var blocked *BlockedError
+		if stderrors.As(err, &blocked) && blocked.URL == "" {
+			return nil, &BlockedError{URL: req.URL.String(), Address: blocked.Address, Reason: blocked.Reason}
+		}
+		return nil, err
+	}
+	return resp, nil
+}
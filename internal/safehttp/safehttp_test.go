@@ -0,0 +1,137 @@
+package safehttp
+
+import (
+	stderrors "errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+)
+
+func TestBlockedReason(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.0.0.1", true},
+		{"172.16.5.4", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true}, // メタデータサーバー
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"255.255.255.255", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true}, // IPv4射影IPv6アドレス
+		{"::ffff:169.254.169.254", true},
+		{"64:ff9b::a9fe:a9fe", true}, // NAT64経由の169.254.169.254
+		{"8.8.8.8", false},
+		{"93.184.216.34", false},
+		{"2001:4860:4860::8888", false},
+	}
+
+	for _, tt := range tests {
+		reason := blockedReason(netip.MustParseAddr(tt.addr))
+		if (reason != "") != tt.blocked {
+			t.Errorf("blockedReason(%s) = %q, 拒否の期待値 %v", tt.addr, reason, tt.blocked)
+		}
+	}
+}
+
+func TestClient_BlocksPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := NewClient(Options{Timeout: 5 * time.Second})
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"IPアドレス指定", server.URL},
+		// ホスト名は接続時に解決されたアドレスで拒否する
+		{"ホスト名指定", "http://localhost:" + serverURL.Port()},
+		{"許可されていないスキーム", "ftp://example.com/feed.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(tt.url)
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("安全でない宛先へのリクエストがエラーになりませんでした")
+			}
+			if !IsBlocked(err) {
+				t.Errorf("BlockedErrorではありません: %v", err)
+			}
+			if !stderrors.Is(err, errors.ErrBlockedDestination) {
+				t.Errorf("errors.ErrBlockedDestinationとして判定できません: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_Redirects(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, server.URL+"/loop", http.StatusFound)
+		case "/scheme":
+			http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+		case "/once":
+			http.Redirect(w, r, server.URL+"/ok", http.StatusFound)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{Timeout: 5 * time.Second, MaxRedirects: 3, AllowPrivateNetworks: true})
+
+	resp, err := client.Get(server.URL + "/once")
+	if err != nil {
+		t.Fatalf("リダイレクトの追跡に失敗: %v", err)
+	}
+	resp.Body.Close()
+
+	for _, path := range []string{"/loop", "/scheme"} {
+		resp, err := client.Get(server.URL + path)
+		if err == nil {
+			resp.Body.Close()
+			t.Fatalf("%s: リダイレクトが拒否されませんでした", path)
+		}
+		if !IsBlocked(err) {
+			t.Errorf("%s: BlockedErrorではありません: %v", path, err)
+		}
+	}
+}
+
+func TestGuard_CheckRedirect(t *testing.T) {
+	g := &guard{resolver: net.DefaultResolver}
+
+	for _, target := range []string{
+		"http://169.254.169.254/computeMetadata/v1/",
+		"http://[::1]/",
+		"http://localhost/admin",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		err := g.checkRedirect(req)
+		if !IsBlocked(err) {
+			t.Errorf("%s へのリダイレクトが拒否されませんでした: %v", target, err)
+		} else if !strings.Contains(err.Error(), target) {
+			t.Errorf("エラーにURLが含まれていません: %v", err)
+		}
+	}
+}
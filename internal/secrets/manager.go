@@ -4,6 +4,8 @@ package secrets
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
@@ -62,6 +64,35 @@ func (m *manager) Close() error {
 	return nil
 }
 
+// envManager は環境変数からシークレットを取得する実装（Secret Managerを使わないセルフホスト用）
+type envManager struct{}
+
+// NewEnvManager は環境変数からシークレットを取得するManagerを作成します
+// シークレット名を大文字にし、ハイフンをアンダースコアに置き換えた環境変数を参照します（例: discord-webhook-url → DISCORD_WEBHOOK_URL）
+func NewEnvManager() Manager {
+	return &envManager{}
+}
+
+// GetSecret はシークレット名に対応する環境変数の値を返します
+func (m *envManager) GetSecret(ctx context.Context, secretName string) (string, error) {
+	envName := EnvName(secretName)
+	value := os.Getenv(envName)
+	if value == "" {
+		return "", fmt.Errorf("シークレット '%s' の環境変数 %s が設定されていません", secretName, envName)
+	}
+	return value, nil
+}
+
+// Close は何もしません（環境変数用）
+func (m *envManager) Close() error {
+	return nil
+}
+
+// EnvName はシークレット名に対応する環境変数名を返します
+func EnvName(secretName string) string {
+	return strings.ToUpper(strings.ReplaceAll(secretName, "-", "_"))
+}
+
 // mockManager はテスト用のモックSecret Manager
 type mockManager struct {
 	secrets map[string]string
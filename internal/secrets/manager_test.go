@@ -110,3 +110,26 @@ func TestMockManager_MultipleGetSecret(t *testing.T) {
 		}
 	}
 }
+
+func TestEnvManager_GetSecret(t *testing.T) {
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.com/api/webhooks/env")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	manager := NewEnvManager()
+	defer manager.Close()
+
+	value, err := manager.GetSecret(context.Background(), "discord-webhook-url")
+	if err != nil {
+		t.Fatalf("GetSecret() エラー = %v", err)
+	}
+	if value != "https://discord.com/api/webhooks/env" {
+		t.Errorf("GetSecret() = %v, 期待 %v", value, "https://discord.com/api/webhooks/env")
+	}
+
+	if _, err := manager.GetSecret(context.Background(), "gemini-api-key"); err == nil {
+		t.Error("環境変数が未設定のシークレットでエラーが返されませんでした")
+	}
+	if got := EnvName("gemini-api-key"); got != "GEMINI_API_KEY" {
+		t.Errorf("EnvName() = %v, 期待 GEMINI_API_KEY", got)
+	}
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// フィードのリンクがリダイレクタ（feedproxy・hnrssなど）や短縮URLの場合、記事はリダイレクト後の最終URLで記録し、
+// フィードに記載された元のURLは別名（エイリアス）として同じ内容で記録します。
+// 次回以降の重複チェックは元のURLで行うため、記事を取得し直さずに除外できます。
+
+// IsArticleProcessed は記事が保持期間内に通知済みまたは却下済みかどうかを返します
+// 確認のみで記事を再評価に回すわけではないため、再評価の予算は消費しません
+func IsArticleProcessed(ctx context.Context, s Store, articleURL string) (bool, error) {
+	notified, err := s.IsArticleNotified(ctx, articleURL)
+	if err != nil || notified {
+		return notified, err
+	}
+	return s.IsArticleRejected(ctx, articleURL, false)
+}
+
+// RunArticles は今回の実行で評価する記事のURLと、同じ記事を指すフィードのリンク（別名）を管理します
+// 別名は、記事が通知済みまたは却下済みとして保存される際に記事と同じ内容で記録します
+// 評価中の記事に重複の却下を記録すると、記事が通知されなかった場合に次回以降も評価されなくなるためです
+type RunArticles struct {
+	store   Store
+	aliases map[string][]string
+}
+
+// NewRunArticles は今回の実行で評価する記事を管理するRunArticlesを作成します
+func NewRunArticles(s Store) *RunArticles {
+	return &RunArticles{store: s, aliases: make(map[string][]string)}
+}
+
+// Add は取得した記事（feedURLのリンクから取得した、最終URLがarticleURLの記事）を今回の実行で評価する記事として登録します
+// 同じ記事を今回すでに登録している場合や、リダイレクト後の最終URLが保持期間内に処理済みの場合は重複としてfalseを返します
+// 重複の場合、feedURLが記事のURLと異なれば（リダイレクタ・短縮URL）別名として記録します
+// 記事が処理済み（今回の実行ですでに保存済みを含む）であればduplicateの理由で却下済みとして保存し、評価中であれば記事の保存時に記録します
+// 処理済みかの確認に失敗した場合は、評価の漏れを避けるため記事を登録してエラーとともにtrueを返します
+func (r *RunArticles) Add(ctx context.Context, articleURL, feedURL string) (bool, error) {
+	_, inRun := r.aliases[articleURL]
+	if !inRun && feedURL == articleURL {
+		// リダイレクトのないフィードのリンクは、取得前に処理済みかを確認している
+		r.aliases[articleURL] = nil
+		return true, nil
+	}
+
+	processed, err := IsArticleProcessed(ctx, r.store, articleURL)
+	if err != nil && !inRun {
+		r.aliases[articleURL] = nil
+		return true, err
+	}
+	switch {
+	case processed:
+		if feedURL != articleURL {
+			if saveErr := r.store.SaveRejectedArticle(ctx, feedURL, config.ReasonDuplicate, nil); saveErr != nil {
+				return false, saveErr
+			}
+		}
+	case inRun:
+		r.addAlias(articleURL, feedURL)
+	default:
+		r.aliases[articleURL] = nil
+		r.addAlias(articleURL, feedURL)
+		return true, nil
+	}
+	return false, err
+}
+
+// addAlias は記事のURLと異なるフィードのリンクを記事の別名として記録します
+func (r *RunArticles) addAlias(articleURL, feedURL string) {
+	if feedURL == articleURL || slices.Contains(r.aliases[articleURL], feedURL) {
+		return
+	}
+	r.aliases[articleURL] = append(r.aliases[articleURL], feedURL)
+}
+
+// Aliases は記事の別名（記事のURLと異なるフィードのリンク）を返します
+func (r *RunArticles) Aliases(articleURL string) []string {
+	return r.aliases[articleURL]
+}
+
+// SaveNotifiedArticleWithAliases は通知済み記事を記事のURLと別名のURLで保存します
+// いずれかの保存に失敗しても残りの保存は継続し、失敗をまとめて返します
+func SaveNotifiedArticleWithAliases(ctx context.Context, s Store, articleURL string, aliases []string, discordMessageID, articleTitle string, relevanceScore int) error {
+	var errs []error
+	for _, u := range withAliases(articleURL, aliases) {
+		if err := s.SaveNotifiedArticle(ctx, u, discordMessageID, articleTitle, relevanceScore); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SaveRejectedArticleWithAliases は却下された記事を記事のURLと別名のURLで保存します
+// いずれかの保存に失敗しても残りの保存は継続し、失敗をまとめて返します
+func SaveRejectedArticleWithAliases(ctx context.Context, s Store, articleURL string, aliases []string, reason string, relevanceScore *int) error {
+	var errs []error
+	for _, u := range withAliases(articleURL, aliases) {
+		if err := s.SaveRejectedArticle(ctx, u, reason, relevanceScore); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withAliases は記事のURLと、空でなく記事のURLと重複しない別名のURLを返します
+func withAliases(articleURL string, aliases []string) []string {
+	urls := []string{articleURL}
+	seen := map[string]bool{articleURL: true}
+	for _, alias := range aliases {
+		if alias != "" && !seen[alias] {
+			seen[alias] = true
+			urls = append(urls, alias)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// BoltStore はbbolt（組み込みキーバリューストア）の単一ファイルを使用するStoreの実装
+// Firestoreを用意せずにローカル環境やセルフホスト環境で永続化する場合に使用します
+// バケット名はFirestoreのコレクション名と同じものを使用します
+type BoltStore struct {
+	db        *bolt.DB
+	mu        sync.RWMutex
+	retention config.RetentionSettings
+	recheck   recheckPolicy
+}
+
+// NewBoltStore は指定されたパスのbboltファイルを開き、新しいストアを作成します
+// ファイルが存在しない場合は作成されます
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("bolt database open failed: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{NotifiedArticlesCollection, RejectedArticlesCollection, EvaluationsCollection, EvaluationCacheCollection, LLMUsageCollection, RunHistoryCollection} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("bolt bucket creation failed: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// SetRetentionSettings は記事の保持期間設定を適用します
+func (b *BoltStore) SetRetentionSettings(settings config.RetentionSettings) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retention = settings
+}
+
+// SetEvaluationContext は現在の評価条件を設定し、再評価数をリセットします
+func (b *BoltStore) SetEvaluationContext(ctx EvaluationContext) {
+	b.recheck.set(ctx)
+}
+
+// RechecksUsed は評価条件の変更により再評価を許可した却下済み記事の数を返します
+func (b *BoltStore) RechecksUsed() int {
+	return b.recheck.rechecksUsed()
+}
+
+// getRetention は現在の保持期間設定を返します
+func (b *BoltStore) getRetention() config.RetentionSettings {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.retention
+}
+
+// SaveNotifiedArticle は通知済み記事を保存します
+func (b *BoltStore) SaveNotifiedArticle(ctx context.Context, articleURL, discordMessageID, articleTitle string, relevanceScore int) error {
+	retention := b.getRetention()
+	now := timeNow()
+	article := config.NotifiedArticle{
+		NotifiedAt:       now,
+		DiscordMessageID: discordMessageID,
+		ArticleTitle:     articleTitle,
+		RelevanceScore:   relevanceScore,
+		ExpireAt:         now.Add(retention.NotifiedRetention()),
+	}
+
+	if err := b.put(NotifiedArticlesCollection, urlToDocID(articleURL), article); err != nil {
+		return fmt.Errorf("failed to save notified article: %w", err)
+	}
+	return nil
+}
+
+// IsArticleNotified は記事が既に通知済みかどうかをチェックします
+func (b *BoltStore) IsArticleNotified(ctx context.Context, articleURL string) (bool, error) {
+	var article config.NotifiedArticle
+	found, err := b.get(NotifiedArticlesCollection, urlToDocID(articleURL), &article)
+	if err != nil {
+		return false, fmt.Errorf("failed to check notified article: %w", err)
+	}
+	if !found {
+		return false, nil
+	}
+	return !notifiedExpired(b.getRetention(), &article), nil
+}
+
+// SaveRejectedArticle は却下された記事を保存します
+func (b *BoltStore) SaveRejectedArticle(ctx context.Context, articleURL, reason string, relevanceScore *int) error {
+	retention := b.getRetention()
+	now := timeNow()
+	article := config.RejectedArticle{
+		EvaluatedAt:    now,
+		Reason:         reason,
+		RelevanceScore: relevanceScore,
+		ExpireAt:       now.Add(retention.RejectedRetention(reason)),
+	}
+	b.recheck.stamp(&article)
+
+	if err := b.put(RejectedArticlesCollection, urlToDocID(articleURL), article); err != nil {
+		return fmt.Errorf("failed to save rejected article: %w", err)
+	}
+	return nil
+}
+
+// IsArticleRejected は記事が既に却下済みかどうかをチェックします
+func (b *BoltStore) IsArticleRejected(ctx context.Context, articleURL string, releaseForRecheck bool) (bool, error) {
+	var article config.RejectedArticle
+	found, err := b.get(RejectedArticlesCollection, urlToDocID(articleURL), &article)
+	if err != nil {
+		return false, fmt.Errorf("failed to check rejected article: %w", err)
+	}
+	if !found {
+		return false, nil
+	}
+	if rejectedExpired(b.getRetention(), &article) {
+		return false, nil
+	}
+	return b.recheck.stillRejected(&article, releaseForRecheck), nil
+}
+
+// SaveEvaluation は評価記録を保存します
+// キーはバケットのシーケンス番号（ビッグエンディアン）で自動採番されます
+func (b *BoltStore) SaveEvaluation(ctx context.Context, record *config.EvaluationRecord) error {
+	prepared := prepareEvaluationRecord(record, b.getRetention(), &b.recheck)
+	if err := b.append(EvaluationsCollection, prepared); err != nil {
+		return fmt.Errorf("failed to save evaluation: %w", err)
+	}
+	return nil
+}
+
+// ListEvaluations は検索条件に一致する評価記録を評価日時の新しい順に返します
+func (b *BoltStore) ListEvaluations(ctx context.Context, query EvaluationQuery) ([]config.EvaluationRecord, error) {
+	var records []config.EvaluationRecord
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(EvaluationsCollection)).ForEach(func(k, v []byte) error {
+			var record config.EvaluationRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evaluations: %w", err)
+	}
+	return filterEvaluations(records, query), nil
+}
+
+// GetCachedEvaluation はキーに対応する有効期間内の評価キャッシュを返します（存在しない場合はnil）
+func (b *BoltStore) GetCachedEvaluation(ctx context.Context, key string) (*config.CachedEvaluation, error) {
+	var entry config.CachedEvaluation
+	found, err := b.get(EvaluationCacheCollection, key, &entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached evaluation: %w", err)
+	}
+	if !found || evaluationCacheExpired(b.getRetention(), &entry) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// SaveCachedEvaluation は評価キャッシュを保存します
+func (b *BoltStore) SaveCachedEvaluation(ctx context.Context, key string, entry *config.CachedEvaluation) error {
+	prepared := prepareCachedEvaluation(entry, b.getRetention())
+	if err := b.put(EvaluationCacheCollection, key, prepared); err != nil {
+		return fmt.Errorf("failed to save cached evaluation: %w", err)
+	}
+	return nil
+}
+
+// GetDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量を返します（記録がない場合は使用量0）
+func (b *BoltStore) GetDailyUsage(ctx context.Context, date string) (*config.DailyUsage, error) {
+	daily := config.DailyUsage{Date: date}
+	if _, err := b.get(LLMUsageCollection, date, &daily); err != nil {
+		return nil, fmt.Errorf("failed to get daily usage: %w", err)
+	}
+	return &daily, nil
+}
+
+// AddDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量にトークン使用量と呼び出し回数を加算します
+// 読み取りと書き込みを1つのトランザクションで行います
+func (b *BoltStore) AddDailyUsage(ctx context.Context, date string, usage config.TokenUsage, requests int) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(LLMUsageCollection))
+		daily := config.DailyUsage{Date: date}
+		if v := bkt.Get([]byte(date)); v != nil {
+			if err := json.Unmarshal(v, &daily); err != nil {
+				return err
+			}
+		}
+		addDailyUsage(&daily, usage, requests)
+
+		data, err := json.Marshal(daily)
+		if err != nil {
+			return err
+		}
+		return bkt.Put([]byte(date), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add daily usage: %w", err)
+	}
+	return nil
+}
+
+// SaveRunHistory は実行履歴を保存します
+// キーはバケットのシーケンス番号（ビッグエンディアン）で自動採番されます
+func (b *BoltStore) SaveRunHistory(ctx context.Context, run *config.RunHistory) error {
+	if err := b.append(RunHistoryCollection, run); err != nil {
+		return fmt.Errorf("failed to save run history: %w", err)
+	}
+	return nil
+}
+
+// ListRunHistory は実行履歴を開始日時の新しい順に最大limit件返します（0以下の場合は全件）
+func (b *BoltStore) ListRunHistory(ctx context.Context, limit int) ([]config.RunHistory, error) {
+	var runs []config.RunHistory
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(RunHistoryCollection)).ForEach(func(k, v []byte) error {
+			var run config.RunHistory
+			if err := json.Unmarshal(v, &run); err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run history: %w", err)
+	}
+	return sortRunHistory(runs, limit), nil
+}
+
+// PurgeExpired は保持期間を過ぎた記事と評価記録を削除します
+// bboltは1トランザクションで一括削除できるため、BatchSizeは使用しません
+func (b *BoltStore) PurgeExpired(ctx context.Context, opts PurgeOptions) (*PurgeResult, error) {
+	retention := b.getRetention()
+	result := &PurgeResult{}
+
+	update := b.db.Update
+	if opts.DryRun {
+		update = b.db.View
+	}
+
+	err := update(func(tx *bolt.Tx) error {
+		notified, err := purgeBucket(tx.Bucket([]byte(NotifiedArticlesCollection)), opts.DryRun, func(v []byte) (bool, error) {
+			var article config.NotifiedArticle
+			if err := json.Unmarshal(v, &article); err != nil {
+				return false, err
+			}
+			return notifiedExpired(retention, &article), nil
+		})
+		result.NotifiedDeleted = notified
+		if err != nil {
+			return err
+		}
+
+		rejected, err := purgeBucket(tx.Bucket([]byte(RejectedArticlesCollection)), opts.DryRun, func(v []byte) (bool, error) {
+			var article config.RejectedArticle
+			if err := json.Unmarshal(v, &article); err != nil {
+				return false, err
+			}
+			return rejectedExpired(retention, &article), nil
+		})
+		result.RejectedDeleted = rejected
+		if err != nil {
+			return err
+		}
+
+		cached, err := purgeBucket(tx.Bucket([]byte(EvaluationCacheCollection)), opts.DryRun, func(v []byte) (bool, error) {
+			var entry config.CachedEvaluation
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return false, err
+			}
+			return evaluationCacheExpired(retention, &entry), nil
+		})
+		result.CacheDeleted = cached
+		return err
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to purge expired articles: %w", err)
+	}
+	return result, nil
+}
+
+// Close はbboltファイルを閉じます
+func (b *BoltStore) Close() error {
+	if b.db == nil {
+		return nil
+	}
+	return b.db.Close()
+}
+
+// put は値をJSONにエンコードしてバケットに保存します
+func (b *BoltStore) put(bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).Put([]byte(key), data)
+	})
+}
+
+// append は値をJSONにエンコードし、シーケンス番号をキーとしてバケットに追加します
+func (b *BoltStore) append(bucket string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte(bucket))
+		seq, err := bkt.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return bkt.Put(key, data)
+	})
+}
+
+// get はバケットから値を取得してデコードします（存在しない場合はfalseを返す）
+func (b *BoltStore) get(bucket, key string, value any) (bool, error) {
+	var data []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket([]byte(bucket)).Get([]byte(key)); v != nil {
+			// トランザクション外で使用するためコピーする
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// purgeBucket はバケット内の期限切れのキーを削除し、件数を返します
+func purgeBucket(bucket *bolt.Bucket, dryRun bool, expired func(v []byte) (bool, error)) (int, error) {
+	var keys [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		ok, err := expired(v)
+		if err != nil {
+			return err
+		}
+		if ok {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !dryRun {
+		// ForEach中の削除は不可のため、収集後に削除する
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(keys), nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+const (
+	// EvaluationCacheCollection はLLMによる評価結果のキャッシュを保存するコレクション名
+	EvaluationCacheCollection = "evaluation_cache"
+)
+
+// evaluationCacheExpired は評価キャッシュが有効期間を過ぎているかを判定します
+func evaluationCacheExpired(retention config.RetentionSettings, entry *config.CachedEvaluation) bool {
+	return isExpired(entry.ExpireAt, entry.CachedAt, retention.EvaluationCacheRetention())
+}
+
+// prepareCachedEvaluation は保存前の評価キャッシュに作成日時と有効期限を設定したコピーを返します
+func prepareCachedEvaluation(entry *config.CachedEvaluation, retention config.RetentionSettings) config.CachedEvaluation {
+	prepared := *entry
+	prepared.CachedAt = timeNow()
+	prepared.ExpireAt = prepared.CachedAt.Add(retention.EvaluationCacheRetention())
+	return prepared
+}
+
+// GetCachedEvaluation はキーに対応する有効期間内の評価キャッシュを返します（存在しない場合はnil）
+func (c *Client) GetCachedEvaluation(ctx context.Context, key string) (*config.CachedEvaluation, error) {
+	doc, err := c.client.Collection(EvaluationCacheCollection).Doc(key).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cached evaluation: %w", err)
+	}
+
+	var entry config.CachedEvaluation
+	if err := doc.DataTo(&entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cached evaluation: %w", err)
+	}
+	if evaluationCacheExpired(c.retention, &entry) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// SaveCachedEvaluation は評価キャッシュを保存します（同じキーのキャッシュは上書きされます）
+func (c *Client) SaveCachedEvaluation(ctx context.Context, key string, entry *config.CachedEvaluation) error {
+	prepared := prepareCachedEvaluation(entry, c.retention)
+	if _, err := c.client.Collection(EvaluationCacheCollection).Doc(key).Set(ctx, prepared); err != nil {
+		return fmt.Errorf("failed to save cached evaluation: %w", err)
+	}
+	return nil
+}
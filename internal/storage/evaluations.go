@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+const (
+	// EvaluationsCollection はLLMによる評価記録を保存するコレクション名
+	EvaluationsCollection = "evaluations"
+)
+
+// EvaluationQuery は評価記録の検索条件を表します
+// ゼロ値の項目は条件として使用しません
+type EvaluationQuery struct {
+	ArticleURL      string    // 指定した記事の評価のみ
+	Since           time.Time // この日時以降に評価されたもののみ
+	Until           time.Time // この日時より前に評価されたもののみ
+	Topic           string    // matching_topicsにこのトピックを含むもののみ
+	MinScore        int       // 関連性スコアがこの値以上のもののみ
+	MaxScore        *int      // 関連性スコアがこの値以下のもののみ
+	AIGeneratedOnly bool      // AI生成と判定されたもののみ
+	Limit           int       // 最大件数（0以下の場合は全件）
+}
+
+// matches は評価記録が検索条件に一致するかを判定します
+func (q *EvaluationQuery) matches(record *config.EvaluationRecord) bool {
+	if q.ArticleURL != "" && record.ArticleURL != q.ArticleURL {
+		return false
+	}
+	if !q.Since.IsZero() && record.EvaluatedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !record.EvaluatedAt.Before(q.Until) {
+		return false
+	}
+	if record.RelevanceScore < q.MinScore {
+		return false
+	}
+	if q.MaxScore != nil && record.RelevanceScore > *q.MaxScore {
+		return false
+	}
+	if q.AIGeneratedOnly && !record.IsAIGenerated {
+		return false
+	}
+	if q.Topic != "" {
+		for _, topic := range record.MatchingTopics {
+			if topic == q.Topic {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// filterEvaluations は評価記録を検索条件で絞り込み、評価日時の新しい順に並べて最大Limit件返します
+func filterEvaluations(records []config.EvaluationRecord, query EvaluationQuery) []config.EvaluationRecord {
+	matched := make([]config.EvaluationRecord, 0, len(records))
+	for i := range records {
+		if query.matches(&records[i]) {
+			matched = append(matched, records[i])
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].EvaluatedAt.After(matched[j].EvaluatedAt)
+	})
+	if query.Limit > 0 && len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched
+}
+
+// evaluationExpired は評価記録が保持期間を過ぎているかを判定します
+func evaluationExpired(retention config.RetentionSettings, record *config.EvaluationRecord) bool {
+	return isExpired(record.ExpireAt, record.EvaluatedAt, retention.EvaluationRetention())
+}
+
+// prepareEvaluationRecord は保存前の評価記録に保持期限と評価条件を設定したコピーを返します
+func prepareEvaluationRecord(record *config.EvaluationRecord, retention config.RetentionSettings, recheck *recheckPolicy) config.EvaluationRecord {
+	prepared := *record
+	if prepared.EvaluatedAt.IsZero() {
+		prepared.EvaluatedAt = timeNow()
+	}
+	prepared.ExpireAt = timeNow().Add(retention.EvaluationRetention())
+	if prepared.InterestsHash == "" {
+		prepared.InterestsHash = recheck.current().InterestsHash
+	}
+	return prepared
+}
+
+// SaveEvaluation は評価記録をFirestoreに保存します
+// 同じ記事が再評価される場合に備え、ドキュメントIDは自動採番されます
+func (c *Client) SaveEvaluation(ctx context.Context, record *config.EvaluationRecord) error {
+	prepared := prepareEvaluationRecord(record, c.retention, &c.recheck)
+	if _, _, err := c.client.Collection(EvaluationsCollection).Add(ctx, prepared); err != nil {
+		return fmt.Errorf("failed to save evaluation: %w", err)
+	}
+	return nil
+}
+
+// ListEvaluations は検索条件に一致する評価記録を評価日時の新しい順に返します
+// 複合インデックスを不要にするため、Firestoreへのクエリは記事URLまたは評価日時の範囲のみで行い、
+// その他の条件はアプリケーション側で絞り込みます
+func (c *Client) ListEvaluations(ctx context.Context, query EvaluationQuery) ([]config.EvaluationRecord, error) {
+	col := c.client.Collection(EvaluationsCollection)
+
+	var q firestore.Query
+	if query.ArticleURL != "" {
+		q = col.Where("article_url", "==", query.ArticleURL)
+	} else {
+		q = col.OrderBy("evaluated_at", firestore.Desc)
+		if !query.Since.IsZero() {
+			q = q.Where("evaluated_at", ">=", query.Since)
+		}
+		if !query.Until.IsZero() {
+			q = q.Where("evaluated_at", "<", query.Until)
+		}
+	}
+
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	var records []config.EvaluationRecord
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list evaluations: %w", err)
+		}
+
+		var record config.EvaluationRecord
+		if err := doc.DataTo(&record); err != nil {
+			return nil, fmt.Errorf("failed to parse evaluation: %w", err)
+		}
+		if !query.matches(&record) {
+			continue
+		}
+		records = append(records, record)
+
+		// 評価日時の降順で取得している場合は上限に達した時点で打ち切る
+		if query.ArticleURL == "" && query.Limit > 0 && len(records) >= query.Limit {
+			break
+		}
+	}
+
+	return filterEvaluations(records, query), nil
+}
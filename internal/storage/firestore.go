@@ -8,11 +8,18 @@ import (
 	"fmt"
 
 	"cloud.google.com/go/firestore"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
 )
 
+// ExpireAtField はFirestoreネイティブTTLポリシーの対象フィールド名
+const ExpireAtField = "expire_at"
+
 // Client はFirestoreクライアントをラップします
 type Client struct {
-	client *firestore.Client
+	client    *firestore.Client
+	retention config.RetentionSettings
+	recheck   recheckPolicy
 }
 
 // NewClient は新しいFirestoreクライアントを作成します
@@ -35,6 +42,22 @@ func (c *Client) Close() error {
 	return c.client.Close()
 }
 
+// SetRetentionSettings は記事の保持期間設定を適用します
+// 未設定の場合はconfig.DefaultRetentionDaysが使用されます
+func (c *Client) SetRetentionSettings(settings config.RetentionSettings) {
+	c.retention = settings
+}
+
+// SetEvaluationContext は現在の評価条件を設定し、再評価数をリセットします
+func (c *Client) SetEvaluationContext(ctx EvaluationContext) {
+	c.recheck.set(ctx)
+}
+
+// RechecksUsed は評価条件の変更により再評価を許可した却下済み記事の数を返します
+func (c *Client) RechecksUsed() int {
+	return c.recheck.rechecksUsed()
+}
+
 // GetClient は内部のFirestoreクライアントを返します（テスト用）
 func (c *Client) GetClient() *firestore.Client {
 	return c.client
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+const (
+	// RunHistoryCollection は実行履歴を保存するコレクション名
+	RunHistoryCollection = "run_history"
+)
+
+// SaveRunHistory は実行履歴をFirestoreに保存します
+// ドキュメントIDは自動採番されます
+func (c *Client) SaveRunHistory(ctx context.Context, run *config.RunHistory) error {
+	_, _, err := c.client.Collection(RunHistoryCollection).Add(ctx, run)
+	if err != nil {
+		return fmt.Errorf("failed to save run history: %w", err)
+	}
+	return nil
+}
+
+// ListRunHistory は実行履歴を開始日時の新しい順に最大limit件返します（0以下の場合は全件）
+func (c *Client) ListRunHistory(ctx context.Context, limit int) ([]config.RunHistory, error) {
+	query := c.client.Collection(RunHistoryCollection).OrderBy("started_at", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run history: %w", err)
+	}
+
+	runs := make([]config.RunHistory, 0, len(docs))
+	for _, doc := range docs {
+		var run config.RunHistory
+		if err := doc.DataTo(&run); err != nil {
+			return nil, fmt.Errorf("failed to parse run history: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// MemoryStore はプロセス内メモリを使用するStoreの実装
+// 永続化されないため、主にテストや一時的な実行で使用します
+type MemoryStore struct {
+	mu        sync.RWMutex
+	retention config.RetentionSettings
+	notified  map[string]config.NotifiedArticle
+	rejected  map[string]config.RejectedArticle
+	evals     []config.EvaluationRecord
+	cache     map[string]config.CachedEvaluation
+	usage     map[string]config.DailyUsage
+	runs      []config.RunHistory
+	recheck   recheckPolicy
+}
+
+// NewMemoryStore は新しいインメモリストアを作成します
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		notified: make(map[string]config.NotifiedArticle),
+		rejected: make(map[string]config.RejectedArticle),
+		cache:    make(map[string]config.CachedEvaluation),
+		usage:    make(map[string]config.DailyUsage),
+	}
+}
+
+// SetRetentionSettings は記事の保持期間設定を適用します
+func (m *MemoryStore) SetRetentionSettings(settings config.RetentionSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = settings
+}
+
+// SetEvaluationContext は現在の評価条件を設定し、再評価数をリセットします
+func (m *MemoryStore) SetEvaluationContext(ctx EvaluationContext) {
+	m.recheck.set(ctx)
+}
+
+// RechecksUsed は評価条件の変更により再評価を許可した却下済み記事の数を返します
+func (m *MemoryStore) RechecksUsed() int {
+	return m.recheck.rechecksUsed()
+}
+
+// SaveNotifiedArticle は通知済み記事を保存します
+func (m *MemoryStore) SaveNotifiedArticle(ctx context.Context, articleURL, discordMessageID, articleTitle string, relevanceScore int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := timeNow()
+	m.notified[urlToDocID(articleURL)] = config.NotifiedArticle{
+		NotifiedAt:       now,
+		DiscordMessageID: discordMessageID,
+		ArticleTitle:     articleTitle,
+		RelevanceScore:   relevanceScore,
+		ExpireAt:         now.Add(m.retention.NotifiedRetention()),
+	}
+	return nil
+}
+
+// IsArticleNotified は記事が既に通知済みかどうかをチェックします
+func (m *MemoryStore) IsArticleNotified(ctx context.Context, articleURL string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	article, ok := m.notified[urlToDocID(articleURL)]
+	if !ok {
+		return false, nil
+	}
+	return !notifiedExpired(m.retention, &article), nil
+}
+
+// SaveRejectedArticle は却下された記事を保存します
+func (m *MemoryStore) SaveRejectedArticle(ctx context.Context, articleURL, reason string, relevanceScore *int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := timeNow()
+	article := config.RejectedArticle{
+		EvaluatedAt: now,
+		Reason:      reason,
+		ExpireAt:    now.Add(m.retention.RejectedRetention(reason)),
+	}
+	if relevanceScore != nil {
+		score := *relevanceScore
+		article.RelevanceScore = &score
+	}
+	m.recheck.stamp(&article)
+	m.rejected[urlToDocID(articleURL)] = article
+	return nil
+}
+
+// IsArticleRejected は記事が既に却下済みかどうかをチェックします
+func (m *MemoryStore) IsArticleRejected(ctx context.Context, articleURL string, releaseForRecheck bool) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	article, ok := m.rejected[urlToDocID(articleURL)]
+	if !ok {
+		return false, nil
+	}
+	if rejectedExpired(m.retention, &article) {
+		return false, nil
+	}
+	return m.recheck.stillRejected(&article, releaseForRecheck), nil
+}
+
+// SaveEvaluation は評価記録を保存します
+func (m *MemoryStore) SaveEvaluation(ctx context.Context, record *config.EvaluationRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evals = append(m.evals, prepareEvaluationRecord(record, m.retention, &m.recheck))
+	return nil
+}
+
+// ListEvaluations は検索条件に一致する評価記録を評価日時の新しい順に返します
+func (m *MemoryStore) ListEvaluations(ctx context.Context, query EvaluationQuery) ([]config.EvaluationRecord, error) {
+	m.mu.RLock()
+	evals := make([]config.EvaluationRecord, len(m.evals))
+	copy(evals, m.evals)
+	m.mu.RUnlock()
+
+	return filterEvaluations(evals, query), nil
+}
+
+// GetCachedEvaluation はキーに対応する有効期間内の評価キャッシュを返します（存在しない場合はnil）
+func (m *MemoryStore) GetCachedEvaluation(ctx context.Context, key string) (*config.CachedEvaluation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.cache[key]
+	if !ok || evaluationCacheExpired(m.retention, &entry) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// SaveCachedEvaluation は評価キャッシュを保存します
+func (m *MemoryStore) SaveCachedEvaluation(ctx context.Context, key string, entry *config.CachedEvaluation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache[key] = prepareCachedEvaluation(entry, m.retention)
+	return nil
+}
+
+// GetDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量を返します（記録がない場合は使用量0）
+func (m *MemoryStore) GetDailyUsage(ctx context.Context, date string) (*config.DailyUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	daily, ok := m.usage[date]
+	if !ok {
+		return &config.DailyUsage{Date: date}, nil
+	}
+	return &daily, nil
+}
+
+// AddDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量にトークン使用量と呼び出し回数を加算します
+func (m *MemoryStore) AddDailyUsage(ctx context.Context, date string, usage config.TokenUsage, requests int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	daily := m.usage[date]
+	daily.Date = date
+	addDailyUsage(&daily, usage, requests)
+	m.usage[date] = daily
+	return nil
+}
+
+// SaveRunHistory は実行履歴を保存します
+func (m *MemoryStore) SaveRunHistory(ctx context.Context, run *config.RunHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = append(m.runs, *run)
+	return nil
+}
+
+// ListRunHistory は実行履歴を開始日時の新しい順に最大limit件返します（0以下の場合は全件）
+func (m *MemoryStore) ListRunHistory(ctx context.Context, limit int) ([]config.RunHistory, error) {
+	m.mu.RLock()
+	runs := make([]config.RunHistory, len(m.runs))
+	copy(runs, m.runs)
+	m.mu.RUnlock()
+
+	return sortRunHistory(runs, limit), nil
+}
+
+// PurgeExpired は保持期間を過ぎた記事と評価記録を削除します
+func (m *MemoryStore) PurgeExpired(ctx context.Context, opts PurgeOptions) (*PurgeResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &PurgeResult{}
+	for id, article := range m.notified {
+		if notifiedExpired(m.retention, &article) {
+			result.NotifiedDeleted++
+			if !opts.DryRun {
+				delete(m.notified, id)
+			}
+		}
+	}
+	for id, article := range m.rejected {
+		if rejectedExpired(m.retention, &article) {
+			result.RejectedDeleted++
+			if !opts.DryRun {
+				delete(m.rejected, id)
+			}
+		}
+	}
+	for key, entry := range m.cache {
+		if evaluationCacheExpired(m.retention, &entry) {
+			result.CacheDeleted++
+			if !opts.DryRun {
+				delete(m.cache, key)
+			}
+		}
+	}
+	kept := m.evals[:0]
+	for _, record := range m.evals {
+		if evaluationExpired(m.retention, &record) {
+			result.EvaluationsDeleted++
+			if !opts.DryRun {
+				continue
+			}
+		}
+		kept = append(kept, record)
+	}
+	m.evals = kept
+	return result, nil
+}
+
+// Close は何もしません（インメモリ用）
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// sortRunHistory は実行履歴を開始日時の新しい順に並べ、最大limit件に切り詰めます
+func sortRunHistory(runs []config.RunHistory, limit int) []config.RunHistory {
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs
+}
@@ -3,7 +3,6 @@ package storage
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/grpc/codes"
@@ -16,9 +15,6 @@ import (
 const (
 	// NotifiedArticlesCollection は通知済み記事を保存するコレクション名
 	NotifiedArticlesCollection = "notified_articles"
-
-	// NotifiedArticleTTLDays は通知済み記事のTTL（日数）
-	NotifiedArticleTTLDays = 30
 )
 
 // SaveNotifiedArticle は通知済み記事をFirestoreに保存します
@@ -33,6 +29,7 @@ func (c *Client) SaveNotifiedArticle(ctx context.Context, articleURL, discordMes
 		"discord_message_id": discordMessageID,
 		"article_title":      articleTitle,
 		"relevance_score":    relevanceScore,
+		ExpireAtField:        timeNow().Add(c.retention.NotifiedRetention()),
 	}
 
 	_, err := docRef.Set(ctx, notifiedArticle)
@@ -69,8 +66,9 @@ func (c *Client) IsArticleNotified(ctx context.Context, articleURL string) (bool
 			return false, fmt.Errorf("failed to parse notified article: %w", err)
 		}
 
-		// TTLチェック: 30日以上経過している場合は古いデータとして扱う
-		if time.Since(notifiedArticle.NotifiedAt) > NotifiedArticleTTLDays*24*time.Hour {
+		// TTLチェック: 保持期間を過ぎている場合は古いデータとして扱う
+		// （ネイティブTTLによる削除は即時ではないため読み取り時にも判定する）
+		if notifiedExpired(c.retention, &notifiedArticle) {
 			// TTL期限切れの場合はfalseを返す（新しい記事として扱う）
 			return false, nil
 		}
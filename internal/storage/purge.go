@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/logging"
+)
+
+const (
+	// DefaultPurgeBatchSize はパージ時に1回のクエリで取得・削除するドキュメント数のデフォルト値
+	DefaultPurgeBatchSize = 200
+)
+
+// PurgeOptions はパージ処理のオプションを表します
+type PurgeOptions struct {
+	BatchSize int  // 1バッチあたりのドキュメント数（0以下の場合はDefaultPurgeBatchSize）
+	DryRun    bool // trueの場合は削除せず件数のみ数える
+}
+
+// PurgeResult はパージ処理の結果を表します
+type PurgeResult struct {
+	NotifiedDeleted    int
+	RejectedDeleted    int
+	EvaluationsDeleted int
+	CacheDeleted       int
+}
+
+// Total は削除（DryRunの場合は削除対象）となったドキュメントの合計数を返します
+func (r *PurgeResult) Total() int {
+	return r.NotifiedDeleted + r.RejectedDeleted + r.EvaluationsDeleted + r.CacheDeleted
+}
+
+// isExpired は記録日時と保持期間、expire_atから期限切れかどうかを判定します
+// expire_atを過ぎている場合に加え、現在の保持期間設定を過ぎている場合も期限切れとします
+// （expire_atを持たない旧データや、保持期間を短縮した場合に対応するため）
+func isExpired(expireAt, recordedAt time.Time, retention time.Duration) bool {
+	now := timeNow()
+	if !expireAt.IsZero() && !now.Before(expireAt) {
+		return true
+	}
+	return now.Sub(recordedAt) > retention
+}
+
+// notifiedExpired は通知済み記事が保持期間を過ぎているかを判定します
+func notifiedExpired(retention config.RetentionSettings, article *config.NotifiedArticle) bool {
+	return isExpired(article.ExpireAt, article.NotifiedAt, retention.NotifiedRetention())
+}
+
+// rejectedExpired は却下済み記事が却下理由ごとの保持期間を過ぎているかを判定します
+func rejectedExpired(retention config.RetentionSettings, article *config.RejectedArticle) bool {
+	return isExpired(article.ExpireAt, article.EvaluatedAt, retention.RejectedRetention(article.Reason))
+}
+
+// minRejectedRetention は却下理由ごとの保持期間のうち最短のものを返します
+func minRejectedRetention(retention config.RetentionSettings) time.Duration {
+	min := retention.RejectedRetention("")
+	for _, reason := range config.RejectionReasons {
+		if r := retention.RejectedRetention(reason); r < min {
+			min = r
+		}
+	}
+	return min
+}
+
+// PurgeExpired は保持期間を過ぎた通知済み・却下済み記事と評価記録・評価キャッシュをバッチで削除します
+// FirestoreのネイティブTTLは削除まで最大24時間程度の遅延があり、
+// またexpire_atを持たない旧データは対象外となるため、その補完として使用します
+func (c *Client) PurgeExpired(ctx context.Context, opts PurgeOptions) (*PurgeResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultPurgeBatchSize
+	}
+
+	result := &PurgeResult{}
+
+	notifiedRetention := c.retention.NotifiedRetention()
+	notified, err := c.purgeCollection(ctx, NotifiedArticlesCollection, "notified_at", notifiedRetention, opts,
+		func(doc *firestore.DocumentSnapshot) (bool, error) {
+			var article config.NotifiedArticle
+			if err := doc.DataTo(&article); err != nil {
+				return false, err
+			}
+			return notifiedExpired(c.retention, &article), nil
+		})
+	result.NotifiedDeleted = notified
+	if err != nil {
+		return result, fmt.Errorf("failed to purge notified articles: %w", err)
+	}
+
+	// 却下理由ごとに保持期間が異なるため、最短の保持期間で候補を絞り込み個別に判定する
+	rejected, err := c.purgeCollection(ctx, RejectedArticlesCollection, "evaluated_at", minRejectedRetention(c.retention), opts,
+		func(doc *firestore.DocumentSnapshot) (bool, error) {
+			var article config.RejectedArticle
+			if err := doc.DataTo(&article); err != nil {
+				return false, err
+			}
+			return rejectedExpired(c.retention, &article), nil
+		})
+	result.RejectedDeleted = rejected
+	if err != nil {
+		return result, fmt.Errorf("failed to purge rejected articles: %w", err)
+	}
+
+	evaluations, err := c.purgeCollection(ctx, EvaluationsCollection, "evaluated_at", c.retention.EvaluationRetention(), opts,
+		func(doc *firestore.DocumentSnapshot) (bool, error) {
+			var record config.EvaluationRecord
+			if err := doc.DataTo(&record); err != nil {
+				return false, err
+			}
+			return evaluationExpired(c.retention, &record), nil
+		})
+	result.EvaluationsDeleted = evaluations
+	if err != nil {
+		return result, fmt.Errorf("failed to purge evaluations: %w", err)
+	}
+
+	cached, err := c.purgeCollection(ctx, EvaluationCacheCollection, "cached_at", c.retention.EvaluationCacheRetention(), opts,
+		func(doc *firestore.DocumentSnapshot) (bool, error) {
+			var entry config.CachedEvaluation
+			if err := doc.DataTo(&entry); err != nil {
+				return false, err
+			}
+			return evaluationCacheExpired(c.retention, &entry), nil
+		})
+	result.CacheDeleted = cached
+	if err != nil {
+		return result, fmt.Errorf("failed to purge evaluation cache: %w", err)
+	}
+
+	return result, nil
+}
+
+// purgeCollection はコレクション内の期限切れドキュメントを削除し、削除件数を返します
+// expire_atが過ぎたドキュメントと、記録日時が保持期間より古いドキュメントの2つのクエリで候補を取得します
+func (c *Client) purgeCollection(
+	ctx context.Context,
+	collection string,
+	recordedAtField string,
+	retention time.Duration,
+	opts PurgeOptions,
+	expired func(doc *firestore.DocumentSnapshot) (bool, error),
+) (int, error) {
+	logger := logging.FromContext(ctx)
+	now := timeNow()
+	col := c.client.Collection(collection)
+
+	queries := []firestore.Query{
+		col.Where(ExpireAtField, "<=", now).OrderBy(ExpireAtField, firestore.Asc),
+		col.Where(recordedAtField, "<", now.Add(-retention)).OrderBy(recordedAtField, firestore.Asc),
+	}
+
+	// DryRunでは削除しないため、2つのクエリで重複したドキュメントを二重に数えないようにする
+	seen := make(map[string]bool)
+	deleted := 0
+
+	for _, query := range queries {
+		var last *firestore.DocumentSnapshot
+		for {
+			q := query.Limit(opts.BatchSize)
+			if last != nil {
+				q = q.StartAfter(last)
+			}
+
+			docs, err := q.Documents(ctx).GetAll()
+			if err != nil {
+				return deleted, fmt.Errorf("failed to query expired documents: %w", err)
+			}
+			if len(docs) == 0 {
+				break
+			}
+			last = docs[len(docs)-1]
+
+			var refs []*firestore.DocumentRef
+			for _, doc := range docs {
+				if seen[doc.Ref.ID] {
+					continue
+				}
+				ok, err := expired(doc)
+				if err != nil {
+					// 破損データはパージ対象外としてログのみ出力
+					logger.Warn("Failed to parse document during purge", "collection", collection, "docID", doc.Ref.ID, "error", err)
+					continue
+				}
+				if ok {
+					seen[doc.Ref.ID] = true
+					refs = append(refs, doc.Ref)
+				}
+			}
+
+			if !opts.DryRun && len(refs) > 0 {
+				if err := c.deleteDocuments(ctx, refs); err != nil {
+					return deleted, err
+				}
+			}
+			deleted += len(refs)
+
+			logger.Info("Purged expired documents batch",
+				"collection", collection,
+				"batchSize", len(docs),
+				"deleted", len(refs),
+				"dryRun", opts.DryRun,
+			)
+
+			if len(docs) < opts.BatchSize {
+				break
+			}
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteDocuments はBulkWriterでドキュメントを一括削除します
+func (c *Client) deleteDocuments(ctx context.Context, refs []*firestore.DocumentRef) error {
+	bw := c.client.BulkWriter(ctx)
+
+	jobs := make([]*firestore.BulkWriterJob, 0, len(refs))
+	for _, ref := range refs {
+		job, err := bw.Delete(ref)
+		if err != nil {
+			bw.End()
+			return fmt.Errorf("failed to enqueue delete: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return fmt.Errorf("failed to delete document: %w", err)
+		}
+	}
+
+	return nil
+}
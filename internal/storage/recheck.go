@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// EvaluationContext は記事を評価した際の条件（興味トピックとプロンプト）を表します
+// 却下済み記事にはこの値が記録され、条件が変わった却下は古い（stale）ものとして扱われます
+type EvaluationContext struct {
+	InterestsHash     string // config.Config.InterestsHash()の値
+	PromptVersion     string // 評価プロンプトのバージョン
+	MaxRechecksPerRun int    // 1回の実行で再評価を許可するstaleな却下の最大数（0の場合は無制限）
+}
+
+// recheckPolicy はstaleな却下済み記事の再評価可否と、実行ごとの再評価数を管理します
+// Storeの各実装で共有されます
+type recheckPolicy struct {
+	mu      sync.Mutex
+	context EvaluationContext
+	used    int
+}
+
+// set は評価条件を設定し、再評価数をリセットします
+func (p *recheckPolicy) set(ctx EvaluationContext) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.context = ctx
+	p.used = 0
+}
+
+// current は現在の評価条件を返します
+func (p *recheckPolicy) current() EvaluationContext {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.context
+}
+
+// stamp は却下済み記事に現在の評価条件を記録します
+func (p *recheckPolicy) stamp(article *config.RejectedArticle) {
+	ctx := p.current()
+	article.InterestsHash = ctx.InterestsHash
+	article.PromptVersion = ctx.PromptVersion
+}
+
+// stillRejected は却下済み記事を引き続き却下扱いとするかを判定します
+// 評価条件が異なる却下は再評価の対象とし、予算が残っている場合のみfalseを返します
+// 予算を消費するのは、呼び出し元が記事を再評価に回す（releaseがtrueの）場合のみです
+// 評価条件が未設定の場合や、コンテンツ抽出失敗などLLM評価以外の却下は常に却下扱いとします
+func (p *recheckPolicy) stillRejected(article *config.RejectedArticle, release bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.context.InterestsHash == "" || !config.IsEvaluationRejection(article.Reason) {
+		return true
+	}
+	if article.InterestsHash == p.context.InterestsHash && article.PromptVersion == p.context.PromptVersion {
+		return true
+	}
+
+	// staleな却下: 予算の範囲内で再評価を許可する
+	if p.context.MaxRechecksPerRun > 0 && p.used >= p.context.MaxRechecksPerRun {
+		return true
+	}
+	if release {
+		p.used++
+	}
+	return false
+}
+
+// rechecksUsed はこの実行で再評価を許可したstaleな却下の数を返します
+func (p *recheckPolicy) rechecksUsed() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.used
+}
@@ -3,7 +3,6 @@ package storage
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/grpc/codes"
@@ -16,9 +15,6 @@ import (
 const (
 	// RejectedArticlesCollection は却下済み記事を保存するコレクション名
 	RejectedArticlesCollection = "rejected_articles"
-
-	// RejectedArticleTTLDays は却下済み記事のTTL（日数）
-	RejectedArticleTTLDays = 30
 )
 
 // SaveRejectedArticle は却下された記事をFirestoreに保存します
@@ -31,11 +27,16 @@ func (c *Client) SaveRejectedArticle(ctx context.Context, articleURL, reason str
 	rejectedArticle := map[string]interface{}{
 		"evaluated_at": firestore.ServerTimestamp,
 		"reason":       reason,
+		ExpireAtField:  timeNow().Add(c.retention.RejectedRetention(reason)),
 	}
 
 	if relevanceScore != nil {
 		rejectedArticle["relevance_score"] = *relevanceScore
 	}
+	if evalCtx := c.recheck.current(); evalCtx.InterestsHash != "" {
+		rejectedArticle["interests_hash"] = evalCtx.InterestsHash
+		rejectedArticle["prompt_version"] = evalCtx.PromptVersion
+	}
 	_, err := docRef.Set(ctx, rejectedArticle)
 	if err != nil {
 		return fmt.Errorf("failed to save rejected article: %w", err)
@@ -45,7 +46,7 @@ func (c *Client) SaveRejectedArticle(ctx context.Context, articleURL, reason str
 }
 
 // IsArticleRejected は記事が既に却下済みかどうかをチェックします
-func (c *Client) IsArticleRejected(ctx context.Context, articleURL string) (bool, error) {
+func (c *Client) IsArticleRejected(ctx context.Context, articleURL string, releaseForRecheck bool) (bool, error) {
 	// URLをFirestoreドキュメントIDに変換
 	docID := urlToDocID(articleURL)
 
@@ -70,12 +71,21 @@ func (c *Client) IsArticleRejected(ctx context.Context, articleURL string) (bool
 			return false, fmt.Errorf("failed to parse rejected article: %w", err)
 		}
 
-		// TTLチェック: 30日以上経過している場合は古いデータとして扱う
-		if time.Since(rejectedArticle.EvaluatedAt) > RejectedArticleTTLDays*24*time.Hour {
+		// TTLチェック: 却下理由ごとの保持期間を過ぎている場合は古いデータとして扱う
+		if rejectedExpired(c.retention, &rejectedArticle) {
 			// TTL期限切れの場合はfalseを返す（新しい記事として扱う）
 			return false, nil
 		}
 
+		// 評価条件（興味トピック・プロンプト）が変わった却下は予算内で再評価する
+		if !c.recheck.stillRejected(&rejectedArticle, releaseForRecheck) {
+			if releaseForRecheck {
+				logger := logging.FromContext(ctx)
+				logger.Info("評価条件が変更されたため却下済み記事を再評価します", "url", articleURL, "reason", rejectedArticle.Reason)
+			}
+			return false, nil
+		}
+
 		return true, nil
 	}
 
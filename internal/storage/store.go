@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// Backend はストレージバックエンドの種類を表します
+type Backend string
+
+const (
+	// BackendFirestore はFirestoreを使用するバックエンド（本番環境のデフォルト）
+	BackendFirestore Backend = "firestore"
+	// BackendMemory はプロセス内メモリを使用するバックエンド（テスト用、永続化なし）
+	BackendMemory Backend = "memory"
+	// BackendBolt はbbolt単一ファイルを使用するバックエンド（ローカル・セルフホスト用）
+	BackendBolt Backend = "bolt"
+
+	// DefaultBoltPath はbboltバックエンドのデフォルトのファイルパス
+	DefaultBoltPath = "curator.db"
+)
+
+// Store は記事の重複排除追跡と評価記録・実行履歴の保存を行うストレージのインターフェース
+type Store interface {
+	// SaveNotifiedArticle は通知済み記事を保存します
+	SaveNotifiedArticle(ctx context.Context, articleURL, discordMessageID, articleTitle string, relevanceScore int) error
+	// IsArticleNotified は記事が保持期間内に通知済みかどうかを返します
+	IsArticleNotified(ctx context.Context, articleURL string) (bool, error)
+	// SaveRejectedArticle は却下された記事を保存します
+	SaveRejectedArticle(ctx context.Context, articleURL, reason string, relevanceScore *int) error
+	// IsArticleRejected は記事が保持期間内に却下済みかどうかを返します
+	// 現在と異なる評価条件での却下は、再評価の予算内であればfalseを返します
+	// releaseForRecheckがtrueの場合は、記事を再評価に回すものとして再評価の予算を消費します
+	IsArticleRejected(ctx context.Context, articleURL string, releaseForRecheck bool) (bool, error)
+	// SaveEvaluation はLLMによる評価記録を保存します
+	SaveEvaluation(ctx context.Context, record *config.EvaluationRecord) error
+	// ListEvaluations は検索条件に一致する評価記録を評価日時の新しい順に返します
+	ListEvaluations(ctx context.Context, query EvaluationQuery) ([]config.EvaluationRecord, error)
+	// GetCachedEvaluation はキーに対応する有効期間内の評価キャッシュを返します（存在しない場合はnil）
+	GetCachedEvaluation(ctx context.Context, key string) (*config.CachedEvaluation, error)
+	// SaveCachedEvaluation は評価キャッシュを保存します（同じキーのキャッシュは上書きされます）
+	SaveCachedEvaluation(ctx context.Context, key string, entry *config.CachedEvaluation) error
+	// GetDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量を返します（記録がない場合は使用量0）
+	GetDailyUsage(ctx context.Context, date string) (*config.DailyUsage, error)
+	// AddDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量にトークン使用量と呼び出し回数を加算します
+	AddDailyUsage(ctx context.Context, date string, usage config.TokenUsage, requests int) error
+	// SaveRunHistory は実行履歴を保存します
+	SaveRunHistory(ctx context.Context, run *config.RunHistory) error
+	// ListRunHistory は実行履歴を新しい順に最大limit件返します
+	ListRunHistory(ctx context.Context, limit int) ([]config.RunHistory, error)
+	// PurgeExpired は保持期間を過ぎたドキュメントを削除します
+	PurgeExpired(ctx context.Context, opts PurgeOptions) (*PurgeResult, error)
+	// SetRetentionSettings は記事の保持期間設定を適用します
+	SetRetentionSettings(settings config.RetentionSettings)
+	// SetEvaluationContext は現在の評価条件を設定し、再評価数をリセットします
+	SetEvaluationContext(ctx EvaluationContext)
+	// RechecksUsed は評価条件の変更により再評価を許可した却下済み記事の数を返します
+	RechecksUsed() int
+	// Close はストレージを閉じます
+	Close() error
+}
+
+// StoreOptions はストレージの初期化オプションを表します
+type StoreOptions struct {
+	Backend   Backend // 空の場合はBackendFirestore
+	ProjectID string  // Firestoreバックエンドで使用
+	Path      string  // bboltバックエンドで使用（空の場合はDefaultBoltPath）
+}
+
+// UsesFirestore はFirestoreバックエンド（GCP_PROJECT_IDが必要）を使用するかどうかを返します
+func (o StoreOptions) UsesFirestore() bool {
+	return o.Backend == "" || o.Backend == BackendFirestore
+}
+
+// NewStore はオプションで指定されたバックエンドのストレージを作成します
+func NewStore(ctx context.Context, opts StoreOptions) (Store, error) {
+	switch opts.Backend {
+	case "", BackendFirestore:
+		return NewClient(ctx, opts.ProjectID)
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendBolt:
+		path := opts.Path
+		if path == "" {
+			path = DefaultBoltPath
+		}
+		return NewBoltStore(path)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", opts.Backend)
+	}
+}
+
+// StoreOptionsFromEnv は環境変数STORAGE_BACKENDとSTORAGE_PATHからストレージオプションを作成します
+func StoreOptionsFromEnv(projectID string) StoreOptions {
+	return StoreOptions{
+		Backend:   Backend(os.Getenv("STORAGE_BACKEND")),
+		ProjectID: projectID,
+		Path:      os.Getenv("STORAGE_PATH"),
+	}
+}
+
+// timeNow は現在時刻を返します（テストで時刻を差し替えるための変数）
+var timeNow = time.Now
+
+// コンパイル時にインターフェースの実装を検証
+var (
+	_ Store = (*Client)(nil)
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*BoltStore)(nil)
+)
@@ -0,0 +1,633 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+// storeFactory はテストごとに空のStoreを作成する関数
+type storeFactory func(t *testing.T) Store
+
+// TestStoreConformance はすべてのStore実装に共通の振る舞いを検証します
+// Firestoreバックエンドはエミュレータ（FIRESTORE_EMULATOR_HOST）が起動している場合のみ実行します
+func TestStoreConformance(t *testing.T) {
+	factories := map[string]storeFactory{
+		"memory": func(t *testing.T) Store {
+			return NewMemoryStore()
+		},
+		"bolt": func(t *testing.T) Store {
+			store, err := NewBoltStore(filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatalf("NewBoltStore failed: %v", err)
+			}
+			return store
+		},
+	}
+
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") != "" {
+		factories["firestore"] = func(t *testing.T) Store {
+			client, err := NewClient(context.Background(), "test-project")
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			t.Cleanup(func() {
+				ctx := context.Background()
+				for _, col := range []string{NotifiedArticlesCollection, RejectedArticlesCollection, EvaluationsCollection, EvaluationCacheCollection, RunHistoryCollection} {
+					docs, _ := client.GetClient().Collection(col).Documents(ctx).GetAll()
+					for _, doc := range docs {
+						doc.Ref.Delete(ctx)
+					}
+				}
+			})
+			return client
+		}
+	}
+
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			runStoreConformance(t, factory)
+		})
+	}
+}
+
+// runStoreConformance は共通のテストケースを実行します
+func runStoreConformance(t *testing.T, newStore storeFactory) {
+	t.Run("通知済み記事の保存と確認", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		url := "https://example.com/article?id=123&lang=ja"
+
+		notified, err := store.IsArticleNotified(ctx, url)
+		if err != nil {
+			t.Fatalf("IsArticleNotified failed: %v", err)
+		}
+		if notified {
+			t.Error("保存前に通知済みと判定されました")
+		}
+
+		if err := store.SaveNotifiedArticle(ctx, url, "1234567890123456789", "Test Article", 85); err != nil {
+			t.Fatalf("SaveNotifiedArticle failed: %v", err)
+		}
+
+		notified, err = store.IsArticleNotified(ctx, url)
+		if err != nil {
+			t.Fatalf("IsArticleNotified failed: %v", err)
+		}
+		if !notified {
+			t.Error("保存後に通知済みと判定されませんでした")
+		}
+
+		// 同じ記事の再保存はべき等であること
+		if err := store.SaveNotifiedArticle(ctx, url, "9876543210987654321", "Test Article", 90); err != nil {
+			t.Errorf("再保存に失敗しました: %v", err)
+		}
+	})
+
+	t.Run("別名のURLを含む保存と確認", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		finalURL := "https://example.com/posts/real-article"
+		aliases := []string{"https://feedproxy.example.com/~r/blog/abc", finalURL, ""}
+
+		if err := SaveNotifiedArticleWithAliases(ctx, store, finalURL, aliases, "1234567890123456789", "Test Article", 85); err != nil {
+			t.Fatalf("SaveNotifiedArticleWithAliases failed: %v", err)
+		}
+		for _, url := range []string{finalURL, aliases[0]} {
+			processed, err := IsArticleProcessed(ctx, store, url)
+			if err != nil {
+				t.Fatalf("IsArticleProcessed failed: %v", err)
+			}
+			if !processed {
+				t.Errorf("保存後に処理済みと判定されませんでした: %s", url)
+			}
+		}
+
+		shortURL := "https://t.example/x1"
+		if err := SaveRejectedArticleWithAliases(ctx, store, "https://example.com/posts/other", []string{shortURL}, config.ReasonDuplicate, nil); err != nil {
+			t.Fatalf("SaveRejectedArticleWithAliases failed: %v", err)
+		}
+		rejected, err := store.IsArticleRejected(ctx, shortURL, true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if !rejected {
+			t.Error("別名のURLが却下済みと判定されませんでした")
+		}
+
+		processed, err := IsArticleProcessed(ctx, store, "https://example.com/posts/unknown")
+		if err != nil {
+			t.Fatalf("IsArticleProcessed failed: %v", err)
+		}
+		if processed {
+			t.Error("未保存の記事が処理済みと判定されました")
+		}
+	})
+
+	t.Run("今回の実行で取得した記事の重複と別名", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		finalURL := "https://example.com/posts/real-article"
+		redirectURL := "https://feedproxy.example.com/~r/blog/abc"
+
+		add := func(r *RunArticles, articleURL, feedURL string) bool {
+			t.Helper()
+			added, err := r.Add(ctx, articleURL, feedURL)
+			if err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			return added
+		}
+		processed := func(url string) bool {
+			t.Helper()
+			processed, err := IsArticleProcessed(ctx, store, url)
+			if err != nil {
+				t.Fatalf("IsArticleProcessed failed: %v", err)
+			}
+			return processed
+		}
+
+		// リダイレクタのリンクと直接のリンクが同じ記事を指す場合は、どちらの順序でも2件目を重複とし、
+		// 評価中の記事にはduplicateの却下を記録せず、リダイレクタのリンクを別名とする
+		for _, links := range [][2]string{{redirectURL, finalURL}, {finalURL, redirectURL}} {
+			r := NewRunArticles(store)
+			if !add(r, finalURL, links[0]) {
+				t.Errorf("1件目の記事が重複と判定されました: %s", links[0])
+			}
+			if add(r, finalURL, links[1]) {
+				t.Errorf("2件目の記事が重複と判定されませんでした: %s", links[1])
+			}
+			if aliases := r.Aliases(finalURL); len(aliases) != 1 || aliases[0] != redirectURL {
+				t.Errorf("別名 = %v, 期待 [%s]", aliases, redirectURL)
+			}
+			if processed(finalURL) || processed(redirectURL) {
+				t.Error("評価中の記事の重複が処理済みとして記録されました")
+			}
+		}
+
+		// 記事が保存された後に同じ記事を指すリンクは、duplicateの理由で却下済みとして記録する
+		r := NewRunArticles(store)
+		add(r, finalURL, finalURL)
+		if err := SaveRejectedArticleWithAliases(ctx, store, finalURL, r.Aliases(finalURL), config.ReasonLowRelevance, nil); err != nil {
+			t.Fatalf("SaveRejectedArticleWithAliases failed: %v", err)
+		}
+		if add(r, finalURL, redirectURL) {
+			t.Error("保存済みの記事へのリダイレクトが重複と判定されませんでした")
+		}
+		if !processed(redirectURL) {
+			t.Error("保存済みの記事へのリダイレクタのリンクが記録されませんでした")
+		}
+
+		// 次の実行でも、処理済みの最終URLへのリダイレクトは重複とする
+		shortURL := "https://t.example/x1"
+		if add(NewRunArticles(store), finalURL, shortURL) {
+			t.Error("処理済みの最終URLへのリダイレクトが重複と判定されませんでした")
+		}
+		if !processed(shortURL) {
+			t.Error("処理済みの記事への短縮URLが記録されませんでした")
+		}
+	})
+
+	t.Run("却下済み記事の保存と確認", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		score := 35
+
+		if err := store.SaveRejectedArticle(ctx, "https://example.com/low", config.ReasonLowRelevance, &score); err != nil {
+			t.Fatalf("SaveRejectedArticle failed: %v", err)
+		}
+		if err := store.SaveRejectedArticle(ctx, "https://example.com/failed", config.ReasonContentExtractionFailed, nil); err != nil {
+			t.Fatalf("SaveRejectedArticle failed: %v", err)
+		}
+
+		for _, url := range []string{"https://example.com/low", "https://example.com/failed"} {
+			rejected, err := store.IsArticleRejected(ctx, url, true)
+			if err != nil {
+				t.Fatalf("IsArticleRejected failed: %v", err)
+			}
+			if !rejected {
+				t.Errorf("保存後に却下済みと判定されませんでした: %s", url)
+			}
+		}
+
+		rejected, err := store.IsArticleRejected(ctx, "https://example.com/unknown", true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if rejected {
+			t.Error("未保存の記事が却下済みと判定されました")
+		}
+	})
+
+	t.Run("保持期間とパージ", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		store.SetRetentionSettings(config.RetentionSettings{
+			NotifiedDays: 30,
+			RejectedDays: 30,
+			RejectedReasonDays: map[string]int{
+				config.ReasonContentExtractionFailed: 3,
+			},
+		})
+
+		score := 40
+		if err := store.SaveNotifiedArticle(ctx, "https://example.com/notified", "1", "Notified", 80); err != nil {
+			t.Fatalf("SaveNotifiedArticle failed: %v", err)
+		}
+		if err := store.SaveRejectedArticle(ctx, "https://example.com/low", config.ReasonLowRelevance, &score); err != nil {
+			t.Fatalf("SaveRejectedArticle failed: %v", err)
+		}
+		if err := store.SaveRejectedArticle(ctx, "https://example.com/failed", config.ReasonContentExtractionFailed, nil); err != nil {
+			t.Fatalf("SaveRejectedArticle failed: %v", err)
+		}
+
+		// 5日後: 抽出失敗（3日）のみ期限切れ
+		setTimeNow(t, time.Now().AddDate(0, 0, 5))
+
+		rejected, err := store.IsArticleRejected(ctx, "https://example.com/failed", true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if rejected {
+			t.Error("保持期間を過ぎた抽出失敗記事が却下済みと判定されました")
+		}
+		rejected, err = store.IsArticleRejected(ctx, "https://example.com/low", true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if !rejected {
+			t.Error("保持期間内の低関連性記事が却下済みと判定されませんでした")
+		}
+
+		dryRun, err := store.PurgeExpired(ctx, PurgeOptions{BatchSize: 1, DryRun: true})
+		if err != nil {
+			t.Fatalf("PurgeExpired (dry run) failed: %v", err)
+		}
+		if dryRun.NotifiedDeleted != 0 || dryRun.RejectedDeleted != 1 {
+			t.Errorf("DryRunの結果が不正: %+v", dryRun)
+		}
+
+		// 31日後: すべて期限切れ
+		setTimeNow(t, time.Now().AddDate(0, 0, 31))
+
+		notified, err := store.IsArticleNotified(ctx, "https://example.com/notified")
+		if err != nil {
+			t.Fatalf("IsArticleNotified failed: %v", err)
+		}
+		if notified {
+			t.Error("保持期間を過ぎた通知済み記事が通知済みと判定されました")
+		}
+
+		result, err := store.PurgeExpired(ctx, PurgeOptions{BatchSize: 1})
+		if err != nil {
+			t.Fatalf("PurgeExpired failed: %v", err)
+		}
+		if result.NotifiedDeleted != 1 || result.RejectedDeleted != 2 {
+			t.Errorf("パージ結果が不正: %+v", result)
+		}
+
+		again, err := store.PurgeExpired(ctx, PurgeOptions{})
+		if err != nil {
+			t.Fatalf("PurgeExpired failed: %v", err)
+		}
+		if again.Total() != 0 {
+			t.Errorf("パージ後に削除対象が残っています: %+v", again)
+		}
+	})
+
+	t.Run("評価条件の変更による再評価", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		score := 30
+
+		store.SetEvaluationContext(EvaluationContext{InterestsHash: "hash-a", PromptVersion: "v1"})
+		for _, url := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+			if err := store.SaveRejectedArticle(ctx, url, config.ReasonLowRelevance, &score); err != nil {
+				t.Fatalf("SaveRejectedArticle failed: %v", err)
+			}
+		}
+		if err := store.SaveRejectedArticle(ctx, "https://example.com/failed", config.ReasonContentExtractionFailed, nil); err != nil {
+			t.Fatalf("SaveRejectedArticle failed: %v", err)
+		}
+
+		// 同じ評価条件では却下済みのまま
+		rejected, err := store.IsArticleRejected(ctx, "https://example.com/a", true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if !rejected {
+			t.Error("同じ評価条件で却下済みと判定されませんでした")
+		}
+
+		// 興味トピックが変わると予算（2件）の範囲で再評価される
+		store.SetEvaluationContext(EvaluationContext{InterestsHash: "hash-b", PromptVersion: "v1", MaxRechecksPerRun: 2})
+
+		// 再評価に回さない確認（重複チェックなど）は予算を消費しない
+		for _, url := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+			rejected, err := store.IsArticleRejected(ctx, url, false)
+			if err != nil {
+				t.Fatalf("IsArticleRejected failed: %v", err)
+			}
+			processed, err := IsArticleProcessed(ctx, store, url)
+			if err != nil {
+				t.Fatalf("IsArticleProcessed failed: %v", err)
+			}
+			if rejected || processed {
+				t.Errorf("予算内のstaleな却下が却下済みと判定されました: %s", url)
+			}
+		}
+		if used := store.RechecksUsed(); used != 0 {
+			t.Errorf("確認のみで再評価の予算を消費しました: %d", used)
+		}
+
+		var rechecked int
+		for _, url := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+			rejected, err := store.IsArticleRejected(ctx, url, true)
+			if err != nil {
+				t.Fatalf("IsArticleRejected failed: %v", err)
+			}
+			if !rejected {
+				rechecked++
+			}
+		}
+		if rechecked != 2 || store.RechecksUsed() != 2 {
+			t.Errorf("再評価数が不正: 期待=2, 実際=%d (RechecksUsed=%d)", rechecked, store.RechecksUsed())
+		}
+
+		// コンテンツ抽出失敗は評価条件に依存しない
+		rejected, err = store.IsArticleRejected(ctx, "https://example.com/failed", true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if !rejected {
+			t.Error("抽出失敗の却下が再評価の対象になりました")
+		}
+
+		// プロンプトのバージョンが変わった場合も再評価される（予算無制限）
+		store.SetEvaluationContext(EvaluationContext{InterestsHash: "hash-a", PromptVersion: "v2"})
+		rejected, err = store.IsArticleRejected(ctx, "https://example.com/a", true)
+		if err != nil {
+			t.Fatalf("IsArticleRejected failed: %v", err)
+		}
+		if rejected {
+			t.Error("プロンプトのバージョン変更後に却下済みと判定されました")
+		}
+	})
+
+	t.Run("評価記録の保存と検索", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		store.SetEvaluationContext(EvaluationContext{InterestsHash: "hash-a", PromptVersion: "v1"})
+
+		base := time.Now().Add(-time.Hour).Truncate(time.Second)
+		records := []config.EvaluationRecord{
+			{ArticleURL: "https://example.com/go", EvaluatedAt: base, RelevanceScore: 85, IsRelevant: true, MatchingTopics: []string{"Go"}, Reasoning: "詳細な実装例", PromptVersion: "v1", Model: "gemini-2.0-flash", TokenUsage: config.TokenUsage{PromptTokens: 1200, CandidatesTokens: 80, TotalTokens: 1280}, ContentLength: 4200},
+			{ArticleURL: "https://example.com/ai", EvaluatedAt: base.Add(10 * time.Minute), RelevanceScore: 0, IsAIGenerated: true, Reasoning: "AI生成の疑い", PromptVersion: "v1", Model: "gemini-2.0-flash"},
+			{ArticleURL: "https://example.com/go", EvaluatedAt: base.Add(20 * time.Minute), RelevanceScore: 70, IsRelevant: true, MatchingTopics: []string{"Go", "Kubernetes"}, PromptVersion: "v2", Model: "gemini-2.0-flash"},
+		}
+		for i := range records {
+			if err := store.SaveEvaluation(ctx, &records[i]); err != nil {
+				t.Fatalf("SaveEvaluation failed: %v", err)
+			}
+		}
+
+		all, err := store.ListEvaluations(ctx, EvaluationQuery{})
+		if err != nil {
+			t.Fatalf("ListEvaluations failed: %v", err)
+		}
+		if len(all) != 3 {
+			t.Fatalf("評価記録の件数が不正: 期待=3, 実際=%d", len(all))
+		}
+		if all[0].PromptVersion != "v2" {
+			t.Errorf("評価日時の新しい順になっていません: %+v", all[0])
+		}
+		if all[2].Reasoning != "詳細な実装例" || all[2].TokenUsage.TotalTokens != 1280 || all[2].ContentLength != 4200 {
+			t.Errorf("評価記録の内容が保存されていません: %+v", all[2])
+		}
+		if all[2].InterestsHash != "hash-a" || all[2].ExpireAt.IsZero() {
+			t.Errorf("評価条件または保持期限が記録されていません: %+v", all[2])
+		}
+
+		byURL, err := store.ListEvaluations(ctx, EvaluationQuery{ArticleURL: "https://example.com/go"})
+		if err != nil {
+			t.Fatalf("ListEvaluations failed: %v", err)
+		}
+		if len(byURL) != 2 || byURL[0].RelevanceScore != 70 {
+			t.Errorf("記事URLでの検索結果が不正: %+v", byURL)
+		}
+
+		byTopic, err := store.ListEvaluations(ctx, EvaluationQuery{Topic: "Kubernetes"})
+		if err != nil {
+			t.Fatalf("ListEvaluations failed: %v", err)
+		}
+		if len(byTopic) != 1 {
+			t.Errorf("トピックでの検索結果が不正: %+v", byTopic)
+		}
+
+		aiGenerated, err := store.ListEvaluations(ctx, EvaluationQuery{AIGeneratedOnly: true})
+		if err != nil {
+			t.Fatalf("ListEvaluations failed: %v", err)
+		}
+		if len(aiGenerated) != 1 || aiGenerated[0].ArticleURL != "https://example.com/ai" {
+			t.Errorf("AI生成判定での検索結果が不正: %+v", aiGenerated)
+		}
+
+		recent, err := store.ListEvaluations(ctx, EvaluationQuery{Since: base.Add(5 * time.Minute), MinScore: 50, Limit: 1})
+		if err != nil {
+			t.Fatalf("ListEvaluations failed: %v", err)
+		}
+		if len(recent) != 1 || recent[0].RelevanceScore != 70 {
+			t.Errorf("期間とスコアでの検索結果が不正: %+v", recent)
+		}
+	})
+
+	t.Run("評価キャッシュの保存と取得", func(t *testing.T) {
+		store := openStore(t, newStore)
+		store.SetRetentionSettings(config.RetentionSettings{EvaluationCacheDays: 3})
+		ctx := context.Background()
+		key := "3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea"
+
+		entry, err := store.GetCachedEvaluation(ctx, key)
+		if err != nil {
+			t.Fatalf("GetCachedEvaluation failed: %v", err)
+		}
+		if entry != nil {
+			t.Errorf("保存前にキャッシュが見つかりました: %+v", entry)
+		}
+
+		saved := &config.CachedEvaluation{
+			ContentHash:    "content-hash",
+			InterestsHash:  "hash-a",
+			PromptVersion:  "v3",
+			Model:          "gemini-2.0-flash",
+			RelevanceScore: 80,
+			MatchingTopics: []string{"Go"},
+			Summary:        "Goの並行処理の解説",
+			TokenUsage:     config.TokenUsage{TotalTokens: 1280},
+		}
+		if err := store.SaveCachedEvaluation(ctx, key, saved); err != nil {
+			t.Fatalf("SaveCachedEvaluation failed: %v", err)
+		}
+
+		entry, err = store.GetCachedEvaluation(ctx, key)
+		if err != nil {
+			t.Fatalf("GetCachedEvaluation failed: %v", err)
+		}
+		if entry == nil || entry.RelevanceScore != 80 || entry.Summary != saved.Summary || entry.TokenUsage.TotalTokens != 1280 {
+			t.Fatalf("キャッシュの内容が保存されていません: %+v", entry)
+		}
+		if entry.CachedAt.IsZero() || entry.ExpireAt.IsZero() {
+			t.Errorf("作成日時または有効期限が記録されていません: %+v", entry)
+		}
+
+		// 4日後: 有効期間（3日）を過ぎたキャッシュは使用しない
+		setTimeNow(t, time.Now().AddDate(0, 0, 4))
+
+		entry, err = store.GetCachedEvaluation(ctx, key)
+		if err != nil {
+			t.Fatalf("GetCachedEvaluation failed: %v", err)
+		}
+		if entry != nil {
+			t.Errorf("有効期間を過ぎたキャッシュが見つかりました: %+v", entry)
+		}
+
+		result, err := store.PurgeExpired(ctx, PurgeOptions{})
+		if err != nil {
+			t.Fatalf("PurgeExpired failed: %v", err)
+		}
+		if result.CacheDeleted != 1 {
+			t.Errorf("パージ結果が不正: %+v", result)
+		}
+	})
+
+	t.Run("LLMの使用量の日ごとの集計", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+
+		daily, err := store.GetDailyUsage(ctx, "2025-10-27")
+		if err != nil {
+			t.Fatalf("GetDailyUsage failed: %v", err)
+		}
+		if daily.Date != "2025-10-27" || daily.TotalTokens != 0 || daily.Requests != 0 {
+			t.Errorf("記録がない日の使用量が0ではありません: %+v", daily)
+		}
+
+		// 同じ日の使用量は加算され、別の日の使用量とは分けて集計される
+		runs := []struct {
+			date     string
+			usage    config.TokenUsage
+			requests int
+		}{
+			{"2025-10-27", config.TokenUsage{PromptTokens: 1000, CandidatesTokens: 200, TotalTokens: 1200}, 2},
+			{"2025-10-27", config.TokenUsage{PromptTokens: 500, CandidatesTokens: 100, TotalTokens: 600}, 1},
+			{"2025-10-28", config.TokenUsage{PromptTokens: 300, CandidatesTokens: 50, TotalTokens: 350}, 1},
+		}
+		for _, run := range runs {
+			if err := store.AddDailyUsage(ctx, run.date, run.usage, run.requests); err != nil {
+				t.Fatalf("AddDailyUsage failed: %v", err)
+			}
+		}
+
+		daily, err = store.GetDailyUsage(ctx, "2025-10-27")
+		if err != nil {
+			t.Fatalf("GetDailyUsage failed: %v", err)
+		}
+		if daily.PromptTokens != 1500 || daily.CandidatesTokens != 300 || daily.TotalTokens != 1800 || daily.Requests != 3 {
+			t.Errorf("使用量が加算されていません: %+v", daily)
+		}
+		if daily.UpdatedAt.IsZero() {
+			t.Errorf("更新日時が記録されていません: %+v", daily)
+		}
+
+		daily, err = store.GetDailyUsage(ctx, "2025-10-28")
+		if err != nil {
+			t.Fatalf("GetDailyUsage failed: %v", err)
+		}
+		if daily.TotalTokens != 350 || daily.Requests != 1 {
+			t.Errorf("別の日の使用量が不正: %+v", daily)
+		}
+	})
+
+	t.Run("実行履歴の保存と取得", func(t *testing.T) {
+		store := openStore(t, newStore)
+		ctx := context.Background()
+		base := time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC)
+
+		for i := 0; i < 3; i++ {
+			run := &config.RunHistory{
+				StartedAt:     base.AddDate(0, 0, i),
+				FinishedAt:    base.AddDate(0, 0, i).Add(time.Minute),
+				Status:        config.RunStatusSuccess,
+				NotifiedCount: i,
+			}
+			if err := store.SaveRunHistory(ctx, run); err != nil {
+				t.Fatalf("SaveRunHistory failed: %v", err)
+			}
+		}
+
+		runs, err := store.ListRunHistory(ctx, 2)
+		if err != nil {
+			t.Fatalf("ListRunHistory failed: %v", err)
+		}
+		if len(runs) != 2 {
+			t.Fatalf("実行履歴の件数が不正: 期待=2, 実際=%d", len(runs))
+		}
+		if runs[0].NotifiedCount != 2 || runs[1].NotifiedCount != 1 {
+			t.Errorf("実行履歴が新しい順に並んでいません: %+v", runs)
+		}
+		if !runs[0].StartedAt.Equal(base.AddDate(0, 0, 2)) {
+			t.Errorf("開始日時が不正: %v", runs[0].StartedAt)
+		}
+	})
+}
+
+// openStore はStoreを作成し、テスト終了時に閉じます
+func openStore(t *testing.T, newStore storeFactory) Store {
+	t.Helper()
+	store := newStore(t)
+	t.Cleanup(func() {
+		if err := store.Close(); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	})
+	return store
+}
+
+// setTimeNow は現在時刻を差し替え、テスト終了時に元に戻します
+func setTimeNow(t *testing.T, now time.Time) {
+	t.Helper()
+	original := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = original
+	})
+}
+
+func TestNewStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewStore(ctx, StoreOptions{Backend: BackendMemory})
+	if err != nil {
+		t.Fatalf("NewStore(memory) failed: %v", err)
+	}
+	if _, ok := store.(*MemoryStore); !ok {
+		t.Errorf("memoryバックエンドの型が不正: %T", store)
+	}
+
+	store, err = NewStore(ctx, StoreOptions{Backend: BackendBolt, Path: filepath.Join(t.TempDir(), "curator.db")})
+	if err != nil {
+		t.Fatalf("NewStore(bolt) failed: %v", err)
+	}
+	defer store.Close()
+	if _, ok := store.(*BoltStore); !ok {
+		t.Errorf("boltバックエンドの型が不正: %T", store)
+	}
+
+	if _, err := NewStore(ctx, StoreOptions{Backend: "unknown"}); err == nil {
+		t.Error("未知のバックエンドでエラーが返されませんでした")
+	}
+}
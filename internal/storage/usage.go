@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+)
+
+const (
+	// LLMUsageCollection はLLMの1日ごとの使用量を保存するコレクション名（ドキュメントIDは日付）
+	LLMUsageCollection = "llm_usage"
+)
+
+// addDailyUsage は1日分の使用量にトークン使用量と呼び出し回数を加算します
+func addDailyUsage(daily *config.DailyUsage, usage config.TokenUsage, requests int) {
+	daily.PromptTokens += usage.PromptTokens
+	daily.CandidatesTokens += usage.CandidatesTokens
+	daily.TotalTokens += usage.TotalTokens
+	daily.Requests += requests
+	daily.UpdatedAt = timeNow()
+}
+
+// GetDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量を返します（記録がない場合は使用量0）
+func (c *Client) GetDailyUsage(ctx context.Context, date string) (*config.DailyUsage, error) {
+	doc, err := c.client.Collection(LLMUsageCollection).Doc(date).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return &config.DailyUsage{Date: date}, nil
+		}
+		return nil, fmt.Errorf("failed to get daily usage: %w", err)
+	}
+
+	var daily config.DailyUsage
+	if err := doc.DataTo(&daily); err != nil {
+		return nil, fmt.Errorf("failed to parse daily usage: %w", err)
+	}
+	return &daily, nil
+}
+
+// AddDailyUsage は指定した日（YYYY-MM-DD）のLLMの使用量にトークン使用量と呼び出し回数を加算します
+// 複数の実行が同時に加算しても失われないよう、Firestoreのインクリメントを使用します
+func (c *Client) AddDailyUsage(ctx context.Context, date string, usage config.TokenUsage, requests int) error {
+	_, err := c.client.Collection(LLMUsageCollection).Doc(date).Set(ctx, map[string]interface{}{
+		"date":              date,
+		"prompt_tokens":     firestore.Increment(usage.PromptTokens),
+		"candidates_tokens": firestore.Increment(usage.CandidatesTokens),
+		"total_tokens":      firestore.Increment(usage.TotalTokens),
+		"requests":          firestore.Increment(requests),
+		"updated_at":        timeNow(),
+	}, firestore.MergeAll)
+	if err != nil {
+		return fmt.Errorf("failed to add daily usage: %w", err)
+	}
+	return nil
+}
@@ -11,3 +11,42 @@ resource "google_firestore_database" "database" {
   # ABANDON: terraform destroyでもFirestoreは削除されず、GCPコンソールから手動削除が必要
   deletion_policy = "ABANDON"
 }
+
+# ネイティブTTLポリシー
+# アプリケーションが書き込むexpire_atフィールドを過ぎたドキュメントをFirestoreが自動削除する
+# （削除は期限から通常24時間以内に実行される）
+resource "google_firestore_field" "notified_articles_ttl" {
+  project    = var.project_id
+  database   = google_firestore_database.database.name
+  collection = "notified_articles"
+  field      = "expire_at"
+
+  ttl_config {}
+}
+
+resource "google_firestore_field" "rejected_articles_ttl" {
+  project    = var.project_id
+  database   = google_firestore_database.database.name
+  collection = "rejected_articles"
+  field      = "expire_at"
+
+  ttl_config {}
+}
+
+resource "google_firestore_field" "evaluations_ttl" {
+  project    = var.project_id
+  database   = google_firestore_database.database.name
+  collection = "evaluations"
+  field      = "expire_at"
+
+  ttl_config {}
+}
+
+resource "google_firestore_field" "evaluation_cache_ttl" {
+  project    = var.project_id
+  database   = google_firestore_database.database.name
+  collection = "evaluation_cache"
+  field      = "expire_at"
+
+  ttl_config {}
+}
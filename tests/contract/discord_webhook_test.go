@@ -3,13 +3,16 @@ package contract
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/kaka0913/discord-article-bot/internal/config"
 	"github.com/kaka0913/discord-article-bot/internal/discord"
+	apperrors "github.com/kaka0913/discord-article-bot/internal/errors"
 	"github.com/kaka0913/discord-article-bot/internal/logging"
 )
 
@@ -291,6 +294,41 @@ func TestDiscordWebhookInvalidWebhook(t *testing.T) {
 	}
 }
 
+// TestDiscordWebhookResponseTooLarge はレスポンスが上限サイズを超える場合のテスト
+// 送信済みの可能性があるため、重複投稿を避けてリトライしないことを確認する
+func TestDiscordWebhookResponseTooLarge(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":"` + strings.Repeat("9", 4096) + `"}`))
+	}))
+	defer server.Close()
+
+	logger := logging.NewLogger()
+	client := discord.NewClient(server.URL, logger)
+	client.SetMaxResponseSize(1024)
+
+	articles := []discord.Article{
+		{
+			Title:       "Test",
+			Description: "Test",
+			URL:         "https://example.com",
+			Relevance:   90,
+			Topics:      []string{"Test"},
+			Source:      "Test",
+		},
+	}
+
+	_, err := client.PostArticles(context.Background(), articles, "2025-10-27", nil)
+	if !errors.Is(err, apperrors.ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got: %v", err)
+	}
+	if requestCount != 1 {
+		t.Errorf("Expected 1 request (no retry), got %d", requestCount)
+	}
+}
+
 // TestDiscordWebhookRateLimit はレート制限のテスト
 func TestDiscordWebhookRateLimit(t *testing.T) {
 	requestCount := 0
@@ -371,3 +409,102 @@ func TestDiscordWebhookTooManyEmbeds(t *testing.T) {
 		t.Errorf("Expected error message to contain 'too many embeds', got: %v", err)
 	}
 }
+
+// TestDiscordWebhookArticleMetadata は記事のメタデータ（サムネイル・著者・公開日時・読了時間）の表示のテスト
+func TestDiscordWebhookArticleMetadata(t *testing.T) {
+	publishedAt := time.Date(2025, 10, 27, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	payload := discord.FormatArticlesPayload([]discord.Article{
+		{
+			Title:              "Goのエラー処理入門",
+			Description:        "Goのエラー処理について解説した記事",
+			URL:                "https://zenn.dev/example/articles/go-errors",
+			Relevance:          85,
+			Topics:             []string{"Go"},
+			Source:             "Zenn",
+			SiteName:           "Zenn",
+			Author:             "example",
+			ThumbnailURL:       "https://zenn.dev/images/og.png",
+			PublishedAt:        publishedAt,
+			ReadingTimeMinutes: 8,
+			Paywalled:          true,
+		},
+		{
+			Title:       "メタデータのない記事",
+			Description: "Test Description",
+			URL:         "https://example.com",
+			Relevance:   70,
+			Topics:      []string{"Test"},
+			Source:      "Test Source",
+		},
+	}, "2025-10-27", nil)
+
+	embed := payload.Embeds[0]
+	if embed.Thumbnail == nil || embed.Thumbnail.URL != "https://zenn.dev/images/og.png" {
+		t.Errorf("Expected thumbnail, got: %+v", embed.Thumbnail)
+	}
+	if embed.Author == nil || embed.Author.Name != "example" {
+		t.Errorf("Expected author, got: %+v", embed.Author)
+	}
+	if embed.Timestamp != "2025-10-27T00:00:00Z" {
+		t.Errorf("Expected timestamp 2025-10-27T00:00:00Z, got: %s", embed.Timestamp)
+	}
+
+	var readingTime, tags string
+	for _, field := range embed.Fields {
+		switch field.Name {
+		case "Reading Time":
+			readingTime = field.Value
+		case "Tags":
+			tags = field.Value
+		}
+	}
+	if readingTime != "⏱ 8 min read" {
+		t.Errorf("Expected reading time field '⏱ 8 min read', got: %q", readingTime)
+	}
+	if tags != "🔒 paywalled" {
+		t.Errorf("Expected tags field '🔒 paywalled', got: %q", tags)
+	}
+
+	// メタデータがない場合は表示しない
+	plain := payload.Embeds[1]
+	if plain.Thumbnail != nil || plain.Author != nil || plain.Timestamp != "" || len(plain.Fields) != 2 {
+		t.Errorf("Expected no metadata, got: %+v", plain)
+	}
+
+	// JSONでは未設定の項目を省略する
+	data, err := json.Marshal(plain)
+	if err != nil {
+		t.Fatalf("Failed to marshal embed: %v", err)
+	}
+	for _, key := range []string{`"thumbnail"`, `"author"`, `"timestamp"`} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("Expected %s to be omitted: %s", key, data)
+		}
+	}
+}
+
+func TestDiscordWebhookUsageFooter(t *testing.T) {
+	runUsage := config.TokenUsage{PromptTokens: 9000, CandidatesTokens: 1000, TotalTokens: 10000}
+	daily := config.DailyUsage{TotalTokens: 45000, Requests: 20}
+
+	footer := discord.FormatUsageFooter(runUsage, 8, daily, config.LLMSettings{DailyTokenBudget: 100000})
+	expected := "LLM usage: 10000 tokens, 8 requests | Today: 45000/100000 tokens, 20 requests"
+	if footer != expected {
+		t.Errorf("Expected footer %q, got: %q", expected, footer)
+	}
+
+	articles := []discord.Article{{Title: "Test Article", URL: "https://example.com", Relevance: 80, Source: "Test Source"}}
+
+	// サマリーのフッターに使用量を表示する
+	payload := discord.FormatArticlesPayload(articles, "2025-10-27", &discord.ArticlesSummary{OverallSummary: "今日のまとめ", Footer: footer})
+	if payload.Embeds[0].Footer == nil || payload.Embeds[0].Footer.Text != footer {
+		t.Errorf("Expected usage footer, got: %+v", payload.Embeds[0].Footer)
+	}
+
+	// フッターを設定しない場合は表示しない
+	payload = discord.FormatArticlesPayload(articles, "2025-10-27", &discord.ArticlesSummary{OverallSummary: "今日のまとめ"})
+	if payload.Embeds[0].Footer != nil {
+		t.Errorf("Expected no footer, got: %+v", payload.Embeds[0].Footer)
+	}
+}
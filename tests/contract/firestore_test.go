@@ -196,7 +196,7 @@ func TestSaveAndCheckRejectedArticle(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// 最初は却下されていないことを確認
-			rejected, err := client.IsArticleRejected(ctx, tc.articleURL)
+			rejected, err := client.IsArticleRejected(ctx, tc.articleURL, true)
 			if err != nil {
 				t.Fatalf("IsArticleRejected failed: %v", err)
 			}
@@ -211,7 +211,7 @@ func TestSaveAndCheckRejectedArticle(t *testing.T) {
 			}
 
 			// 保存後に却下済みであることを確認
-			rejected, err = client.IsArticleRejected(ctx, tc.articleURL)
+			rejected, err = client.IsArticleRejected(ctx, tc.articleURL, true)
 			if err != nil {
 				t.Fatalf("IsArticleRejected failed after save: %v", err)
 			}
@@ -248,7 +248,7 @@ func TestRejectedArticleTTL(t *testing.T) {
 	}
 
 	// TTL期限切れの記事は却下されていないとして扱われるべき
-	rejected, err := client.IsArticleRejected(ctx, articleURL)
+	rejected, err := client.IsArticleRejected(ctx, articleURL, true)
 	if err != nil {
 		t.Fatalf("IsArticleRejected failed: %v", err)
 	}
@@ -257,6 +257,130 @@ func TestRejectedArticleTTL(t *testing.T) {
 	}
 }
 
+// TestRejectedArticleRetentionByReason は却下理由ごとの保持期間設定をテストします
+func TestRejectedArticleRetentionByReason(t *testing.T) {
+	client := setupTestClient(t)
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		cleanupCollection(t, client, storage.RejectedArticlesCollection)
+	})
+
+	client.SetRetentionSettings(config.RetentionSettings{
+		RejectedDays: 30,
+		RejectedReasonDays: map[string]int{
+			config.ReasonContentExtractionFailed: 3,
+		},
+	})
+
+	// 5日前の抽出失敗記事は期限切れ、5日前の低関連性記事は有効
+	testCases := []struct {
+		name         string
+		articleURL   string
+		reason       string
+		wantRejected bool
+	}{
+		{
+			name:         "抽出失敗は3日で期限切れ",
+			articleURL:   "https://dev.to/example/extraction-failed",
+			reason:       config.ReasonContentExtractionFailed,
+			wantRejected: false,
+		},
+		{
+			name:         "低関連性は30日間有効",
+			articleURL:   "https://dev.to/example/low-relevance-recent",
+			reason:       config.ReasonLowRelevance,
+			wantRejected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			docID := storage.UrlToDocID(tc.articleURL)
+			article := config.RejectedArticle{
+				EvaluatedAt: time.Now().AddDate(0, 0, -5),
+				Reason:      tc.reason,
+			}
+			if _, err := client.GetClient().Collection(storage.RejectedArticlesCollection).Doc(docID).Set(ctx, article); err != nil {
+				t.Fatalf("Failed to save rejected article: %v", err)
+			}
+
+			rejected, err := client.IsArticleRejected(ctx, tc.articleURL, true)
+			if err != nil {
+				t.Fatalf("IsArticleRejected failed: %v", err)
+			}
+			if rejected != tc.wantRejected {
+				t.Errorf("IsArticleRejected = %v, want %v", rejected, tc.wantRejected)
+			}
+		})
+	}
+}
+
+// TestPurgeExpired は期限切れドキュメントのパージをテストします
+func TestPurgeExpired(t *testing.T) {
+	client := setupTestClient(t)
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		cleanupCollection(t, client, storage.NotifiedArticlesCollection)
+		cleanupCollection(t, client, storage.RejectedArticlesCollection)
+	})
+
+	notifiedCol := client.GetClient().Collection(storage.NotifiedArticlesCollection)
+	rejectedCol := client.GetClient().Collection(storage.RejectedArticlesCollection)
+
+	// expire_atを過ぎた記事
+	expired := config.NotifiedArticle{
+		NotifiedAt:       time.Now().AddDate(0, 0, -2),
+		DiscordMessageID: "1111111111111111111",
+		ArticleTitle:     "Expired Article",
+		RelevanceScore:   80,
+		ExpireAt:         time.Now().Add(-time.Hour),
+	}
+	if _, err := notifiedCol.Doc(storage.UrlToDocID("https://dev.to/example/expired")).Set(ctx, expired); err != nil {
+		t.Fatalf("Failed to save expired article: %v", err)
+	}
+
+	// expire_atを持たない旧データ（31日前）
+	legacy := config.RejectedArticle{
+		EvaluatedAt: time.Now().AddDate(0, 0, -31),
+		Reason:      config.ReasonLowRelevance,
+	}
+	if _, err := rejectedCol.Doc(storage.UrlToDocID("https://dev.to/example/legacy")).Set(ctx, legacy); err != nil {
+		t.Fatalf("Failed to save legacy article: %v", err)
+	}
+
+	// 有効期間内の記事
+	if err := client.SaveNotifiedArticle(ctx, "https://dev.to/example/fresh", "2222222222222222222", "Fresh Article", 90); err != nil {
+		t.Fatalf("SaveNotifiedArticle failed: %v", err)
+	}
+
+	// DryRunでは削除されない
+	dryRun, err := client.PurgeExpired(ctx, storage.PurgeOptions{BatchSize: 1, DryRun: true})
+	if err != nil {
+		t.Fatalf("PurgeExpired (dry run) failed: %v", err)
+	}
+	if dryRun.NotifiedDeleted != 1 || dryRun.RejectedDeleted != 1 {
+		t.Errorf("Dry run result = %+v, want 1 notified and 1 rejected", dryRun)
+	}
+
+	result, err := client.PurgeExpired(ctx, storage.PurgeOptions{BatchSize: 1})
+	if err != nil {
+		t.Fatalf("PurgeExpired failed: %v", err)
+	}
+	if result.Total() != 2 {
+		t.Errorf("PurgeExpired deleted %d documents, want 2", result.Total())
+	}
+
+	notified, err := client.IsArticleNotified(ctx, "https://dev.to/example/fresh")
+	if err != nil {
+		t.Fatalf("IsArticleNotified failed: %v", err)
+	}
+	if !notified {
+		t.Error("Expected fresh article to survive purge")
+	}
+}
+
 // TestDuplicateNotificationPrevention は重複通知を防ぐテストです
 func TestDuplicateNotificationPrevention(t *testing.T) {
 	client := setupTestClient(t)
@@ -0,0 +1,145 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBatchProvider はプロンプトに含まれる記事IDに対して評価結果を返すテスト用のProvider
+// バッチ評価のプロンプトではomitに含まれる記事IDの評価を応答から除き、1記事の評価では常に評価結果を返す
+type fakeBatchProvider struct {
+	omit    map[string]bool
+	prompts []string
+}
+
+var batchArticleIDPattern = regexp.MustCompile(`=== 記事ID: (A\d+) ===`)
+
+func (p *fakeBatchProvider) GenerateText(ctx context.Context, prompt string) (*llm.Generation, error) {
+	return p.GenerateJSON(ctx, prompt, nil)
+}
+
+func (p *fakeBatchProvider) GenerateJSON(_ context.Context, prompt string, _ *llm.Schema) (*llm.Generation, error) {
+	p.prompts = append(p.prompts, prompt)
+	usage := config.TokenUsage{PromptTokens: 3000, CandidatesTokens: 300, TotalTokens: 3300}
+
+	ids := batchArticleIDPattern.FindAllStringSubmatch(prompt, -1)
+	if len(ids) == 0 {
+		return &llm.Generation{Text: testEvaluationJSON, Usage: usage}, nil
+	}
+	var evaluations []string
+	for _, m := range ids {
+		if !p.omit[m[1]] {
+			evaluations = append(evaluations, fmt.Sprintf(`{"article_id": %q, %s`, m[1], strings.TrimPrefix(testEvaluationJSON, "{")))
+		}
+	}
+	// スキーマに対応しないバックエンドのように、JSONの前に説明文を付ける
+	return &llm.Generation{Text: "評価結果です。\n" + `{"evaluations": [` + strings.Join(evaluations, ",") + `]}`, Usage: usage}, nil
+}
+
+func (p *fakeBatchProvider) Model() string { return "fake-model" }
+
+// testBatchItems は同じ興味トピックで評価するn件の記事を返します
+func testBatchItems(n int, topics []config.InterestTopic) []llm.BatchItem {
+	items := make([]llm.BatchItem, n)
+	for i := range items {
+		article := testProviderArticle()
+		article.URL = fmt.Sprintf("https://example.com/articles/%d", i)
+		items[i] = llm.BatchItem{Article: article, Topics: topics}
+	}
+	return items
+}
+
+// TestEvaluateArticles_Batch は複数の記事を1回の呼び出しで評価し、応答に含まれない記事を1記事ずつ評価し直すことをテストします
+func TestEvaluateArticles_Batch(t *testing.T) {
+	provider := &fakeBatchProvider{omit: map[string]bool{"A2": true}}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetBatchOptions(llm.BatchOptions{MaxArticles: 5, InputTokenBudget: 100000, OutputTokenBudget: 4096})
+
+	items := testBatchItems(3, testTopics("Go"))
+	results := evaluator.EvaluateArticles(context.Background(), items, 70)
+
+	require.Len(t, results, 3)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		assert.Same(t, items[i].Article, result.Article, "結果は記事と同じ順序")
+		assert.Equal(t, items[i].Article.URL, result.Evaluation.ArticleURL)
+		assert.Equal(t, "fake-model", result.Evaluation.Model)
+	}
+
+	// バッチ評価1回と、応答に含まれなかったA2の再評価1回
+	require.Len(t, provider.prompts, 2)
+	assert.Contains(t, provider.prompts[0], "=== 記事ID: A3 ===")
+	assert.NotContains(t, provider.prompts[1], "記事ID")
+
+	// バッチ評価のトークン使用量は記事数で按分される
+	assert.Equal(t, 1100, results[0].Evaluation.TokenUsage.TotalTokens)
+	assert.Equal(t, 3300, results[1].Evaluation.TokenUsage.TotalTokens)
+}
+
+// TestEvaluateArticles_Planning は記事数・トークン数の上限と興味トピックの違いでバッチが分割されることをテストします
+func TestEvaluateArticles_Planning(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      llm.BatchOptions
+		items     []llm.BatchItem
+		wantCalls int
+	}{
+		{
+			name:      "バッチ評価なし",
+			opts:      llm.BatchOptions{},
+			items:     testBatchItems(3, testTopics("Go")),
+			wantCalls: 3,
+		},
+		{
+			name:      "記事数の上限",
+			opts:      llm.BatchOptions{MaxArticles: 2, InputTokenBudget: 100000, OutputTokenBudget: 4096},
+			items:     testBatchItems(5, testTopics("Go")),
+			wantCalls: 3,
+		},
+		{
+			name:      "出力トークン数の上限",
+			opts:      llm.BatchOptions{MaxArticles: 10, InputTokenBudget: 100000, OutputTokenBudget: 800},
+			items:     testBatchItems(4, testTopics("Go")),
+			wantCalls: 2,
+		},
+		{
+			name:      "入力トークン数の上限",
+			opts:      llm.BatchOptions{MaxArticles: 10, InputTokenBudget: 1500, OutputTokenBudget: 4096},
+			items:     testBatchItems(3, testTopics("Go")),
+			wantCalls: 3,
+		},
+		{
+			name:      "興味トピックごと",
+			opts:      llm.BatchOptions{MaxArticles: 10, InputTokenBudget: 100000, OutputTokenBudget: 4096},
+			items:     append(testBatchItems(2, testTopics("Go")), testBatchItems(2, testTopics("Go", "個人開発"))...),
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeBatchProvider{}
+			evaluator := llm.NewEvaluator(provider)
+			evaluator.SetBatchOptions(tt.opts)
+
+			for _, result := range evaluator.EvaluateArticles(context.Background(), tt.items, 70) {
+				require.NoError(t, result.Err)
+			}
+			assert.Len(t, provider.prompts, tt.wantCalls)
+		})
+	}
+}
+
+// TestBatchOptionsFromSettings は設定からバッチ評価の制限を作成できることをテストします
+func TestBatchOptionsFromSettings(t *testing.T) {
+	opts := llm.BatchOptionsFromSettings(config.LLMSettings{BatchSize: 5})
+	assert.Equal(t, llm.BatchOptions{MaxArticles: 5, InputTokenBudget: llm.DefaultBatchTokenBudget, OutputTokenBudget: llm.MaxOutputTokens}, opts)
+}
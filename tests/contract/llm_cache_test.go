@@ -0,0 +1,102 @@
+package contract
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/kaka0913/discord-article-bot/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluateArticle_Cache は評価済みの記事がLLMを呼び出さずにキャッシュから評価されることをテストします
+func TestEvaluateArticle_Cache(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	provider := &fakeBatchProvider{}
+
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetCache(store, "hash-a")
+
+	first, err := evaluator.EvaluateArticle(ctx, testProviderArticle(), testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+	assert.Equal(t, 3300, first.TokenUsage.TotalTokens)
+
+	// 同じ本文・興味トピック・プロンプト・モデルの評価はキャッシュを使う（閾値は評価のたびに適用する）
+	second, err := evaluator.EvaluateArticle(ctx, testProviderArticle(), testTopics("Go"), 90)
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.RelevanceScore, second.RelevanceScore)
+	assert.Equal(t, first.Summary, second.Summary)
+	assert.False(t, second.IsRelevant)
+	assert.Zero(t, second.TokenUsage.TotalTokens)
+	assert.Len(t, provider.prompts, 1)
+
+	// 本文が変わった記事はキャッシュを使わない
+	changed := testProviderArticle()
+	changed.ContentText += "\n追記"
+	_, err = evaluator.EvaluateArticle(ctx, changed, testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Len(t, provider.prompts, 2)
+
+	// 本文が同じでもタイトルが異なる記事や、フィードの要約のみで評価する記事はキャッシュを使わない
+	retitled := testProviderArticle()
+	retitled.Title = "別のタイトルの記事"
+	_, err = evaluator.EvaluateArticle(ctx, retitled, testTopics("Go"), 70)
+	require.NoError(t, err)
+	paywalled := testProviderArticle()
+	paywalled.Paywalled = true
+	_, err = evaluator.EvaluateArticle(ctx, paywalled, testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Len(t, provider.prompts, 4)
+
+	hits, misses := evaluator.CacheStats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 4, misses)
+
+	// 興味トピックが変わった場合はキャッシュを使わない
+	other := llm.NewEvaluator(provider)
+	other.SetCache(store, "hash-b")
+	_, err = other.EvaluateArticle(ctx, testProviderArticle(), testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Len(t, provider.prompts, 5)
+}
+
+// TestEvaluateArticles_Cache はキャッシュにある記事をバッチ評価から除き、バッチ評価の結果をキャッシュに保存することをテストします
+func TestEvaluateArticles_Cache(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	provider := &fakeBatchProvider{}
+
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetBatchOptions(llm.BatchOptions{MaxArticles: 5, InputTokenBudget: 100000, OutputTokenBudget: 4096})
+	evaluator.SetCache(store, "hash-a")
+
+	items := testBatchItems(3, testTopics("Go"))
+	// 1記事目は本文を変えて、他の記事とキャッシュのキーを分ける
+	items[0].Article.ContentText += "\n追記"
+	_, err := evaluator.EvaluateArticle(ctx, items[0].Article, items[0].Topics, 70)
+	require.NoError(t, err)
+
+	results := evaluator.EvaluateArticles(ctx, items, 70)
+	for _, result := range results {
+		require.NoError(t, result.Err)
+	}
+	assert.True(t, results[0].Evaluation.Cached)
+	assert.False(t, results[1].Evaluation.Cached)
+
+	// キャッシュにない2記事のみを1回のバッチで評価する
+	require.Len(t, provider.prompts, 2)
+	assert.Contains(t, provider.prompts[1], "=== 記事ID: A2 ===")
+	assert.NotContains(t, provider.prompts[1], "=== 記事ID: A3 ===")
+
+	// バッチ評価の結果もキャッシュに保存されている
+	again := evaluator.EvaluateArticles(ctx, items, 70)
+	for _, result := range again {
+		require.NoError(t, result.Err)
+		assert.True(t, result.Evaluation.Cached)
+	}
+	assert.Len(t, provider.prompts, 2)
+}
@@ -0,0 +1,237 @@
+package contract
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEvaluationTemplate は1記事の評価とバッチ評価を定義したカスタムの評価プロンプトのテンプレート
+const testEvaluationTemplate = `{{/*
+version: custom-1
+required: Topics, Article, Articles
+*/ -}}
+カスタム評価: {{.Topics}}
+記事タイトル: {{.Article.Title}}
+{{define "batch" -}}
+カスタムバッチ評価: {{.Topics}}
+{{range .Articles}}=== 記事ID: {{.ID}} ===
+記事タイトル: {{.Title}}
+{{end}}
+{{- end}}`
+
+// writePromptFiles は設定ファイルとプロンプトのテンプレートを一時ディレクトリに作成し、設定ファイルのパスを返します
+func writePromptFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+// TestDefaultPrompts は組み込みのプロンプトのテンプレートが検証を通ることをテストします
+func TestDefaultPrompts(t *testing.T) {
+	prompts := llm.DefaultPrompts()
+	require.NoError(t, prompts.Validate())
+	assert.Equal(t, "v8", prompts.Evaluation.Version)
+	assert.Equal(t, "v1", prompts.Summary.Version)
+	assert.Equal(t, "v1", prompts.Triage.Version)
+	assert.Equal(t, "v8", llm.NewEvaluator(&fakeBatchProvider{}).PromptVersion())
+}
+
+// evaluationPromptHashes は組み込みの評価プロンプトのバージョンごとの、サンプルの記事から生成したプロンプトのSHA-256
+// 評価プロンプトを変更した場合は、evaluation.tmplのversionを上げてハッシュを追加してください
+var evaluationPromptHashes = map[string]string{
+	"v8": "60974f9b08521b5f989b0ee89f005398bd4a999b17b915df7e237de0e9486a52",
+}
+
+// TestDefaultPrompts_EvaluationVersion は組み込みの評価プロンプトを変更した場合に、バージョンが更新されていることをテストします
+// 有料記事・サイトの評価・言語の注記を含むサンプルの記事で、1記事の評価とバッチ評価のプロンプトを生成して比較します
+func TestDefaultPrompts_EvaluationVersion(t *testing.T) {
+	article := testProviderArticle()
+	article.Paywalled = true
+	article.Tags = []string{"go", "concurrency"}
+	article.LikeCount = 120
+	article.Language = "en"
+	topics := []config.InterestTopic{{Topic: "Go", Aliases: []string{"Golang"}, Priority: "high"}}
+
+	provider := &fakeBatchProvider{}
+	evaluator := llm.NewEvaluator(provider)
+	_, err := evaluator.EvaluateArticle(context.Background(), article, topics, 70)
+	require.NoError(t, err)
+	evaluator.SetBatchOptions(llm.BatchOptions{MaxArticles: 5, InputTokenBudget: 100000, OutputTokenBudget: 4096})
+	items := testBatchItems(2, topics)
+	items[0].Article = article
+	evaluator.EvaluateArticles(context.Background(), items, 70)
+	require.Len(t, provider.prompts, 2)
+
+	sum := sha256.Sum256([]byte(strings.Join(provider.prompts, "\x00")))
+	version := evaluator.PromptVersion()
+	assert.Equal(t, evaluationPromptHashes[version], hex.EncodeToString(sum[:]),
+		"評価プロンプトが変更されています。evaluation.tmplのversion（現在 %s）を上げ、evaluationPromptHashesを更新してください", version)
+}
+
+// TestLoadPrompts_LocalFile は設定ファイルからの相対パスでテンプレートを読み込み、評価にバージョンを記録することをテストします
+func TestLoadPrompts_LocalFile(t *testing.T) {
+	ctx := context.Background()
+	configPath := writePromptFiles(t, map[string]string{
+		"config.json":         "{}",
+		"prompts/custom.tmpl": testEvaluationTemplate,
+	})
+
+	prompts, err := llm.LoadPrompts(ctx, config.NewLoader(), configPath, config.PromptSettings{Evaluation: "prompts/custom.tmpl"}, llm.BatchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "custom-1", prompts.Evaluation.Version)
+	assert.Equal(t, []string{"Topics", "Article", "Articles"}, prompts.Evaluation.Required)
+	assert.Equal(t, "v1", prompts.Summary.Version, "指定のないサマリーは組み込みのテンプレートを使う")
+
+	provider := &fakeBatchProvider{}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetPrompts(prompts)
+	assert.Equal(t, "custom-1", evaluator.PromptVersion())
+
+	evaluation, err := evaluator.EvaluateArticle(ctx, testProviderArticle(), testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Equal(t, "custom-1", evaluation.PromptVersion)
+	require.Len(t, provider.prompts, 1)
+	assert.Equal(t, "カスタム評価: [\"Go\"]\n記事タイトル: "+testProviderArticle().Title, provider.prompts[0])
+
+	// バッチ評価は"batch"テンプレートを使う
+	evaluator.SetBatchOptions(llm.BatchOptions{MaxArticles: 5, InputTokenBudget: 100000, OutputTokenBudget: 4096})
+	results := evaluator.EvaluateArticles(ctx, testBatchItems(3, testTopics("Go")), 70)
+	require.Len(t, provider.prompts, 2)
+	assert.Contains(t, provider.prompts[1], "カスタムバッチ評価")
+	assert.Contains(t, provider.prompts[1], "=== 記事ID: A3 ===")
+	for _, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, "custom-1", result.Evaluation.PromptVersion)
+	}
+}
+
+// TestLoadPrompts_URL はURLの設定ファイルからの相対パスでテンプレートを読み込むことをテストします
+func TestLoadPrompts_URL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/curator/prompts/summary.tmpl":
+			w.Write([]byte("{{/* version: summary-2 */}}記事数: {{len .Articles}}\n{{.ArticlesJSON}}"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	prompts, err := llm.LoadPrompts(context.Background(), config.NewLoader(), server.URL+"/curator/config.json", config.PromptSettings{Summary: "prompts/summary.tmpl"}, llm.BatchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "summary-2", prompts.Summary.Version)
+	assert.Equal(t, "v8", prompts.Evaluation.Version)
+
+	provider := &fakeBatchProvider{}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetPrompts(prompts)
+	_, _ = evaluator.GenerateArticlesSummary(context.Background(), []llm.ArticleForSummary{{Title: "記事1"}, {Title: "記事2"}})
+	require.Len(t, provider.prompts, 1)
+	assert.Contains(t, provider.prompts[0], "記事数: 2")
+}
+
+// testBatchOptions はバッチ評価を行う場合のバッチ評価の制限
+var testBatchOptions = llm.BatchOptions{MaxArticles: 5, InputTokenBudget: 100000, OutputTokenBudget: 4096}
+
+// TestLoadPrompts_WithoutBatch はバッチ評価を行わない場合に、バッチ評価のテンプレートを省略できることをテストします
+func TestLoadPrompts_WithoutBatch(t *testing.T) {
+	ctx := context.Background()
+	configPath := writePromptFiles(t, map[string]string{
+		"evaluation.tmpl": "{{/* version: single-1 */}}カスタム評価: {{.Topics}}\n記事タイトル: {{.Article.Title}}",
+	})
+
+	prompts, err := llm.LoadPrompts(ctx, config.NewLoader(), configPath, config.PromptSettings{Evaluation: "evaluation.tmpl"}, llm.BatchOptions{MaxArticles: 1})
+	require.NoError(t, err)
+	require.NoError(t, prompts.Validate())
+
+	// バッチ評価の制限を設定しても、バッチ評価のテンプレートがなければ1記事ずつ評価する
+	provider := &fakeBatchProvider{}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetPrompts(prompts)
+	evaluator.SetBatchOptions(testBatchOptions)
+	results := evaluator.EvaluateArticles(ctx, testBatchItems(3, testTopics("Go")), 70)
+	require.Len(t, provider.prompts, 3)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, "single-1", result.Evaluation.PromptVersion)
+		assert.True(t, strings.HasPrefix(provider.prompts[i], "カスタム評価"))
+	}
+}
+
+// TestLoadPrompts_Invalid は不正なテンプレートを起動時の検証でエラーにすることをテストします
+func TestLoadPrompts_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{
+			name:     "ヘッダーのコメントがない",
+			template: "{{.Topics}}",
+			wantErr:  "must start with",
+		},
+		{
+			name:     "バージョンがない",
+			template: "{{/*\nrequired: Topics\n*/}}{{.Topics}}{{define \"batch\"}}{{.Topics}}{{end}}",
+			wantErr:  "version is not declared",
+		},
+		{
+			name:     "不明なヘッダーの項目",
+			template: "{{/*\nversion: v9\nauthor: someone\n*/}}{{.Topics}}{{define \"batch\"}}{{.Topics}}{{end}}",
+			wantErr:  "unknown header key",
+		},
+		{
+			name:     "必須変数がプログラムの変数にない",
+			template: "{{/*\nversion: v9\nrequired: Topics, Interests\n*/}}{{.Topics}}{{define \"batch\"}}{{.Topics}}{{end}}",
+			wantErr:  `unknown variable "Interests"`,
+		},
+		{
+			name:     "存在しない変数の参照",
+			template: "{{/* version: v9 */}}{{.Article.Body}}{{define \"batch\"}}{{.Topics}}{{end}}",
+			wantErr:  "Body",
+		},
+		{
+			name:     "バッチ評価を行う場合にバッチ評価のテンプレートがない",
+			template: "{{/* version: v9 */}}{{.Topics}}",
+			wantErr:  `does not define template "batch"`,
+		},
+		{
+			name:     "構文エラー",
+			template: "{{/* version: v9 */}}{{if .Topics}}",
+			wantErr:  "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := writePromptFiles(t, map[string]string{"evaluation.tmpl": tt.template})
+			_, err := llm.LoadPrompts(context.Background(), config.NewLoader(), configPath, config.PromptSettings{Evaluation: "evaluation.tmpl"}, testBatchOptions)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("ファイルがない", func(t *testing.T) {
+		configPath := writePromptFiles(t, map[string]string{})
+		_, err := llm.LoadPrompts(context.Background(), config.NewLoader(), configPath, config.PromptSettings{Summary: "missing.tmpl"}, llm.BatchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing.tmpl")
+	})
+}
@@ -0,0 +1,249 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTopics は優先度mediumの興味トピックを返します
+func testTopics(names ...string) []config.InterestTopic {
+	topics := make([]config.InterestTopic, len(names))
+	for i, name := range names {
+		topics[i] = config.InterestTopic{Topic: name, Priority: "medium"}
+	}
+	return topics
+}
+
+// testEvaluationJSON はバックエンドのモックが返す評価結果
+const testEvaluationJSON = `{"relevance_score": 80, "matching_topics": ["Go"], "summary": "Goのゴルーチンとチャネルを使ったワーカープールの実装方法を、キャンセル処理やエラーハンドリングを含めて具体的なコード例とともに解説した記事。", "reasoning": "詳細な実装例あり", "is_ai_generated": false}`
+
+// TestOpenAICompatibleProvider はOpenAI互換のChat Completions APIのリクエストと応答の変換をテストします
+func TestOpenAICompatibleProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var req llm.OpenAIRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "qwen2.5-7b-instruct", req.Model)
+		assert.Equal(t, 0.3, req.Temperature)
+		assert.Equal(t, 1024, req.MaxTokens)
+		require.Len(t, req.Messages, 1)
+		assert.Equal(t, "user", req.Messages[0].Role)
+		require.NotNil(t, req.ResponseFormat)
+		assert.Equal(t, "json_object", req.ResponseFormat.Type)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": testEvaluationJSON}, "finish_reason": "stop"},
+			},
+			"usage": map[string]int{"prompt_tokens": 1200, "completion_tokens": 80, "total_tokens": 1280},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := llm.NewProvider(config.LLMSettings{
+		Provider:        config.LLMProviderOpenAI,
+		Model:           "qwen2.5-7b-instruct",
+		Endpoint:        server.URL + "/v1/",
+		MaxOutputTokens: 1024,
+	}, "test-key", 1024*1024)
+	require.NoError(t, err)
+	assert.Equal(t, "qwen2.5-7b-instruct", provider.Model())
+
+	evaluation, err := llm.NewEvaluator(provider).EvaluateArticle(context.Background(), testProviderArticle(), testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Equal(t, 80, evaluation.RelevanceScore)
+	assert.True(t, evaluation.IsRelevant)
+	assert.Equal(t, "qwen2.5-7b-instruct", evaluation.Model)
+	assert.Equal(t, config.TokenUsage{PromptTokens: 1200, CandidatesTokens: 80, TotalTokens: 1280}, evaluation.TokenUsage)
+}
+
+// TestOpenAICompatibleProvider_Errors はOpenAI互換APIのエラー応答と打ち切られた応答をテストします
+func TestOpenAICompatibleProvider_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"認証エラー", http.StatusUnauthorized, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`, "Incorrect API key provided"},
+		{"トークン上限", http.StatusOK, `{"choices": [{"message": {"content": "{\"relevance"}, "finish_reason": "length"}]}`, "max tokens"},
+		{"候補なし", http.StatusOK, `{"choices": []}`, "no choices"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider, err := llm.NewProvider(config.LLMSettings{Provider: config.LLMProviderOpenAI, Endpoint: server.URL}, "", 1024*1024)
+			require.NoError(t, err)
+			_, err = provider.GenerateText(context.Background(), "prompt")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestOllamaProvider はOllamaの生成APIのリクエストと応答の変換をテストします
+func TestOllamaProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/generate", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var req llm.OllamaRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, llm.DefaultOllamaModel, req.Model)
+		assert.False(t, req.Stream)
+		assert.Equal(t, "json", req.Format)
+		assert.Equal(t, llm.MaxOutputTokens, req.Options.NumPredict)
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"response":          testEvaluationJSON,
+			"done":              true,
+			"done_reason":       "stop",
+			"prompt_eval_count": 900,
+			"eval_count":        70,
+		})
+	}))
+	defer server.Close()
+
+	provider, err := llm.NewProvider(config.LLMSettings{Provider: config.LLMProviderOllama, Endpoint: server.URL}, "", 1024*1024)
+	require.NoError(t, err)
+
+	evaluation, err := llm.NewEvaluator(provider).EvaluateArticle(context.Background(), testProviderArticle(), testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Equal(t, llm.DefaultOllamaModel, evaluation.Model)
+	assert.Equal(t, config.TokenUsage{PromptTokens: 900, CandidatesTokens: 70, TotalTokens: 970}, evaluation.TokenUsage)
+}
+
+// TestGeminiProvider_Settings はGeminiのモデルとエンドポイントを設定で変更できることをテストします
+func TestGeminiProvider_Settings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-2.5-flash:generateContent", r.URL.Path)
+		assert.Equal(t, "test-key", r.URL.Query().Get("key"))
+
+		var req llm.GeminiRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, 4096, req.GenerationConfig.MaxOutputTokens)
+		assert.Equal(t, "application/json", req.GenerationConfig.ResponseMimeType)
+		require.NotNil(t, req.GenerationConfig.ResponseSchema)
+		assert.Equal(t, "INTEGER", req.GenerationConfig.ResponseSchema.Properties["relevance_score"].Type)
+
+		json.NewEncoder(w).Encode(llm.GeminiResponse{
+			Candidates: []llm.Candidate{{
+				Content:      llm.CandidateContent{Parts: []llm.Part{{Text: "```json\n" + testEvaluationJSON + "\n```"}}, Role: "model"},
+				FinishReason: "STOP",
+			}},
+			UsageMetadata: llm.UsageMetadata{PromptTokenCount: 1000, CandidatesTokenCount: 60, TotalTokenCount: 1060},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := llm.NewProvider(config.LLMSettings{
+		Model:           "gemini-2.5-flash",
+		Endpoint:        server.URL + "/v1beta",
+		MaxOutputTokens: 4096,
+	}, "test-key", 1024*1024)
+	require.NoError(t, err)
+
+	evaluation, err := llm.NewEvaluator(provider).EvaluateArticle(context.Background(), testProviderArticle(), testTopics("Go"), 70)
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-2.5-flash", evaluation.Model)
+	assert.Equal(t, 1060, evaluation.TokenUsage.TotalTokens)
+}
+
+// TestNewProvider_Defaults はバックエンドごとのデフォルト値とAPIキーの要否をテストします
+func TestNewProvider_Defaults(t *testing.T) {
+	gemini, err := llm.NewProvider(config.LLMSettings{}, "test-key", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, llm.ModelName, gemini.Model())
+
+	_, err = llm.NewProvider(config.LLMSettings{}, "", 1024)
+	assert.Error(t, err, "GeminiはAPIキーが必須")
+
+	openai, err := llm.NewProvider(config.LLMSettings{Provider: config.LLMProviderOpenAI}, "", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, llm.DefaultOpenAIModel, openai.Model())
+
+	settings := config.LLMSettings{}
+	assert.Equal(t, config.DefaultGeminiAPIKeySecret, settings.APIKeySecretName())
+	settings = config.LLMSettings{Provider: config.LLMProviderOllama}
+	assert.Empty(t, settings.APIKeySecretName())
+	settings = config.LLMSettings{Provider: config.LLMProviderOpenAI, APIKeySecret: "openai-api-key"}
+	assert.Equal(t, "openai-api-key", settings.APIKeySecretName())
+}
+
+// TestSchemaFor は結果の構造体からGemini APIのresponseSchemaを生成できることをテストします
+func TestSchemaFor(t *testing.T) {
+	schema := llm.SchemaFor(llm.EvaluationResult{})
+	assert.Equal(t, "OBJECT", schema.Type)
+	assert.Equal(t, []string{"relevance_score", "matching_topics", "summary", "reasoning", "is_ai_generated"}, schema.PropertyOrdering)
+	assert.Equal(t, schema.PropertyOrdering, schema.Required)
+	assert.Equal(t, "INTEGER", schema.Properties["relevance_score"].Type)
+	assert.Equal(t, "ARRAY", schema.Properties["matching_topics"].Type)
+	assert.Equal(t, "STRING", schema.Properties["matching_topics"].Items.Type)
+	assert.Equal(t, "BOOLEAN", schema.Properties["is_ai_generated"].Type)
+	assert.NotEmpty(t, schema.Properties["summary"].Description)
+
+	summary := llm.SchemaFor(&llm.ArticlesSummaryResult{})
+	assert.Equal(t, []string{"overall_summary", "must_read", "recommendations"}, summary.PropertyOrdering)
+
+	// スキーマはGemini APIの形式（キャメルケース）でエンコードされる
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"propertyOrdering"`)
+}
+
+// TestParseJSONResponse はスキーマに対応しないバックエンドの応答を寛容にパースできることをテストします
+func TestParseJSONResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{"JSONのみ", `{"overall_summary": "概要", "must_read": "必読", "recommendations": ["a"]}`, false},
+		{"コードブロック", "```json\n{\"overall_summary\": \"概要\", \"must_read\": \"必読\", \"recommendations\": [\"a\"]}\n```", false},
+		{"前後に説明文", "以下がサマリーです。\n```json\n{\"overall_summary\": \"概要\", \"must_read\": \"必読\", \"recommendations\": [\"a\"]}\n```\nご確認ください。", false},
+		{"説明文中の括弧", `注意: {不完全} な例です。{"overall_summary": "概要", "must_read": "必読", "recommendations": ["a"]} 以上`, false},
+		{"JSONなし", "サマリーを生成できませんでした", true},
+		{"型が異なる", `{"overall_summary": 1}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result llm.ArticlesSummaryResult
+			err := llm.ParseJSONResponse(tt.text, &result)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "概要", result.OverallSummary)
+			assert.Equal(t, []string{"a"}, result.Recommendations)
+		})
+	}
+}
+
+// testProviderArticle はバックエンドのテストで評価する記事を返します
+func testProviderArticle() *config.Article {
+	return &config.Article{
+		Title:       "Goで作るワーカープール",
+		URL:         "https://example.com/worker-pool",
+		ContentText: strings.Repeat("Goのゴルーチンとチャネルでワーカープールを実装します。", 20),
+	}
+}
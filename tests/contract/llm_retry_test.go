@@ -0,0 +1,176 @@
+package contract
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/errors"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// geminiSuccessBody はGemini APIのモックが返す成功応答
+const geminiSuccessBody = `{"candidates": [{"content": {"parts": [{"text": "ok"}], "role": "model"}, "finishReason": "STOP"}], "usageMetadata": {"promptTokenCount": 10, "candidatesTokenCount": 2, "totalTokenCount": 12}}`
+
+// geminiErrorResponse はGemini APIのモックが返すエラー応答
+type geminiErrorResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+// newRetryTestClient は応答を順に返すモックサーバーに接続したGeminiクライアントを作成します
+// 応答を使い切った後は成功応答を返します
+func newRetryTestClient(t *testing.T, responses []geminiErrorResponse) (*llm.Client, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n > len(responses) {
+			w.Write([]byte(geminiSuccessBody))
+			return
+		}
+		resp := responses[n-1]
+		for k, v := range resp.header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := llm.NewProvider(config.LLMSettings{
+		Provider:          config.LLMProviderGemini,
+		Endpoint:          server.URL,
+		RequestsPerMinute: 60000,
+	}, "test-key", 1024*1024)
+	require.NoError(t, err)
+
+	client := provider.(*llm.Client)
+	client.SetRetryPolicy(llm.RetryPolicy{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		MaxRetryAfter:  time.Second,
+	})
+	return client, &calls
+}
+
+// TestGeminiClient_Retry はGemini APIのエラー応答の分類と再試行をテストします
+func TestGeminiClient_Retry(t *testing.T) {
+	unavailable := geminiErrorResponse{status: http.StatusServiceUnavailable, body: `{"error": {"code": 503, "message": "The model is overloaded.", "status": "UNAVAILABLE"}}`}
+
+	tests := []struct {
+		name      string
+		responses []geminiErrorResponse
+		wantKind  *errors.AppError // nilの場合は成功
+		wantCalls int32
+		minWait   time.Duration
+	}{
+		{
+			name:      "一時的なエラーは再試行して成功する",
+			responses: []geminiErrorResponse{unavailable, {status: http.StatusBadGateway, body: "<html>Bad Gateway</html>"}},
+			wantCalls: 3,
+		},
+		{
+			name:      "クォータ超過はretryDelayだけ待って再試行する",
+			responses: []geminiErrorResponse{{status: http.StatusTooManyRequests, body: `{"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED", "details": [{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "0.05s"}]}}`}},
+			wantCalls: 2,
+			minWait:   50 * time.Millisecond,
+		},
+		{
+			name:      "再試行の上限を超えた一時的なエラー",
+			responses: []geminiErrorResponse{unavailable, unavailable, unavailable},
+			wantKind:  errors.ErrLLMTransient,
+			wantCalls: 3,
+		},
+		{
+			name:      "Retry-Afterが長すぎるクォータ超過は再試行しない",
+			responses: []geminiErrorResponse{{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "3600"}, body: `{"error": {"code": 429, "message": "Quota exceeded for quota metric per day", "status": "RESOURCE_EXHAUSTED"}}`}},
+			wantKind:  errors.ErrLLMQuotaExceeded,
+			wantCalls: 1,
+		},
+		{
+			name:      "不正なAPIキー",
+			responses: []geminiErrorResponse{{status: http.StatusBadRequest, body: `{"error": {"code": 400, "message": "API key not valid.", "status": "INVALID_ARGUMENT", "details": [{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "API_KEY_INVALID"}]}}`}},
+			wantKind:  errors.ErrLLMAuthFailed,
+			wantCalls: 1,
+		},
+		{
+			name:      "権限なし",
+			responses: []geminiErrorResponse{{status: http.StatusForbidden, body: `{"error": {"code": 403, "message": "Permission denied", "status": "PERMISSION_DENIED"}}`}},
+			wantKind:  errors.ErrLLMAuthFailed,
+			wantCalls: 1,
+		},
+		{
+			name:      "不正なリクエスト",
+			responses: []geminiErrorResponse{{status: http.StatusBadRequest, body: `{"error": {"code": 400, "message": "Invalid JSON payload", "status": "INVALID_ARGUMENT"}}`}},
+			wantKind:  errors.ErrLLMBadRequest,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newRetryTestClient(t, tt.responses)
+
+			start := time.Now()
+			generation, err := client.GenerateText(context.Background(), "prompt")
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(calls))
+			assert.GreaterOrEqual(t, time.Since(start), tt.minWait)
+
+			if tt.wantKind == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "ok", generation.Text)
+				return
+			}
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.wantKind)
+
+			var appErr *errors.AppError
+			require.True(t, stderrors.As(err, &appErr))
+			assert.Equal(t, errors.ErrorTypeLLM, appErr.Type)
+		})
+	}
+}
+
+// quotaProvider は常にクォータ超過を返すテスト用のProvider
+type quotaProvider struct {
+	calls int
+}
+
+func (p *quotaProvider) GenerateText(ctx context.Context, prompt string) (*llm.Generation, error) {
+	return p.GenerateJSON(ctx, prompt, nil)
+}
+
+func (p *quotaProvider) GenerateJSON(context.Context, string, *llm.Schema) (*llm.Generation, error) {
+	p.calls++
+	return nil, errors.NewLLMError("Gemini APIの呼び出しに失敗しました", &llm.APIError{Kind: errors.ErrLLMQuotaExceeded, StatusCode: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"})
+}
+
+func (p *quotaProvider) Model() string { return "quota-model" }
+
+// TestEvaluateArticles_QuotaStop はクォータ超過が続いた場合に、残りの記事の評価とサマリー生成でLLMを呼び出さないことをテストします
+func TestEvaluateArticles_QuotaStop(t *testing.T) {
+	provider := &quotaProvider{}
+	evaluator := llm.NewEvaluator(provider)
+
+	results := evaluator.EvaluateArticles(context.Background(), testBatchItems(5, testTopics("Go")), 70)
+	require.Len(t, results, 5)
+	for _, result := range results {
+		assert.True(t, llm.IsQuotaExceeded(result.Err), "すべての記事がクォータ超過で評価できない")
+	}
+	assert.Equal(t, llm.QuotaErrorsBeforeStop, provider.calls)
+	assert.True(t, evaluator.QuotaExhausted())
+
+	_, err := evaluator.GenerateArticlesSummary(context.Background(), []llm.ArticleForSummary{{Title: "記事"}})
+	assert.True(t, llm.IsQuotaExceeded(err))
+	assert.Equal(t, llm.QuotaErrorsBeforeStop, provider.calls)
+}
@@ -0,0 +1,91 @@
+package contract
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scoringTopics は別名と優先度を設定した興味トピック
+var scoringTopics = []config.InterestTopic{
+	{Topic: "Go", Aliases: []string{"Golang", "Go言語"}, Priority: "high"},
+	{Topic: "Kubernetes", Aliases: []string{"k8s"}, Priority: "medium"},
+	{Topic: "個人開発", Priority: "low"},
+}
+
+// TestNormalizeMatchingTopics は一致トピックの別名が正式名に置き換えられ、幻覚トピックが除外されることをテストします
+func TestNormalizeMatchingTopics(t *testing.T) {
+	tests := []struct {
+		name    string
+		matched []string
+		want    []string
+	}{
+		{"正式名", []string{"Go", "Kubernetes"}, []string{"Go", "Kubernetes"}},
+		{"別名", []string{"Golang", "k8s"}, []string{"Go", "Kubernetes"}},
+		{"大文字・小文字と空白の違い", []string{" golang ", "KUBERNETES"}, []string{"Go", "Kubernetes"}},
+		{"正式名と別名の重複", []string{"Go", "Go言語", "Golang"}, []string{"Go"}},
+		{"幻覚トピック", []string{"Rust", "個人開発", "Web開発"}, []string{"個人開発"}},
+		{"一致なし", []string{"Rust"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, llm.NormalizeMatchingTopics(tt.matched, scoringTopics))
+		})
+	}
+}
+
+// TestRankingScore は一致トピックの最も高い優先度の倍率でスコアが計算され、0〜100に制限されることをテストします
+func TestRankingScore(t *testing.T) {
+	tests := []struct {
+		name            string
+		llmScore        int
+		matched         []string
+		want            int
+		wantExplanation string
+	}{
+		{"優先度high", 40, []string{"Go"}, 80, "LLMスコア40 × 倍率2.0（Go: 優先度high）= 80"},
+		{"上限で制限", 70, []string{"Kubernetes", "Go"}, 100, "LLMスコア70 × 倍率2.0（Go: 優先度high）= 140 → 100（0〜100に制限）"},
+		{"優先度medium", 75, []string{"Kubernetes", "個人開発"}, 75, "LLMスコア75 × 倍率1.0（Kubernetes: 優先度medium）= 75"},
+		{"優先度low", 75, []string{"個人開発"}, 38, "LLMスコア75 × 倍率0.5（個人開発: 優先度low）= 38"},
+		{"一致なし", 90, nil, 0, "LLMスコア90、興味トピックに一致しないため0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, explanation := llm.RankingScore(tt.llmScore, tt.matched, scoringTopics)
+			assert.Equal(t, tt.want, score)
+			assert.Equal(t, tt.wantExplanation, explanation)
+		})
+	}
+}
+
+// TestEvaluateArticle_TopicAliases は別名がプロンプトに含まれ、評価結果の一致トピックが正規化されることをテストします
+func TestEvaluateArticle_TopicAliases(t *testing.T) {
+	provider := &fakeBatchProvider{}
+	evaluator := llm.NewEvaluator(provider)
+
+	// fakeBatchProviderは一致トピックに"Go"を返す
+	evaluation, err := evaluator.EvaluateArticle(context.Background(), testProviderArticle(), scoringTopics, 70)
+	require.NoError(t, err)
+
+	require.Len(t, provider.prompts, 1)
+	assert.Contains(t, provider.prompts[0], `["Go","Kubernetes","個人開発"]`)
+	assert.Contains(t, provider.prompts[0], "トピックの別名: Go = Golang, Go言語 / Kubernetes = k8s")
+
+	assert.Equal(t, []string{"Go"}, evaluation.MatchingTopics)
+	assert.Equal(t, 80, evaluation.RelevanceScore)
+	assert.Equal(t, 100, evaluation.RankingScore)
+	assert.True(t, evaluation.IsRelevant)
+
+	// 興味トピックに一致しない場合は、スコアが閾値以上でも関連性なしとする
+	evaluation, err = evaluator.EvaluateArticle(context.Background(), testProviderArticle(), testTopics("Rust"), 70)
+	require.NoError(t, err)
+	assert.Empty(t, evaluation.MatchingTopics)
+	assert.Equal(t, 0, evaluation.RankingScore)
+	assert.False(t, evaluation.IsRelevant)
+}
@@ -0,0 +1,151 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTriageProvider はトリアージのプロンプトに含まれる記事のうち、rejectに含まれるタイトルの記事を関連なしと判定するテスト用のプロバイダー
+type fakeTriageProvider struct {
+	reject  map[string]bool
+	err     error
+	prompts []string
+}
+
+var triageArticlePattern = regexp.MustCompile(`\[(T\d+)\] タイトル: (.+)`)
+
+func (p *fakeTriageProvider) GenerateText(ctx context.Context, prompt string) (*llm.Generation, error) {
+	return p.GenerateJSON(ctx, prompt, nil)
+}
+
+func (p *fakeTriageProvider) GenerateJSON(_ context.Context, prompt string, _ *llm.Schema) (*llm.Generation, error) {
+	p.prompts = append(p.prompts, prompt)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	var decisions []string
+	for _, m := range triageArticlePattern.FindAllStringSubmatch(prompt, -1) {
+		decisions = append(decisions, fmt.Sprintf(`{"article_id": %q, "plausible": %t}`, m[1], !p.reject[m[2]]))
+	}
+	usage := config.TokenUsage{PromptTokens: 500, CandidatesTokens: 50, TotalTokens: 550}
+	return &llm.Generation{Text: `{"articles": [` + strings.Join(decisions, ",") + `]}`, Usage: usage}, nil
+}
+
+func (p *fakeTriageProvider) Model() string { return "fake-model" }
+
+// TestTriageArticles_Keyword はタイトルとフィードの要約に興味トピック名・別名・キーワードを含む記事を残すことをテストします
+func TestTriageArticles_Keyword(t *testing.T) {
+	provider := &fakeTriageProvider{}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetTriageOptions(llm.TriageOptionsFromSettings(config.TriageSettings{Mode: config.TriageModeKeyword, Keywords: []string{"CNCF"}}))
+	require.True(t, evaluator.TriageEnabled())
+
+	topics := []config.InterestTopic{
+		{Topic: "Go", Aliases: []string{"Golang"}, Priority: "high"},
+		{Topic: "Kubernetes", Priority: "medium"},
+	}
+	items := []llm.TriageItem{
+		{Title: "Goで作るCLIツール"},
+		{Title: "Google announces new Pixel"},
+		{Title: "Advanced golang generics"},
+		{Title: "週末の料理レシピ", Description: "簡単な夕食の作り方"},
+		{Title: "クラスタ運用の振り返り", Description: "本番のkubernetesクラスタで起きた障害"},
+		{Title: "CNCF landscape 2025"},
+	}
+
+	keep := evaluator.TriageArticles(context.Background(), items, topics)
+	assert.Equal(t, []bool{true, false, true, false, true, true}, keep)
+	assert.Empty(t, provider.prompts, "キーワードによるトリアージではLLMを呼び出さない")
+}
+
+// TestTriageArticles_LLM はタイトルとフィードの要約をまとめてLLMで判定し、関連なしと判定された記事を除くことをテストします
+func TestTriageArticles_LLM(t *testing.T) {
+	provider := &fakeTriageProvider{reject: map[string]bool{"週末の料理レシピ": true}}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetTriageOptions(llm.TriageOptions{Mode: config.TriageModeLLM, BatchSize: 2})
+
+	items := []llm.TriageItem{
+		{Title: "Goの並行処理", Description: strings.Repeat("あ", llm.MaxTriageDescriptionLength+50)},
+		{Title: "週末の料理レシピ"},
+		{Title: "Kubernetesの運用"},
+	}
+	keep := evaluator.TriageArticles(context.Background(), items, testTopics("Go", "Kubernetes"))
+	assert.Equal(t, []bool{true, false, true}, keep)
+
+	// batch_sizeごとに1回呼び出す
+	require.Len(t, provider.prompts, 2)
+	assert.Contains(t, provider.prompts[0], `["Go","Kubernetes"]`)
+	assert.Contains(t, provider.prompts[0], "[T2] タイトル: 週末の料理レシピ")
+	assert.Contains(t, provider.prompts[0], "要約: "+strings.Repeat("あ", llm.MaxTriageDescriptionLength)+"...")
+	assert.Contains(t, provider.prompts[1], "[T1] タイトル: Kubernetesの運用")
+
+	// トリアージの呼び出しも使用量に含める
+	usage, requests := evaluator.Usage()
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1100, usage.TotalTokens)
+}
+
+// TestTriageArticles_Fallback はトリアージを行わない場合やLLMの呼び出しに失敗した場合に、すべての記事を残すことをテストします
+func TestTriageArticles_Fallback(t *testing.T) {
+	items := []llm.TriageItem{{Title: "Goの並行処理"}, {Title: "週末の料理レシピ"}}
+
+	t.Run("トリアージを行わない", func(t *testing.T) {
+		provider := &fakeTriageProvider{reject: map[string]bool{"週末の料理レシピ": true}}
+		evaluator := llm.NewEvaluator(provider)
+		assert.False(t, evaluator.TriageEnabled())
+		assert.Equal(t, []bool{true, true}, evaluator.TriageArticles(context.Background(), items, testTopics("Go")))
+		assert.Empty(t, provider.prompts)
+	})
+
+	t.Run("LLMの呼び出しに失敗", func(t *testing.T) {
+		provider := &fakeTriageProvider{err: fmt.Errorf("connection reset")}
+		evaluator := llm.NewEvaluator(provider)
+		evaluator.SetTriageOptions(llm.TriageOptionsFromSettings(config.TriageSettings{Mode: config.TriageModeLLM}))
+		assert.Equal(t, []bool{true, true}, evaluator.TriageArticles(context.Background(), items, testTopics("Go")))
+		assert.Len(t, provider.prompts, 1)
+	})
+
+	t.Run("1日あたりの使用量の上限に達している", func(t *testing.T) {
+		provider := &fakeTriageProvider{reject: map[string]bool{"週末の料理レシピ": true}}
+		evaluator := llm.NewEvaluator(provider)
+		evaluator.SetTriageOptions(llm.TriageOptionsFromSettings(config.TriageSettings{Mode: config.TriageModeLLM}))
+		evaluator.SetBudget(llm.Budget{DailyRequests: 1}, config.DailyUsage{Requests: 1})
+		assert.Equal(t, []bool{true, true}, evaluator.TriageArticles(context.Background(), items, testTopics("Go")))
+		assert.Empty(t, provider.prompts)
+	})
+}
+
+// TestTriageArticles_DefaultBatchSize はBatchSizeを指定しない場合に、デフォルトの件数ずつ判定することをテストします
+func TestTriageArticles_DefaultBatchSize(t *testing.T) {
+	provider := &fakeTriageProvider{reject: map[string]bool{"記事2": true}}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetTriageOptions(llm.TriageOptions{Mode: config.TriageModeLLM})
+
+	items := make([]llm.TriageItem, llm.DefaultTriageBatchSize+1)
+	for i := range items {
+		items[i] = llm.TriageItem{Title: fmt.Sprintf("記事%d", i+1)}
+	}
+	keep := evaluator.TriageArticles(context.Background(), items, testTopics("Go"))
+	require.Len(t, keep, len(items))
+	assert.False(t, keep[1])
+	assert.Len(t, provider.prompts, 2)
+}
+
+// TestTriageOptionsFromSettings はトリアージの設定からTriageOptionsを作成することをテストします
+func TestTriageOptionsFromSettings(t *testing.T) {
+	opts := llm.TriageOptionsFromSettings(config.TriageSettings{Mode: config.TriageModeLLM, BatchSize: 10, Keywords: []string{"CNCF"}})
+	assert.Equal(t, llm.TriageOptions{Mode: config.TriageModeLLM, BatchSize: 10, Keywords: []string{"CNCF"}}, opts)
+
+	// トリアージで除いた記事は興味トピックの変更時に再評価の対象になる
+	assert.True(t, config.IsEvaluationRejection(config.ReasonTriageRejected))
+	assert.True(t, config.IsValidRejectionReason(config.ReasonTriageRejected))
+}
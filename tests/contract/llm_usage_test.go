@@ -0,0 +1,133 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaka0913/discord-article-bot/internal/config"
+	"github.com/kaka0913/discord-article-bot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluator_Usage はLLMの呼び出しごとのトークン使用量がこの実行の使用量と当日の使用量に集計されることをテストします
+func TestEvaluator_Usage(t *testing.T) {
+	provider := &fakeBatchProvider{}
+	evaluator := llm.NewEvaluator(provider)
+	evaluator.SetBudget(llm.Budget{}, config.DailyUsage{Date: "2025-10-27", TotalTokens: 10000, Requests: 4})
+
+	for _, item := range testBatchItems(2, testTopics("Go")) {
+		_, err := evaluator.EvaluateArticle(context.Background(), item.Article, item.Topics, 70)
+		require.NoError(t, err)
+	}
+	// サマリー生成の呼び出しも使用量に含める
+	_, _ = evaluator.GenerateArticlesSummary(context.Background(), []llm.ArticleForSummary{{Title: "記事"}})
+
+	usage, requests := evaluator.Usage()
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, config.TokenUsage{PromptTokens: 9000, CandidatesTokens: 900, TotalTokens: 9900}, usage)
+
+	daily := evaluator.DailyUsage()
+	assert.Equal(t, "2025-10-27", daily.Date)
+	assert.Equal(t, 19900, daily.TotalTokens)
+	assert.Equal(t, 7, daily.Requests)
+	assert.False(t, evaluator.BudgetExhausted(), "上限を設定しない場合は無制限")
+}
+
+// TestEvaluator_UsageCountsRetries はGemini APIのクライアントが再試行したリクエストも呼び出し回数に数えることをテストします
+func TestEvaluator_UsageCountsRetries(t *testing.T) {
+	unavailable := geminiErrorResponse{status: http.StatusServiceUnavailable, body: `{"error": {"code": 503, "message": "The model is overloaded.", "status": "UNAVAILABLE"}}`}
+
+	t.Run("再試行して成功した場合", func(t *testing.T) {
+		client, calls := newRetryTestClient(t, []geminiErrorResponse{unavailable})
+		evaluator := llm.NewEvaluator(client)
+		evaluator.SetBudget(llm.Budget{DailyRequests: 3}, config.DailyUsage{Requests: 1})
+
+		// 応答のテキストはサマリーとしてパースできないが、使用量は記録される
+		_, _ = evaluator.GenerateArticlesSummary(context.Background(), []llm.ArticleForSummary{{Title: "記事"}})
+
+		usage, requests := evaluator.Usage()
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 12, usage.TotalTokens)
+		assert.Equal(t, 3, evaluator.DailyUsage().Requests)
+		assert.True(t, evaluator.BudgetExhausted(), "再試行したリクエストも1日あたりの上限に数える")
+	})
+
+	t.Run("再試行しても失敗した場合", func(t *testing.T) {
+		client, calls := newRetryTestClient(t, []geminiErrorResponse{unavailable, unavailable, unavailable})
+		evaluator := llm.NewEvaluator(client)
+
+		_, err := evaluator.GenerateArticlesSummary(context.Background(), []llm.ArticleForSummary{{Title: "記事"}})
+		require.Error(t, err)
+
+		_, requests := evaluator.Usage()
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+		assert.Equal(t, 3, requests)
+	})
+}
+
+// TestEvaluateArticles_Budget は1日あたりの使用量の上限に達した後はLLMを呼び出さずに評価を中止することをテストします
+func TestEvaluateArticles_Budget(t *testing.T) {
+	tests := []struct {
+		name          string
+		budget        llm.Budget
+		usedToday     config.DailyUsage
+		wantEvaluated int
+	}{
+		{
+			name:          "トークン数の上限",
+			budget:        llm.Budget{DailyTokens: 10000},
+			usedToday:     config.DailyUsage{TotalTokens: 4000},
+			wantEvaluated: 2, // 4000+3300=7300 < 10000、7300+3300=10600 >= 10000
+		},
+		{
+			name:          "呼び出し回数の上限",
+			budget:        llm.Budget{DailyRequests: 5},
+			usedToday:     config.DailyUsage{Requests: 4},
+			wantEvaluated: 1,
+		},
+		{
+			name:          "実行前に上限に達している",
+			budget:        llm.Budget{DailyRequests: 5},
+			usedToday:     config.DailyUsage{Requests: 5},
+			wantEvaluated: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeBatchProvider{}
+			evaluator := llm.NewEvaluator(provider)
+			evaluator.SetBudget(tt.budget, tt.usedToday)
+
+			results := evaluator.EvaluateArticles(context.Background(), testBatchItems(4, testTopics("Go")), 70)
+			require.Len(t, results, 4)
+
+			evaluated := 0
+			for _, result := range results {
+				if result.Err == nil {
+					evaluated++
+					continue
+				}
+				assert.True(t, llm.IsBudgetExceeded(result.Err), "上限に達した後の記事は使用量の上限のエラーになる")
+			}
+			assert.Equal(t, tt.wantEvaluated, evaluated)
+			assert.Len(t, provider.prompts, tt.wantEvaluated)
+			assert.True(t, evaluator.BudgetExhausted())
+
+			// 上限に達した後はサマリーも生成しない
+			_, err := evaluator.GenerateArticlesSummary(context.Background(), []llm.ArticleForSummary{{Title: "記事"}})
+			assert.True(t, llm.IsBudgetExceeded(err))
+			assert.Len(t, provider.prompts, tt.wantEvaluated)
+		})
+	}
+}
+
+// TestBudgetFromSettings はLLMの設定から1日あたりの使用量の上限を作成することをテストします
+func TestBudgetFromSettings(t *testing.T) {
+	budget := llm.BudgetFromSettings(config.LLMSettings{DailyTokenBudget: 1000000, DailyRequestBudget: 1500})
+	assert.Equal(t, llm.Budget{DailyTokens: 1000000, DailyRequests: 1500}, budget)
+}
@@ -62,6 +62,12 @@ func TestRSSParser_ParseRSS2Feed(t *testing.T) {
 		if firstArticle.FetchedAt.IsZero() {
 			t.Error("取得日時が設定されていない")
 		}
+
+		expectedSummary := "A comprehensive guide to building scalable microservices using Go."
+		if firstArticle.Summary != expectedSummary {
+			t.Errorf("要約が期待値と異なる: got %q, want %q",
+				firstArticle.Summary, expectedSummary)
+		}
 	}
 }
 